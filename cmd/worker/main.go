@@ -0,0 +1,133 @@
+// Command worker runs the background work (jobs, outbox relay, webhook
+// deliveries, alerts, trash purge and expired idempotency keys) outside the API.
+// Start the API with JOB_WORKERS=0 when using it.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+	"github.com/olmits/budget-tracker-backend/pkg/database"
+	"github.com/olmits/budget-tracker-backend/pkg/notify"
+	"github.com/olmits/budget-tracker-backend/pkg/storage"
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+)
+
+func main() {
+	dsn := database.DSNFromEnv()
+	database.RunMigrations(dsn)
+
+	dbPool, err := database.NewPostgresDB(dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	jobQueue := &service.JobQueue{Repo: &repository.PostgresJobRepo{DB: dbPool}, Workers: 4}
+	if v := os.Getenv("JOB_WORKERS"); v != "" {
+		jobQueue.Workers, err = strconv.Atoi(v)
+		if err != nil || jobQueue.Workers < 1 {
+			log.Fatalf("Invalid JOB_WORKERS: %q", v)
+		}
+	}
+
+	// Every job type is registered here so the worker can run anything the API enqueues
+	notificationService := &service.NotificationService{
+		Repo:        &repository.PostgresNotificationRepo{DB: dbPool},
+		Dispatchers: notify.DispatchersFromEnv(),
+		Jobs:        jobQueue,
+	}
+	notificationService.RegisterJobs(jobQueue)
+
+	webhookService := &service.WebhookService{
+		Repo:   &repository.PostgresWebhookRepo{DB: dbPool},
+		Client: webhook.NewClient(30 * time.Second),
+	}
+
+	transactionRepo := &repository.PostgresTransactionRepo{DB: dbPool}
+	alertService := &service.AlertService{
+		Notifications: notificationService,
+		TxRepo:        transactionRepo,
+		Users:         &repository.PostgresUserRepo{DB: dbPool},
+		Envelopes: &service.EnvelopeService{
+			Repo:         &repository.PostgresBudgetRepo{DB: dbPool},
+			CategoryRepo: &repository.PostgresCategoryRepo{DB: dbPool},
+		},
+		Anomalies:     &service.AnomalyService{TxRepo: transactionRepo},
+		Subscriptions: &service.SubscriptionService{TxRepo: transactionRepo},
+	}
+
+	// SSE clients are connected to the API, the events reach them through NOTIFY
+	pubSub := &repository.PostgresPubSub{DB: dbPool}
+	realtimeService := &service.RealtimeService{
+		PubSub:    pubSub,
+		Dashboard: &service.DashboardService{Repo: transactionRepo},
+	}
+	outboxRelay := &service.OutboxRelay{
+		Repo:   &repository.PostgresOutboxRepo{DB: dbPool},
+		Sink:   service.EventPublishers{webhookService, realtimeService, alertService, service.LogPublisher{}},
+		PubSub: pubSub,
+	}
+
+	// Purging the trash removes the receipts of purged transactions
+	blobStorage, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize blob storage: %v", err)
+	}
+	trashService := &service.TrashService{
+		Repo:    &repository.PostgresTrashRepo{DB: dbPool},
+		Storage: blobStorage,
+	}
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 {
+			log.Fatalf("Invalid TRASH_RETENTION_DAYS: %q", v)
+		}
+		trashService.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	idempotencyService := &service.IdempotencyService{Repo: &repository.PostgresIdempotencyRepo{DB: dbPool}}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Worker started with %d job workers", jobQueue.Workers)
+	var wg sync.WaitGroup
+	wg.Add(6)
+	go func() {
+		defer wg.Done()
+		jobQueue.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		webhookService.Run(ctx, 10*time.Second)
+	}()
+	go func() {
+		defer wg.Done()
+		outboxRelay.Run(ctx, 5*time.Second)
+	}()
+	go func() {
+		defer wg.Done()
+		trashService.Run(ctx, time.Hour)
+	}()
+	go func() {
+		defer wg.Done()
+		idempotencyService.Run(ctx, time.Hour)
+	}()
+	go func() {
+		defer wg.Done()
+		alertService.Run(ctx, time.Hour)
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down, waiting for running jobs...")
+	wg.Wait()
+}
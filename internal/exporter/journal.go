@@ -0,0 +1,220 @@
+// Package exporter writes the user's data in formats other tools understand.
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+// Plain-text accounting dialects
+const (
+	FormatLedger    = "ledger"
+	FormatHledger   = "hledger"
+	FormatBeancount = "beancount"
+
+	DefaultAssetAccount = "Assets:Checking"
+	DefaultCurrency     = "USD"
+)
+
+// JournalOptions configures WriteJournal
+type JournalOptions struct {
+	Format   string // FormatLedger, FormatHledger or FormatBeancount
+	Account  string // Asset account every transaction is balanced against
+	Currency string // Commodity written next to every amount
+}
+
+// JournalFormats lists the supported dialects
+func JournalFormats() []string {
+	return []string{FormatBeancount, FormatHledger, FormatLedger}
+}
+
+// FileExtension is the usual file extension of a dialect
+func FileExtension(format string) string {
+	switch format {
+	case FormatBeancount:
+		return "beancount"
+	case FormatHledger:
+		return "journal"
+	default:
+		return "ledger"
+	}
+}
+
+// AccountName maps a category to its account, e.g. "Dining out" (expense)
+// becomes "Expenses:Dining-out". Names are reduced to characters every
+// dialect accepts, so the same export works in all of them.
+func AccountName(c *models.Category) string {
+	root := "Expenses"
+	if c.Type == "income" {
+		root = "Income"
+	}
+	return root + ":" + accountComponent(c.Name)
+}
+
+func accountComponent(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if b.Len() == 0 {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	s := strings.TrimRight(b.String(), "-")
+	if s == "" {
+		return "Unnamed"
+	}
+	return s
+}
+
+// WriteJournal writes categories as accounts and transactions as balanced
+// two-posting entries against opts.Account, oldest first.
+func WriteJournal(w io.Writer, categories []*models.Category, transactions []*models.Transaction, opts JournalOptions) error {
+	if opts.Account == "" {
+		opts.Account = DefaultAssetAccount
+	}
+	if opts.Currency == "" {
+		opts.Currency = DefaultCurrency
+	}
+
+	sorted := append([]*models.Transaction{}, transactions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	// Distinct categories may normalize to one name, the later ones get a
+	// suffix: an account opened twice breaks beancount
+	names := []string{opts.Account}
+	used := map[string]bool{opts.Account: true}
+	unique := func(account string) string {
+		name := account
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", account, i)
+		}
+		used[name] = true
+		names = append(names, name)
+		return name
+	}
+
+	accounts := make(map[string]string, len(categories)) // Key: journalAccountKey
+	for _, c := range categories {
+		accounts[c.ID.String()] = unique(AccountName(c))
+	}
+	// Transactions may refer to categories not listed (trashed) or to none
+	for _, t := range sorted {
+		if key := journalAccountKey(t); accounts[key] == "" {
+			accounts[key] = unique(AccountName(&models.Category{Name: t.CategoryName, Type: t.Type}))
+		}
+	}
+	sort.Strings(names[1:])
+
+	// Accounts must be opened before their first posting
+	opened := time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if len(sorted) > 0 {
+		opened = sorted[0].Date
+	}
+
+	out := bufio.NewWriter(w)
+	switch opts.Format {
+	case FormatBeancount:
+		fmt.Fprintf(out, "option \"operating_currency\" \"%s\"\n\n", opts.Currency)
+		for _, name := range names {
+			fmt.Fprintf(out, "%s open %s\n", opened.Format("2006-01-02"), name)
+		}
+	case FormatLedger, FormatHledger:
+		fmt.Fprintf(out, "commodity %s\n\n", opts.Currency)
+		for _, name := range names {
+			fmt.Fprintf(out, "account %s\n", name)
+		}
+	default:
+		return fmt.Errorf("unsupported journal format %q, expected one of %v", opts.Format, JournalFormats())
+	}
+
+	for _, t := range sorted {
+		account := accounts[journalAccountKey(t)]
+
+		// Expenses are debited (positive), income credited (negative)
+		amount := formatCents(t.Amount)
+		balance := formatCents(-t.Amount)
+		if t.Type == "income" {
+			amount, balance = balance, amount
+		}
+
+		out.WriteString("\n")
+		writeJournalEntry(out, opts, t, account, amount, balance)
+	}
+
+	return out.Flush()
+}
+
+// journalAccountKey is the category ID of t, or its type and category name
+// when it has no category
+func journalAccountKey(t *models.Transaction) string {
+	if t.CategoryId != nil {
+		return t.CategoryId.String()
+	}
+	return t.Type + ":" + t.CategoryName
+}
+
+func writeJournalEntry(out *bufio.Writer, opts JournalOptions, t *models.Transaction, account, amount, balance string) {
+	date := t.Date.Format("2006-01-02")
+	description := strings.Join(strings.Fields(t.Description), " ")
+
+	if opts.Format == FormatBeancount {
+		fmt.Fprintf(out, "%s * %q", date, description)
+		for _, tag := range t.Tags {
+			fmt.Fprintf(out, " #%s", tagName(tag.Name))
+		}
+		fmt.Fprintf(out, "\n  id: %q\n", t.ID.String())
+	} else {
+		fmt.Fprintf(out, "%s * %s\n", date, description)
+		fmt.Fprintf(out, "    ; id: %s\n", t.ID)
+		if len(t.Tags) > 0 {
+			tags := make([]string, 0, len(t.Tags))
+			for _, tag := range t.Tags {
+				tags = append(tags, tagName(tag.Name))
+			}
+			if opts.Format == FormatHledger {
+				fmt.Fprintf(out, "    ; %s:\n", strings.Join(tags, ":, "))
+			} else {
+				fmt.Fprintf(out, "    ; :%s:\n", strings.Join(tags, ":"))
+			}
+		}
+	}
+
+	indent := "  "
+	if opts.Format != FormatBeancount {
+		indent = "    "
+	}
+	fmt.Fprintf(out, "%s%-40s  %12s %s\n", indent, account, amount, opts.Currency)
+	fmt.Fprintf(out, "%s%-40s  %12s %s\n", indent, opts.Account, balance, opts.Currency)
+}
+
+// tagName keeps the characters tags may contain in every dialect
+func tagName(name string) string {
+	return strings.ToLower(accountComponent(name))
+}
+
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
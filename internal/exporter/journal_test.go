@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/importer"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func journalFixtures() ([]*models.Category, []*models.Transaction) {
+	dining := &models.Category{ID: uuid.New(), Name: "Dining out", Type: "expense"}
+	salary := &models.Category{ID: uuid.New(), Name: "salary", Type: "income"}
+
+	transactions := []*models.Transaction{
+		{
+			ID: uuid.MustParse("22222222-2222-2222-2222-222222222222"), Amount: 250000, Description: "ACME payroll",
+			Date: time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC), CategoryId: &salary.ID, Type: "income",
+			Tags: []*models.Tag{},
+		},
+		{
+			ID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Amount: 4250, Description: "Pizza  place",
+			Date: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), CategoryId: &dining.ID, Type: "expense",
+			Tags: []*models.Tag{{Name: "Trip 2026"}},
+		},
+	}
+	return []*models.Category{dining, salary}, transactions
+}
+
+func TestAccountName(t *testing.T) {
+	assert.Equal(t, "Expenses:Dining-out", AccountName(&models.Category{Name: "Dining out", Type: "expense"}))
+	assert.Equal(t, "Income:Salary", AccountName(&models.Category{Name: "salary", Type: "income"}))
+	assert.Equal(t, "Expenses:Food-Drinks", AccountName(&models.Category{Name: " Food & Drinks! ", Type: "expense"}))
+	assert.Equal(t, "Expenses:Unnamed", AccountName(&models.Category{Name: "???", Type: "expense"}))
+}
+
+func TestWriteJournal(t *testing.T) {
+	categories, transactions := journalFixtures()
+
+	t.Run("Beancount", func(t *testing.T) {
+		var buf strings.Builder
+		err := WriteJournal(&buf, categories, transactions, JournalOptions{Format: FormatBeancount, Currency: "EUR"})
+		require.NoError(t, err)
+		out := buf.String()
+
+		assert.Contains(t, out, "2026-03-05 open Assets:Checking\n")
+		assert.Contains(t, out, "2026-03-05 open Expenses:Dining-out\n")
+		assert.Contains(t, out, `2026-03-05 * "Pizza place" #trip-2026`)
+		assert.Contains(t, out, `  id: "11111111-1111-1111-1111-111111111111"`)
+		assert.Regexp(t, `\n  Expenses:Dining-out +42\.50 EUR\n  Assets:Checking +-42\.50 EUR\n`, out)
+		assert.Regexp(t, `\n  Income:Salary +-2500\.00 EUR\n  Assets:Checking +2500\.00 EUR\n`, out)
+
+		// Oldest first
+		assert.Less(t, strings.Index(out, "Pizza"), strings.Index(out, "ACME"))
+	})
+
+	t.Run("Ledger", func(t *testing.T) {
+		var buf strings.Builder
+		err := WriteJournal(&buf, categories, transactions, JournalOptions{Format: FormatLedger, Account: "Assets:Bank"})
+		require.NoError(t, err)
+		out := buf.String()
+
+		assert.Contains(t, out, "account Expenses:Dining-out\n")
+		assert.Contains(t, out, "2026-03-05 * Pizza place\n    ; id: 11111111-1111-1111-1111-111111111111\n    ; :trip-2026:\n")
+		assert.Regexp(t, `\n    Assets:Bank +-42\.50 USD\n`, out)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		err := WriteJournal(&strings.Builder{}, categories, transactions, JournalOptions{Format: "qif"})
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteJournalUniqueAccounts(t *testing.T) {
+	dining := &models.Category{ID: uuid.New(), Name: "Dining out", Type: "expense"}
+	diningDash := &models.Category{ID: uuid.New(), Name: "Dining-out", Type: "expense"}
+	trashed := uuid.New()
+	day := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+	transactions := []*models.Transaction{
+		{ID: uuid.New(), Amount: 1000, Description: "Pizza", Date: day, CategoryId: &dining.ID, Type: "expense"},
+		{ID: uuid.New(), Amount: 2000, Description: "Sushi", Date: day, CategoryId: &diningDash.ID, Type: "expense"},
+		{ID: uuid.New(), Amount: 3000, Description: "Tapas", Date: day, CategoryId: &trashed, CategoryName: "Dining out", Type: "expense"},
+	}
+
+	var buf strings.Builder
+	require.NoError(t, WriteJournal(&buf, []*models.Category{dining, diningDash}, transactions, JournalOptions{Format: FormatBeancount}))
+	out := buf.String()
+
+	// Every account is opened once, each category keeps its own
+	for _, account := range []string{"Expenses:Dining-out", "Expenses:Dining-out-2", "Expenses:Dining-out-3"} {
+		assert.Equal(t, 1, strings.Count(out, " open "+account+"\n"), account)
+	}
+	assert.Regexp(t, `"Pizza"\n.*\n  Expenses:Dining-out +10\.00`, out)
+	assert.Regexp(t, `"Sushi"\n.*\n  Expenses:Dining-out-2 +20\.00`, out)
+	assert.Regexp(t, `"Tapas"\n.*\n  Expenses:Dining-out-3 +30\.00`, out)
+}
+
+// What we write must read back into the same records
+func TestWriteJournalRoundTrip(t *testing.T) {
+	categories, transactions := journalFixtures()
+
+	for _, format := range JournalFormats() {
+		t.Run(format, func(t *testing.T) {
+			var buf strings.Builder
+			require.NoError(t, WriteJournal(&buf, categories, transactions, JournalOptions{Format: format}))
+
+			batch, err := (&importer.JournalParser{}).Parse(strings.NewReader(buf.String()))
+			require.NoError(t, err)
+			require.Empty(t, batch.Errors)
+			require.Len(t, batch.Records, 2)
+
+			pizza, payroll := batch.Records[0], batch.Records[1]
+			assert.Equal(t, "journal:11111111-1111-1111-1111-111111111111", pizza.ExternalID)
+			assert.Equal(t, int64(-4250), pizza.Amount)
+			assert.Equal(t, "Dining out", pizza.Category)
+			assert.Equal(t, "expense", pizza.CategoryType)
+			assert.Equal(t, "Pizza place", pizza.Description)
+
+			assert.Equal(t, int64(250000), payroll.Amount)
+			assert.Equal(t, "Salary", payroll.Category)
+			assert.Equal(t, "income", payroll.CategoryType)
+		})
+	}
+}
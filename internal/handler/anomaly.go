@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type AnomalyHandler struct {
+	Service *service.AnomalyService
+}
+
+// GET /api/v1/anomalies?z=2.5&days=30
+// Unusual transactions of the last "days" days and unusual months, newest first
+func (h *AnomalyHandler) ListAnomalies(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	z := service.DefaultAnomalyZ
+	if v := c.Query("z"); v != "" {
+		z, err = strconv.ParseFloat(v, 64)
+		if err != nil || z <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'z' value, expected a positive number"})
+			return
+		}
+	}
+
+	days := 30
+	if v := c.Query("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 1 || days > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'days' value, expected 1 to 365"})
+			return
+		}
+	}
+
+	anomalies, err := h.Service.ListAnomalies(c.Request.Context(), userID, z, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detect anomalies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": anomalies})
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/storage"
+)
+
+// DefaultMaxAttachmentSize is used when AttachmentHandler.MaxSize is not set (10 MB)
+const DefaultMaxAttachmentSize int64 = 10 << 20
+
+// Content types we accept, detected from the file content (never trusted from the client)
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type AttachmentHandler struct {
+	Repo    repository.AttachmentRepository
+	TxRepo  repository.TransactionRepository
+	Storage storage.BlobStorage
+	MaxSize int64 // In bytes
+}
+
+func (h *AttachmentHandler) maxSize() int64 {
+	if h.MaxSize > 0 {
+		return h.MaxSize
+	}
+	return DefaultMaxAttachmentSize
+}
+
+// MaxBodySize is the largest upload request: the file plus some room for the multipart envelope
+func (h *AttachmentHandler) MaxBodySize() int64 {
+	return h.maxSize() + (1 << 20)
+}
+
+// POST /api/v1/transactions/:id/attachments (multipart form, field "file")
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return
+	}
+
+	// 1. Make sure the transaction exists before touching storage
+	if _, err := h.TxRepo.GetTransaction(c.Request.Context(), userID, transactionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transaction"})
+		return
+	}
+
+	// 2. Cap the body size
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxBodySize())
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds the %d bytes limit", h.maxSize())})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing 'file' form field"})
+		return
+	}
+	if fileHeader.Size > h.maxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds the %d bytes limit", h.maxSize())})
+		return
+	}
+	if fileHeader.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is empty"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	// 3. Sniff the MIME type from the first 512 bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+		return
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !allowedAttachmentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": fmt.Sprintf("Unsupported file type %q", contentType)})
+		return
+	}
+
+	// 4. Store the content, then the metadata
+	a := &models.Attachment{
+		UserId:        userID,
+		TransactionId: transactionID,
+		FileName:      sanitizeFileName(fileHeader.Filename),
+		ContentType:   contentType,
+		SizeBytes:     fileHeader.Size,
+		StorageKey:    fmt.Sprintf("%s/%s/%s", userID, transactionID, uuid.New()),
+	}
+
+	body := io.MultiReader(bytes.NewReader(head), file)
+	if err := h.Storage.Put(c.Request.Context(), a.StorageKey, body, a.SizeBytes, a.ContentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+		return
+	}
+
+	if err := h.Repo.CreateAttachment(c.Request.Context(), a); err != nil {
+		// Don't leave orphaned blobs behind
+		if delErr := h.Storage.Delete(c.Request.Context(), a.StorageKey); delErr != nil {
+			log.Printf("failed to clean up attachment blob %s: %v", a.StorageKey, delErr)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, a)
+}
+
+// GET /api/v1/transactions/:id/attachments
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return
+	}
+
+	attachments, err := h.Repo.ListAttachments(c.Request.Context(), userID, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": attachments})
+}
+
+// GET /api/v1/transactions/:id/attachments/:attachmentId
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	a, ok := h.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	reader, err := h.Storage.Get(c.Request.Context(), a.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment content not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read attachment"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, a.SizeBytes, a.ContentType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", a.FileName),
+	})
+}
+
+// DELETE /api/v1/transactions/:id/attachments/:attachmentId
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	a, ok := h.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.DeleteAttachment(c.Request.Context(), a.UserId, a.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+
+	// The row is gone, a leftover blob is harmless so only log failures
+	if err := h.Storage.Delete(c.Request.Context(), a.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("failed to delete attachment blob %s: %v", a.StorageKey, err)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// findAttachment loads the attachment from the URL and makes sure it belongs
+// to the transaction in the path. It writes the error response itself.
+func (h *AttachmentHandler) findAttachment(c *gin.Context) (*models.Attachment, bool) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return nil, false
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Attachment ID format"})
+		return nil, false
+	}
+
+	a, err := h.Repo.GetAttachment(c.Request.Context(), userID, attachmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attachment"})
+		return nil, false
+	}
+	if a.TransactionId != transactionID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return nil, false
+	}
+
+	return a, true
+}
+
+// sanitizeFileName drops any client-side directories and quotes
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r == '"' || r < 0x20 {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
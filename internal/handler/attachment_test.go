@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAttachmentRepo struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepo) CreateAttachment(ctx context.Context, a *models.Attachment) error {
+	args := m.Called(ctx, a)
+	a.ID = uuid.New()
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepo) ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]*models.Attachment, error) {
+	args := m.Called(ctx, userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepo) GetAttachment(ctx context.Context, userID, attachmentID uuid.UUID) (*models.Attachment, error) {
+	args := m.Called(ctx, userID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepo) DeleteAttachment(ctx context.Context, userID, attachmentID uuid.UUID) error {
+	args := m.Called(ctx, userID, attachmentID)
+	return args.Error(0)
+}
+
+// Smallest possible PNG signature, enough for http.DetectContentType
+var pngBytes = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func multipartBody(t *testing.T, fileName string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	part.Write(content)
+	require.NoError(t, w.Close())
+	return body, w.FormDataContentType()
+}
+
+func TestUploadAttachment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	transactionID := uuid.New()
+
+	setup := func(t *testing.T, maxSize int64) (*gin.Engine, *MockAttachmentRepo, *MockTransactionRepo, *storage.LocalStorage) {
+		blobs, err := storage.NewLocalStorage(t.TempDir())
+		require.NoError(t, err)
+
+		attRepo := new(MockAttachmentRepo)
+		txRepo := new(MockTransactionRepo)
+		txRepo.On("GetTransaction", mock.Anything, userID, transactionID).Return(&models.Transaction{ID: transactionID}, nil)
+
+		h := &AttachmentHandler{Repo: attRepo, TxRepo: txRepo, Storage: blobs, MaxSize: maxSize}
+		r := gin.Default()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", userID)
+			ctx.Next()
+		})
+		r.POST("/api/v1/transactions/:id/attachments", h.UploadAttachment)
+		return r, attRepo, txRepo, blobs
+	}
+
+	url := "/api/v1/transactions/" + transactionID.String() + "/attachments"
+
+	t.Run("Success Case", func(t *testing.T) {
+		r, attRepo, _, blobs := setup(t, 0)
+		attRepo.On("CreateAttachment", mock.Anything, mock.AnythingOfType("*models.Attachment")).Return(nil)
+
+		body, contentType := multipartBody(t, "../../receipt.png", pngBytes)
+		req, _ := http.NewRequest("POST", url, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"content_type":"image/png"`)
+		assert.Contains(t, w.Body.String(), `"file_name":"receipt.png"`)
+
+		saved := attRepo.Calls[0].Arguments.Get(1).(*models.Attachment)
+		stored, err := blobs.Get(context.Background(), saved.StorageKey)
+		require.NoError(t, err)
+		stored.Close()
+		attRepo.AssertExpectations(t)
+	})
+
+	t.Run("Rejects Unsupported Type", func(t *testing.T) {
+		r, attRepo, _, _ := setup(t, 0)
+
+		body, contentType := multipartBody(t, "receipt.png", []byte("just some text, not an image"))
+		req, _ := http.NewRequest("POST", url, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		attRepo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rejects Oversized File", func(t *testing.T) {
+		r, attRepo, _, _ := setup(t, 8)
+
+		body, contentType := multipartBody(t, "receipt.png", pngBytes)
+		req, _ := http.NewRequest("POST", url, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		attRepo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown Transaction", func(t *testing.T) {
+		r, _, txRepo, _ := setup(t, 0)
+		otherID := uuid.New()
+		txRepo.On("GetTransaction", mock.Anything, userID, otherID).Return(nil, repository.ErrNotFound)
+
+		body, contentType := multipartBody(t, "receipt.png", pngBytes)
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/"+otherID.String()+"/attachments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+type AuditHandler struct {
+	Repo repository.AuditRepository
+}
+
+// GET /api/v1/audit?entity_type=transaction&entity_id=...&action=update&actor_id=...&from=2026-01-01&to=2026-01-31&limit=50&before=...
+// Newest first. When a page is full, "next" holds the value of "before" for the next page.
+func (h *AuditHandler) ListAuditEntries(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter := models.AuditFilter{Limit: 50}
+	switch filter.Action = c.Query("action"); filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete, models.AuditActionRestore:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be 'create', 'update', 'delete' or 'restore'"})
+		return
+	}
+	switch filter.EntityType = c.Query("entity_type"); filter.EntityType {
+	case "", models.AuditEntityTransaction, models.AuditEntityCategory, models.AuditEntityBudget:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be 'transaction', 'category' or 'budget'"})
+		return
+	}
+	for _, p := range []struct {
+		param string
+		id    **uuid.UUID
+	}{
+		{"entity_id", &filter.EntityId},
+		{"actor_id", &filter.ActorId},
+		{"before", &filter.Before},
+	} {
+		if v := c.Query(p.param); v != "" {
+			parsed, err := uuid.Parse(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid '" + p.param + "' value, expected a UUID"})
+				return
+			}
+			*p.id = &parsed
+		}
+	}
+	// Both days are inclusive
+	if v := c.Query("from"); v != "" {
+		if filter.From, err = time.Parse(dateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from' date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(dateLayout, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to' date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	if v := c.Query("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit < 1 || filter.Limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'limit' value, expected 1 to 200"})
+			return
+		}
+	}
+
+	entries, err := h.Repo.ListAuditEntries(c.Request.Context(), userID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	var next *uuid.UUID
+	if len(entries) == filter.Limit {
+		next = &entries[len(entries)-1].ID
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries, "next": next})
+}
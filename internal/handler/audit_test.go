@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepo) ListAuditEntries(ctx context.Context, userID uuid.UUID, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).([]*models.AuditEntry), args.Error(1)
+}
+
+func TestListAuditEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	newRouter := func(repo *MockAuditRepo) *gin.Engine {
+		h := &AuditHandler{Repo: repo}
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", userID)
+			ctx.Next()
+		})
+		r.GET("/api/v1/audit", h.ListAuditEntries)
+		return r
+	}
+
+	t.Run("Filters And Next Page", func(t *testing.T) {
+		entityID, before := uuid.New(), uuid.New()
+		entries := []*models.AuditEntry{{ID: uuid.New()}, {ID: uuid.New()}}
+
+		repo := new(MockAuditRepo)
+		repo.On("ListAuditEntries", mock.Anything, userID, models.AuditFilter{
+			Action:     models.AuditActionUpdate,
+			EntityType: models.AuditEntityTransaction,
+			EntityId:   &entityID,
+			From:       time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+			Before:     &before,
+			Limit:      2,
+		}).Return(entries, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit?action=update&entity_type=transaction&entity_id="+entityID.String()+
+			"&from=2026-03-01&to=2026-03-31&before="+before.String()+"&limit=2", nil)
+		newRouter(repo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data []*models.AuditEntry `json:"data"`
+			Next *uuid.UUID           `json:"next"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Len(t, body.Data, 2)
+		require.NotNil(t, body.Next)
+		assert.Equal(t, entries[1].ID, *body.Next)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Last Page", func(t *testing.T) {
+		repo := new(MockAuditRepo)
+		repo.On("ListAuditEntries", mock.Anything, userID, models.AuditFilter{Limit: 50}).Return([]*models.AuditEntry{{ID: uuid.New()}}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit", nil)
+		newRouter(repo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"next":null`)
+	})
+
+	t.Run("Invalid Filters", func(t *testing.T) {
+		for _, query := range []string{"action=rename", "entity_type=goal", "actor_id=me", "before=1", "from=March", "limit=500"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit?"+query, nil)
+			newRouter(new(MockAuditRepo)).ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+	"github.com/olmits/budget-tracker-backend/pkg/banking"
+)
+
+type BankHandler struct {
+	Repo    repository.BankAccountRepository
+	Service *service.BankSyncService
+}
+
+type LinkBankAccountRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Token    string `json:"token" binding:"required"`
+}
+
+// POST /api/v1/bank/accounts
+// Exchanges the token of the provider's link flow for the user's accounts
+func (h *BankHandler) LinkAccounts(c *gin.Context) {
+	var req LinkBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	accounts, err := h.Service.LinkAccounts(c.Request.Context(), userID, req.Provider, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownProvider), errors.Is(err, banking.ErrInvalidToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to link bank accounts"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": accounts})
+}
+
+// GET /api/v1/bank/accounts
+func (h *BankHandler) ListAccounts(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	accounts, err := h.Repo.ListAccounts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bank accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// POST /api/v1/bank/accounts/:id/sync
+// Pulls the transactions added or corrected since the previous sync
+func (h *BankHandler) SyncAccount(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Bank Account ID format"})
+		return
+	}
+
+	account, report, err := h.Service.Sync(c.Request.Context(), userID, accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bank account not found"})
+		case errors.Is(err, banking.ErrUnknownAccount):
+			c.JSON(http.StatusConflict, gin.H{"error": "The provider no longer knows this account, link it again"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to sync bank account"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"account": account, "report": report})
+}
+
+// POST /api/v1/bank/accounts/refresh
+// Updates the balances of every linked account
+func (h *BankHandler) RefreshBalances(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	accounts, err := h.Service.RefreshBalances(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to refresh balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// DELETE /api/v1/bank/accounts/:id
+// Unlinks the account, transactions already synced are kept
+func (h *BankHandler) UnlinkAccount(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Bank Account ID format"})
+		return
+	}
+
+	if err := h.Repo.DeleteAccount(c.Request.Context(), userID, accountID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bank account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink bank account"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+const monthLayout = "2006-01"
+
+type BudgetHandler struct {
+	Service *service.EnvelopeService
+}
+
+type AssignBudgetRequest struct {
+	Assigned *int64 `json:"assigned" binding:"required"` // Cents, 0 clears the envelope
+}
+
+type MoveBudgetRequest struct {
+	FromCategoryID *string `json:"from_category_id"` // Empty means "ready to assign"
+	ToCategoryID   *string `json:"to_category_id"`   // Empty means "ready to assign"
+	Amount         int64   `json:"amount" binding:"required,gt=0"`
+}
+
+// GET /api/v1/budgets/:month
+// Envelopes of the month (YYYY-MM) with rollover and the "ready to assign" pool
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	month, err := time.Parse(monthLayout, c.Param("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, expected YYYY-MM"})
+		return
+	}
+
+	budget, err := h.Service.GetMonth(c.Request.Context(), userID, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// PUT /api/v1/budgets/:month/envelopes/:categoryId
+func (h *BudgetHandler) AssignBudget(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	month, err := time.Parse(monthLayout, c.Param("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, expected YYYY-MM"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		return
+	}
+
+	var req AssignBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget, err := h.Service.Assign(c.Request.Context(), userID, categoryID, month, *req.Assigned)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// POST /api/v1/budgets/:month/move
+// Moves money between two envelopes, or between an envelope and "ready to assign"
+func (h *BudgetHandler) MoveBudget(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	month, err := time.Parse(monthLayout, c.Param("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, expected YYYY-MM"})
+		return
+	}
+
+	var req MoveBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := parseOptionalID(req.FromCategoryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		return
+	}
+	to, err := parseOptionalID(req.ToCategoryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		return
+	}
+	if from == nil && to == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set from_category_id, to_category_id or both"})
+		return
+	}
+	if from != nil && to != nil && *from == *to {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from_category_id and to_category_id must differ"})
+		return
+	}
+
+	budget, err := h.Service.Move(c.Request.Context(), userID, month, from, to, req.Amount)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// parseOptionalID treats a missing or empty value as nil
+func parseOptionalID(v *string) (*uuid.UUID, error) {
+	if v == nil || *v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*v)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (h *BudgetHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, service.ErrNotAnEnvelope):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInsufficientFunds):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
+	}
+}
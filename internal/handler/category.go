@@ -3,16 +3,21 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/olmits/budget-tracker-backend/internal/middleware"
 	"github.com/olmits/budget-tracker-backend/internal/models"
 	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
 )
 
 type CategoryHandler struct {
-	Repo repository.CategoryRepository
+	Repo      repository.CategoryRepository
+	Suggester *service.CategorySuggester
 }
 
 // Define the input JSON structure
@@ -74,5 +79,135 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": categories})
+	jsonWithETag(c, gin.H{"data": categories})
+}
+
+// GET /api/v1/categories/:id
+// The ETag is the category's version, send it back in If-Match to change it
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		return
+	}
+
+	cat, err := h.Repo.GetCategory(c.Request.Context(), userID, categoryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category"})
+		return
+	}
+
+	if notModified(c, versionETag(cat.Version)) {
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+// DELETE /api/v1/categories/:id?reassign_to=<id>
+// Moves the category to the trash. A category still used by transactions
+// or goals needs reassign_to, the category they move to, rules setting it move
+// too. Without reassign_to those rules are disabled. Requires If-Match.
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		return
+	}
+	versions, ok := ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var reassignTo *uuid.UUID
+	if v := c.Query("reassign_to"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil || id == categoryID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'reassign_to' category"})
+			return
+		}
+		if _, err := h.Repo.GetCategory(ctx, userID, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'reassign_to' category"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category"})
+			return
+		}
+		reassignTo = &id
+	}
+
+	if err := h.Repo.DeleteCategory(ctx, userID, categoryID, versions, reassignTo); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Category was changed since it was fetched"})
+		case errors.Is(err, repository.ErrInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": "Category is used by transactions or goals, pass 'reassign_to' to move them"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/categories/suggest?description=...&limit=3
+func (h *CategoryHandler) SuggestCategory(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	description := strings.TrimSpace(c.Query("description"))
+	if description == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'description' query parameter is required"})
+		return
+	}
+
+	limit := 3
+	if v := c.Query("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'limit' value"})
+			return
+		}
+	}
+
+	suggestions, err := h.Suggester.Suggest(c.Request.Context(), userID, description, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest categories"})
+		return
+	}
+
+	// Categories only seen through Observe don't carry a name yet
+	for _, s := range suggestions {
+		if s.CategoryName != "" {
+			continue
+		}
+		if cat, err := h.Repo.GetCategory(c.Request.Context(), userID, s.CategoryID); err == nil {
+			s.CategoryName = cat.Name
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
 }
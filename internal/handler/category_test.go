@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,6 +26,14 @@ func (m *MockCategoryRepo) CreateCategory(ctx context.Context, c *models.Categor
 	return args.Error(0)
 }
 
+func (m *MockCategoryRepo) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error) {
+	args := m.Called(ctx, userID, categoryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
 func (m *MockCategoryRepo) ListCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -33,6 +42,11 @@ func (m *MockCategoryRepo) ListCategories(ctx context.Context, userID uuid.UUID)
 	return args.Get(0).([]*models.Category), args.Error(1)
 }
 
+func (m *MockCategoryRepo) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, versions []int, reassignTo *uuid.UUID) error {
+	args := m.Called(ctx, userID, categoryID, versions, reassignTo)
+	return args.Error(0)
+}
+
 func TestCreateCategory(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -121,3 +135,97 @@ func TestListCategories(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Rent")
 	})
 }
+
+func TestSuggestCategory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Missing Description", func(t *testing.T) {
+		mockRepo := new(MockCategoryRepo)
+
+		h := &CategoryHandler{Repo: mockRepo}
+		r := gin.Default()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", uuid.New())
+			ctx.Next()
+		})
+		r.GET("/api/v1/categories/suggest", h.SuggestCategory)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/categories/suggest", nil)
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "description")
+	})
+}
+
+func TestDeleteCategory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	categoryID, otherID := uuid.New(), uuid.New()
+
+	newRouter := func(repo *MockCategoryRepo) *gin.Engine {
+		h := &CategoryHandler{Repo: repo}
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", userID)
+			ctx.Next()
+		})
+		r.DELETE("/api/v1/categories/:id", h.DeleteCategory)
+		return r
+	}
+
+	t.Run("In Use", func(t *testing.T) {
+		mockRepo := new(MockCategoryRepo)
+		mockRepo.On("DeleteCategory", mock.Anything, userID, categoryID, []int{3}, (*uuid.UUID)(nil)).Return(repository.ErrInUse)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/categories/"+categoryID.String(), nil)
+		req.Header.Set("If-Match", `"3"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "reassign_to")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Changed Meanwhile", func(t *testing.T) {
+		mockRepo := new(MockCategoryRepo)
+		mockRepo.On("DeleteCategory", mock.Anything, userID, categoryID, []int{3}, (*uuid.UUID)(nil)).Return(repository.ErrVersionMismatch)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/categories/"+categoryID.String(), nil)
+		req.Header.Set("If-Match", `"3"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("Reassigned", func(t *testing.T) {
+		mockRepo := new(MockCategoryRepo)
+		mockRepo.On("GetCategory", mock.Anything, userID, otherID).Return(&models.Category{ID: otherID}, nil)
+		mockRepo.On("DeleteCategory", mock.Anything, userID, categoryID, []int{3}, &otherID).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/categories/"+categoryID.String()+"?reassign_to="+otherID.String(), nil)
+		req.Header.Set("If-Match", `"3"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Target", func(t *testing.T) {
+		mockRepo := new(MockCategoryRepo)
+		mockRepo.On("GetCategory", mock.Anything, userID, otherID).Return(nil, repository.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/categories/"+categoryID.String()+"?reassign_to="+otherID.String(), nil)
+		req.Header.Set("If-Match", `"3"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "DeleteCategory", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
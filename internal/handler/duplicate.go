@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+type DuplicateHandler struct {
+	Repo repository.DuplicateRepository
+}
+
+// GET /api/v1/duplicates?status=pending|dismissed
+func (h *DuplicateHandler) ListDuplicates(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status := c.DefaultQuery("status", models.DuplicateStatusPending)
+	if status != models.DuplicateStatusPending && status != models.DuplicateStatusDismissed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'pending' or 'dismissed'"})
+		return
+	}
+
+	candidates, err := h.Repo.ListCandidates(c.Request.Context(), userID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch duplicates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": candidates})
+}
+
+// POST /api/v1/duplicates/:id/merge
+// Keeps the original transaction and deletes the duplicate
+func (h *DuplicateHandler) MergeDuplicate(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	candidateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Duplicate ID format"})
+		return
+	}
+
+	keptID, err := h.Repo.MergeCandidate(c.Request.Context(), userID, candidateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pending duplicate not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge duplicate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "merged", "kept_transaction_id": keptID})
+}
+
+// POST /api/v1/duplicates/:id/dismiss
+// Marks the pair as "not a duplicate" so it isn't flagged again
+func (h *DuplicateHandler) DismissDuplicate(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	candidateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Duplicate ID format"})
+		return
+	}
+
+	if err := h.Repo.DismissCandidate(c.Request.Context(), userID, candidateID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pending duplicate not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss duplicate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": candidateID, "status": models.DuplicateStatusDismissed})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag is the ETag of a row with a version column
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersions reads the versions a write may apply to from the If-Match
+// header: nil for "*", which matches any version, otherwise those of the
+// listed ETags. Writes to versioned rows must send it: without it the request
+// gets a 428, without any of our ETags a 412. ok is false when it answered.
+func ifMatchVersions(c *gin.Context) (versions []int, ok bool) {
+	v := strings.TrimSpace(c.GetHeader("If-Match"))
+	if v == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header with the ETag of the resource is required"})
+		return nil, false
+	}
+	if v == "*" {
+		return nil, true
+	}
+	for _, etag := range strings.Split(v, ",") {
+		// Weak ETags never match and ours are never weak
+		etag = strings.TrimSpace(etag)
+		version, err := strconv.Atoi(strings.Trim(etag, `"`))
+		if err == nil && versionETag(version) == etag {
+			versions = append(versions, version)
+		}
+	}
+	if versions == nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match does not match the current ETag"})
+		return nil, false
+	}
+	return versions, true
+}
+
+// notModified answers 304 when the client's If-None-Match holds etag, in
+// which case the handler has nothing left to do. The ETag header is set either way.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// jsonWithETag answers 200 with obj, tagged with a hash of the body, or 304
+// when the client already has that body
+func jsonWithETag(c *gin.Context, obj any) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+	sum := sha256.Sum256(body)
+	if notModified(c, `"`+hex.EncodeToString(sum[:16])+`"`) {
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type EventHandler struct {
+	Service   *service.RealtimeService
+	KeepAlive time.Duration // Defaults to 25s, below the idle timeout of most proxies
+}
+
+// GET /api/v1/events
+// Server-Sent Events stream of the user's data changes, starting with the current dashboard summary
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Subscribe first so nothing published while loading the summary is missed
+	events, unsubscribe := h.Service.Broker.Subscribe(userID)
+	defer unsubscribe()
+
+	var summary *models.Event
+	if h.Service.Dashboard != nil {
+		summary, err = h.Service.Summary(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dashboard"})
+			return
+		}
+	}
+
+	keepAlive := h.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = 25 * time.Second
+	}
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	c.Status(http.StatusOK)
+	if summary != nil {
+		renderEvent(c, summary)
+	}
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case e, ok := <-events:
+			if !ok {
+				return false // Fell behind, the client reconnects
+			}
+			renderEvent(c, e)
+		case <-ticker.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
+
+func renderEvent(c *gin.Context, e *models.Event) {
+	c.Render(-1, sse.Event{Id: e.ID.String(), Event: e.Type, Data: e.Data})
+}
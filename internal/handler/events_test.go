@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	s := &service.RealtimeService{Broker: &service.Broker{}}
+	h := &EventHandler{Service: s, KeepAlive: time.Hour}
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.GET("/api/v1/events", h.StreamEvents)
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/events", nil)
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Another user's change must not show up before ours
+	require.NoError(t, s.Publish(ctx, uuid.New(), &models.Event{ID: uuid.New(), Type: models.EventCategoryCreated, CreatedAt: time.Now(), Data: &models.Category{Name: "Rent"}}))
+	require.NoError(t, s.Publish(ctx, userID, &models.Event{ID: uuid.New(), Type: models.EventCategoryCreated, CreatedAt: time.Now(), Data: &models.Category{Name: "Food"}}))
+
+	lines := bufio.NewScanner(resp.Body)
+	var got []string
+	for len(got) < 3 && lines.Scan() {
+		got = append(got, lines.Text())
+	}
+	require.Len(t, got, 3)
+	assert.Regexp(t, `^id:[0-9a-f-]{36}$`, got[0])
+	assert.Equal(t, "event:"+models.EventCategoryCreated, got[1])
+	assert.Contains(t, got[2], `"name":"Food"`)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/exporter"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// Account names like "Assets:Bank:Checking", commodities like "EUR"
+var (
+	assetAccountPattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9-]*(:[A-Z0-9][A-Za-z0-9-]*)+$`)
+	currencyPattern     = regexp.MustCompile(`^[A-Z][A-Z0-9'._-]{0,22}[A-Z0-9]$`)
+)
+
+type ExportHandler struct {
+	TxRepo       repository.TransactionRepository
+	CategoryRepo repository.CategoryRepository
+}
+
+// GET /api/v1/export/:format?from=YYYY-MM-DD&to=YYYY-MM-DD&account=Assets:Checking&currency=USD
+// Writes a ledger, hledger or beancount journal. A missing from or to leaves that end open.
+func (h *ExportHandler) ExportJournal(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	format := strings.ToLower(c.Param("format"))
+	if !slices.Contains(exporter.JournalFormats(), format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported export format %q, expected one of %v", format, exporter.JournalFormats())})
+		return
+	}
+
+	opts := exporter.JournalOptions{
+		Format:   format,
+		Account:  c.DefaultQuery("account", exporter.DefaultAssetAccount),
+		Currency: strings.ToUpper(c.DefaultQuery("currency", exporter.DefaultCurrency)),
+	}
+	if !assetAccountPattern.MatchString(opts.Account) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'account', expected e.g. Assets:Checking"})
+		return
+	}
+	if !currencyPattern.MatchString(opts.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'currency', expected e.g. USD"})
+		return
+	}
+
+	from, to, err := parseOpenDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := models.TransactionFilter{From: from, To: to}
+
+	ctx := c.Request.Context()
+	categories, err := h.CategoryRepo.ListCategories(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+	transactions, err := h.TxRepo.ListTransactions(ctx, userID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := exporter.WriteJournal(&buf, categories, transactions, opts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write journal"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="transactions.%s"`, exporter.FileExtension(format)))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportJournal_DateBounds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		query  string
+		filter models.TransactionFilter
+	}{
+		{"", models.TransactionFilter{}},
+		{"?to=2023-12-31", models.TransactionFilter{To: day(2024, time.January, 1)}},
+		{"?from=2020-01-01", models.TransactionFilter{From: day(2020, time.January, 1)}},
+		{"?from=2020-01-01&to=2020-12-31", models.TransactionFilter{From: day(2020, time.January, 1), To: day(2021, time.January, 1)}},
+	}
+	for _, tt := range tests {
+		txRepo := new(MockTransactionRepo)
+		txRepo.On("ListTransactions", mock.Anything, userID, tt.filter).Return([]*models.Transaction{}, nil)
+		categoryRepo := new(MockCategoryRepo)
+		categoryRepo.On("ListCategories", mock.Anything, userID).Return([]*models.Category{}, nil)
+
+		h := &ExportHandler{TxRepo: txRepo, CategoryRepo: categoryRepo}
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", userID)
+			ctx.Next()
+		})
+		r.GET("/api/v1/export/:format", h.ExportJournal)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/export/beancount"+tt.query, nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.query)
+		txRepo.AssertExpectations(t)
+	}
+
+	// Both bounds given, they must be in order
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/export/beancount?from=2021-01-01&to=2020-12-31", nil)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.GET("/api/v1/export/:format", (&ExportHandler{}).ExportJournal)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
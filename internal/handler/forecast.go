@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type ForecastHandler struct {
+	Service *service.ForecastService
+}
+
+// GET /api/v1/forecast?days=90&threshold=0
+// Daily balance projection with confidence bands and the days it crosses
+// the threshold (cents, default 0)
+func (h *ForecastHandler) GetForecast(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	days := 90
+	if v := c.Query("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 1 || days > service.MaxForecastDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid 'days' value, expected 1 to %d", service.MaxForecastDays)})
+			return
+		}
+	}
+
+	var threshold int64
+	if v := c.Query("threshold"); v != "" {
+		threshold, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'threshold' value, expected cents"})
+			return
+		}
+	}
+
+	forecast, err := h.Service.Forecast(c.Request.Context(), userID, days, threshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute forecast"})
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type GoalHandler struct {
+	Repo         repository.GoalRepository
+	CategoryRepo repository.CategoryRepository
+	AccountRepo  repository.BankAccountRepository
+	Service      *service.GoalService
+}
+
+type GoalRequest struct {
+	Name          string  `json:"name" binding:"required,max=100"`
+	TargetAmount  int64   `json:"target_amount" binding:"required,gt=0"` // Cents
+	StartDate     string  `json:"start_date"`                            // YYYY-MM-DD, defaults to today
+	TargetDate    *string `json:"target_date"`                           // YYYY-MM-DD
+	CategoryID    *string `json:"category_id"`
+	BankAccountID *string `json:"bank_account_id"`
+}
+
+// toGoal validates the request and maps it to a model.
+// It writes the error response itself and returns false on failure.
+func (h *GoalHandler) toGoal(c *gin.Context, userID uuid.UUID) (*models.Goal, bool) {
+	var req GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	goal := &models.Goal{
+		UserId:       userID,
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		StartDate:    time.Now().UTC().Truncate(24 * time.Hour),
+	}
+
+	if req.StartDate != "" {
+		d, err := time.Parse(dateLayout, req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, expected YYYY-MM-DD"})
+			return nil, false
+		}
+		goal.StartDate = d
+	}
+	if req.TargetDate != nil {
+		d, err := time.Parse(dateLayout, *req.TargetDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target_date, expected YYYY-MM-DD"})
+			return nil, false
+		}
+		goal.TargetDate = &d
+	}
+
+	ctx := c.Request.Context()
+	if req.CategoryID != nil {
+		categoryID, err := uuid.Parse(*req.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+			return nil, false
+		}
+		if _, err := h.CategoryRepo.GetCategory(ctx, userID, categoryID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category"})
+			return nil, false
+		}
+		goal.CategoryId = &categoryID
+	}
+	if req.BankAccountID != nil {
+		accountID, err := uuid.Parse(*req.BankAccountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Bank Account ID format"})
+			return nil, false
+		}
+		if _, err := h.AccountRepo.GetAccount(ctx, userID, accountID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Bank account not found"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bank account"})
+			return nil, false
+		}
+		goal.BankAccountId = &accountID
+	}
+
+	if err := service.ValidateGoal(goal); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return goal, true
+}
+
+// POST /api/v1/goals
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goal, ok := h.toGoal(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.CreateGoal(c.Request.Context(), goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
+		return
+	}
+	if err := h.Service.AttachProgress(c.Request.Context(), goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute goal progress"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// GET /api/v1/goals
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goals, err := h.Service.ListGoals(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": goals})
+}
+
+// GET /api/v1/goals/:id
+// Includes the progress: saved amount, required monthly contribution and whether the goal is on track
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Goal ID format"})
+		return
+	}
+
+	goal, err := h.Service.GetGoal(c.Request.Context(), userID, goalID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// PUT /api/v1/goals/:id
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Goal ID format"})
+		return
+	}
+
+	goal, ok := h.toGoal(c, userID)
+	if !ok {
+		return
+	}
+	goal.ID = goalID
+
+	if err := h.Repo.UpdateGoal(c.Request.Context(), goal); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return
+	}
+	if err := h.Service.AttachProgress(c.Request.Context(), goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute goal progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DELETE /api/v1/goals/:id
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Goal ID format"})
+		return
+	}
+
+	if err := h.Repo.DeleteGoal(c.Request.Context(), userID, goalID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/importer"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+// MaxImportSize caps statement uploads. Statements are text files, 10 MB is plenty
+const MaxImportSize int64 = 10 << 20
+
+type ImportHandler struct {
+	Service *service.ImportService
+}
+
+// POST /api/v1/import/:format
+// The statement is sent either as multipart form field "file" or as the raw request body.
+func (h *ImportHandler) ImportStatement(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	format := strings.ToLower(c.Param("format"))
+	parser, err := importer.ForFormat(format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportSize)
+
+	var file io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(importReadStatus(err), gin.H{"error": "Missing or oversized 'file' form field"})
+			return
+		}
+		f, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+			return
+		}
+		defer f.Close()
+		file = f
+	}
+
+	batch, err := parser.Parse(file)
+	if err != nil {
+		c.JSON(importReadStatus(err), gin.H{"error": fmt.Sprintf("Unable to parse %s file: %v", format, err)})
+		return
+	}
+	batch.Format = format
+
+	report, err := h.Service.Import(c.Request.Context(), userID, batch)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import statement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importReadStatus maps a read/parse error to 413 for oversized bodies, 400 otherwise
+func importReadStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
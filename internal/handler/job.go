@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+type JobHandler struct {
+	Repo repository.JobRepository
+}
+
+// GET /api/v1/jobs?status=queued|running|succeeded|dead&limit=50
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.JobStatusQueued, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'queued', 'running', 'succeeded' or 'dead'"})
+		return
+	}
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'limit' value, expected 1 to 200"})
+			return
+		}
+	}
+
+	jobs, err := h.Repo.ListJobs(c.Request.Context(), userID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": jobs})
+}
+
+// GET /api/v1/jobs/:id
+func (h *JobHandler) GetJob(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Job ID format"})
+		return
+	}
+
+	job, err := h.Repo.GetJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// POST /api/v1/jobs/:id/retry
+// Queues a dead job again with a fresh set of attempts
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Job ID format"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.Repo.GetJob(ctx, userID, jobID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+
+	job, err := h.Repo.RetryJob(ctx, userID, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only dead jobs can be retried"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
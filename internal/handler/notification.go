@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type NotificationHandler struct {
+	Repo    repository.NotificationRepository
+	Service *service.NotificationService
+}
+
+type NotificationPreferenceRequest struct {
+	Channel string   `json:"channel" binding:"required,oneof=email webhook log"`
+	Enabled *bool    `json:"enabled"` // Defaults to true
+	Target  string   `json:"target"`
+	Kinds   []string `json:"kinds"`
+}
+
+type SavePreferencesRequest struct {
+	Channels []NotificationPreferenceRequest `json:"channels" binding:"dive"`
+}
+
+// GET /api/v1/notifications?unread=true&limit=50
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'limit' value, expected 1 to 200"})
+			return
+		}
+	}
+
+	notifications, unread, err := h.Service.ListNotifications(c.Request.Context(), userID, unreadOnly, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notifications, "unread": unread})
+}
+
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Notification ID format"})
+		return
+	}
+
+	if err := h.Repo.MarkRead(c.Request.Context(), userID, notificationID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	updated, err := h.Repo.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// GET /api/v1/notifications/preferences
+func (h *NotificationHandler) ListPreferences(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	prefs, err := h.Repo.ListPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": prefs})
+}
+
+// PUT /api/v1/notifications/preferences
+// Replaces the delivery channels; the in-app inbox is always on
+func (h *NotificationHandler) SavePreferences(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req SavePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs := make([]*models.NotificationPreference, 0, len(req.Channels))
+	for _, ch := range req.Channels {
+		enabled := true
+		if ch.Enabled != nil {
+			enabled = *ch.Enabled
+		}
+		kinds := ch.Kinds
+		if kinds == nil {
+			kinds = []string{}
+		}
+		prefs = append(prefs, &models.NotificationPreference{
+			Channel: ch.Channel,
+			Enabled: enabled,
+			Target:  ch.Target,
+			Kinds:   kinds,
+		})
+	}
+	if err := h.Service.ValidatePreferences(c.Request.Context(), prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Repo.SavePreferences(c.Request.Context(), userID, prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": prefs})
+}
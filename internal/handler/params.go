@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDateRange reads "from" and "to" (YYYY-MM-DD, both inclusive) from the query string.
+// Missing values default to the current calendar year.
+// The returned "to" is exclusive (start of the day after), ready for SQL "date < $to".
+func parseDateRange(c *gin.Context) (time.Time, time.Time, error) {
+	from, to, err := parseOpenDateRange(c)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now := time.Now().UTC()
+	year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	if from.IsZero() {
+		from = year
+	}
+	if to.IsZero() {
+		to = year.AddDate(1, 0, 0)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("'from' must not be after 'to'")
+	}
+	return from, to, nil
+}
+
+// parseOpenDateRange is parseDateRange without defaults: a missing value
+// is returned as the zero time, an open bound.
+func parseOpenDateRange(c *gin.Context) (from, to time.Time, err error) {
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(dateLayout, v); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid 'from' date, expected YYYY-MM-DD")
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		d, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid 'to' date, expected YYYY-MM-DD")
+		}
+		to = d.AddDate(0, 0, 1)
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("'from' must not be after 'to'")
+	}
+	return from, to, nil
+}
+
+// parseUUIDs converts a list of strings into UUIDs, failing on the first invalid one.
+// Repeated IDs are dropped, so ?tag=X&tag=X filters on X once.
+func parseUUIDs(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	seen := make(map[uuid.UUID]bool, len(values))
+	for _, v := range values {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID format: %q", v)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
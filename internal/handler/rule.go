@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type RuleHandler struct {
+	Repo         repository.RuleRepository
+	CategoryRepo repository.CategoryRepository
+	Service      *service.RuleService
+	Suggester    *service.CategorySuggester // Optional, reset after rules rewrite history
+}
+
+type RuleRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Priority int    `json:"priority"`
+	Enabled  *bool  `json:"enabled"` // Defaults to true
+
+	DescriptionPattern string `json:"description_pattern" binding:"max=255"`
+	MatchType          string `json:"match_type"` // Defaults to "contains"
+	MinAmount          *int64 `json:"min_amount"`
+	MaxAmount          *int64 `json:"max_amount"`
+	Weekdays           []int  `json:"weekdays"`
+
+	SetCategoryID  *string  `json:"set_category_id"`
+	SetDescription *string  `json:"set_description" binding:"omitempty,max=255"`
+	AddTagIDs      []string `json:"add_tag_ids"`
+}
+
+// toRule validates the request and maps it to a model.
+// It writes the error response itself and returns false on failure.
+func (h *RuleHandler) toRule(c *gin.Context, userID uuid.UUID) (*models.Rule, bool) {
+	var req RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	rule := &models.Rule{
+		UserId:             userID,
+		Name:               req.Name,
+		Priority:           req.Priority,
+		Enabled:            req.Enabled == nil || *req.Enabled,
+		DescriptionPattern: req.DescriptionPattern,
+		MatchType:          req.MatchType,
+		MinAmount:          req.MinAmount,
+		MaxAmount:          req.MaxAmount,
+		Weekdays:           req.Weekdays,
+		SetDescription:     req.SetDescription,
+	}
+	if rule.MatchType == "" {
+		rule.MatchType = service.MatchContains
+	}
+
+	tagIDs, err := parseUUIDs(req.AddTagIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	rule.AddTagIds = tagIDs
+
+	if req.SetCategoryID != nil {
+		categoryID, err := uuid.Parse(*req.SetCategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+			return nil, false
+		}
+		// The category must belong to the user
+		if _, err := h.CategoryRepo.GetCategory(c.Request.Context(), userID, categoryID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category"})
+			return nil, false
+		}
+		rule.SetCategoryId = &categoryID
+	}
+
+	if err := service.ValidateRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return rule, true
+}
+
+// POST /api/v1/rules
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rule, ok := h.toRule(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.CreateRule(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GET /api/v1/rules
+func (h *RuleHandler) ListRules(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.Repo.ListRules(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// GET /api/v1/rules/:id
+func (h *RuleHandler) GetRule(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Rule ID format"})
+		return
+	}
+
+	rule, err := h.Repo.GetRule(c.Request.Context(), userID, ruleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// PUT /api/v1/rules/:id
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Rule ID format"})
+		return
+	}
+
+	rule, ok := h.toRule(c, userID)
+	if !ok {
+		return
+	}
+	rule.ID = ruleID
+
+	if err := h.Repo.UpdateRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DELETE /api/v1/rules/:id
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Rule ID format"})
+		return
+	}
+
+	if err := h.Repo.DeleteRule(c.Request.Context(), userID, ruleID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rule"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/rules/rerun?dry_run=true
+func (h *RuleHandler) RerunRules(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'dry_run' value"})
+			return
+		}
+	}
+
+	changes, err := h.Service.Rerun(c.Request.Context(), userID, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-run rules"})
+		return
+	}
+	if !dryRun && len(changes) > 0 && h.Suggester != nil {
+		h.Suggester.Forget(userID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"changed": len(changes),
+		"data":    changes,
+	})
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type SubscriptionHandler struct {
+	Service *service.SubscriptionService
+}
+
+// GET /api/v1/subscriptions
+// Recurring charges detected from history with their annual cost and price changes
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	subscriptions, err := h.Service.ListSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detect subscriptions"})
+		return
+	}
+
+	var annual int64
+	for _, s := range subscriptions {
+		annual += s.AnnualCost
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": subscriptions, "annual_cost": annual})
+}
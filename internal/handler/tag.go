@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+type TagHandler struct {
+	Repo repository.TagRepository
+}
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type SetTransactionTagsRequest struct {
+	TagIDs []string `json:"tag_ids"`
+}
+
+// POST /api/v1/tags
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tag := &models.Tag{
+		UserId: userID,
+		Name:   req.Name,
+	}
+
+	if err := h.Repo.CreateTag(c.Request.Context(), tag); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// GET /api/v1/tags
+func (h *TagHandler) ListTags(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tags, err := h.Repo.ListTags(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// GET /api/v1/tags/:id
+func (h *TagHandler) GetTag(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Tag ID format"})
+		return
+	}
+
+	tag, err := h.Repo.GetTag(c.Request.Context(), userID, tagID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// PUT /api/v1/tags/:id
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Tag ID format"})
+		return
+	}
+
+	tag := &models.Tag{
+		ID:     tagID,
+		UserId: userID,
+		Name:   req.Name,
+	}
+
+	if err := h.Repo.UpdateTag(c.Request.Context(), tag); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag with this name already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DELETE /api/v1/tags/:id
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Tag ID format"})
+		return
+	}
+
+	if err := h.Repo.DeleteTag(c.Request.Context(), userID, tagID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PUT /api/v1/transactions/:id/tags
+// Requires If-Match with the transaction's ETag, the response carries the new one
+func (h *TagHandler) SetTransactionTags(c *gin.Context) {
+	var req SetTransactionTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return
+	}
+
+	tagIDs, err := parseUUIDs(req.TagIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	versions, ok := ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	version, err := h.Repo.SetTransactionTags(c.Request.Context(), userID, transactionID, versions, tagIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Transaction was changed since it was fetched"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction tags"})
+		}
+		return
+	}
+
+	c.Header("ETag", versionETag(version))
+	c.JSON(http.StatusOK, gin.H{"id": transactionID, "status": "updated"})
+}
+
+// GET /api/v1/tags/report?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *TagHandler) GetTagReport(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	totals, err := h.Repo.GetTotalsByTag(c.Request.Context(), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tag report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": from.Format(dateLayout),
+		"to":   to.AddDate(0, 0, -1).Format(dateLayout),
+		"data": totals,
+	})
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagRepo struct {
+	mock.Mock
+}
+
+func (m *MockTagRepo) CreateTag(ctx context.Context, tag *models.Tag) error {
+	args := m.Called(ctx, tag)
+	tag.ID = uuid.New()
+	return args.Error(0)
+}
+
+func (m *MockTagRepo) ListTags(ctx context.Context, userID uuid.UUID) ([]*models.Tag, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepo) GetTag(ctx context.Context, userID, tagID uuid.UUID) (*models.Tag, error) {
+	args := m.Called(ctx, userID, tagID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepo) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	args := m.Called(ctx, tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepo) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	args := m.Called(ctx, userID, tagID)
+	return args.Error(0)
+}
+
+func (m *MockTagRepo) SetTransactionTags(ctx context.Context, userID, transactionID uuid.UUID, versions []int, tagIDs []uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID, transactionID, versions, tagIDs)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTagRepo) GetTotalsByTag(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.TagTotal, error) {
+	args := m.Called(ctx, userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TagTotal), args.Error(1)
+}
+
+func newTagRouter(h *TagHandler, userID uuid.UUID) *gin.Engine {
+	r := gin.Default()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.POST("/api/v1/tags", h.CreateTag)
+	r.DELETE("/api/v1/tags/:id", h.DeleteTag)
+	r.GET("/api/v1/tags/report", h.GetTagReport)
+	r.PUT("/api/v1/transactions/:id/tags", h.SetTransactionTags)
+	return r
+}
+
+func TestCreateTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success Case", func(t *testing.T) {
+		mockRepo := new(MockTagRepo)
+		mockRepo.On("CreateTag", mock.Anything, mock.AnythingOfType("*models.Tag")).Return(nil)
+
+		r := newTagRouter(&TagHandler{Repo: mockRepo}, uuid.New())
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tags", bytes.NewBufferString(`{"name": "vacation-2026"}`))
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "vacation-2026")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Duplicate Error (Conflict)", func(t *testing.T) {
+		mockRepo := new(MockTagRepo)
+		mockRepo.On("CreateTag", mock.Anything, mock.Anything).Return(&pgconn.PgError{Code: "23505"})
+
+		r := newTagRouter(&TagHandler{Repo: mockRepo}, uuid.New())
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tags", bytes.NewBufferString(`{"name": "reimbursable"}`))
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "already exists")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestDeleteTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo := new(MockTagRepo)
+		userID := uuid.New()
+		tagID := uuid.New()
+		mockRepo.On("DeleteTag", mock.Anything, userID, tagID).Return(repository.ErrNotFound)
+
+		r := newTagRouter(&TagHandler{Repo: mockRepo}, userID)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/tags/"+tagID.String(), nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestSetTransactionTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID, transactionID, tagID := uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo := new(MockTagRepo)
+	mockRepo.On("SetTransactionTags", mock.Anything, userID, transactionID, []int{3}, []uuid.UUID{tagID}).Return(4, nil)
+
+	r := newTagRouter(&TagHandler{Repo: mockRepo}, userID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v1/transactions/"+transactionID.String()+"/tags",
+		bytes.NewBufferString(`{"tag_ids": ["`+tagID.String()+`"]}`))
+	req.Header.Set("If-Match", `"3"`)
+	r.ServeHTTP(w, req)
+
+	// The new ETag lets the client write again without fetching first
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTagReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Uses Inclusive Date Range", func(t *testing.T) {
+		mockRepo := new(MockTagRepo)
+		userID := uuid.New()
+		from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC) // Day after "to"
+
+		totals := []*models.TagTotal{{TagName: "vacation-2026", Expense: 12000, Count: 3}}
+		mockRepo.On("GetTotalsByTag", mock.Anything, userID, from, to).Return(totals, nil)
+
+		r := newTagRouter(&TagHandler{Repo: mockRepo}, userID)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/tags/report?from=2026-01-01&to=2026-01-31", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"expense":12000`)
+		assert.Contains(t, w.Body.String(), `"to":"2026-01-31"`)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Date", func(t *testing.T) {
+		mockRepo := new(MockTagRepo)
+		r := newTagRouter(&TagHandler{Repo: mockRepo}, uuid.New())
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/tags/report?from=01/01/2026", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -16,12 +18,17 @@ type CreateTransactionRequest struct {
 	Amount      int64     `json:"amount" binding:"required"` // In cents!
 	Description string    `json:"description"`
 	Date        time.Time `json:"date" binding:"required"`
-	CategoryID  string    `json:"category_id" binding:"required"`
+	CategoryID  string    `json:"category_id"` // Optional when a rule sets the category
+	TagIDs      []string  `json:"tag_ids"`
 }
 
 type TransactionHandler struct {
-	Repo    repository.TransactionRepository
-	Service *service.DashboardService
+	Repo         repository.TransactionRepository
+	CategoryRepo repository.CategoryRepository
+	Service      *service.DashboardService
+	Rules        *service.RuleService       // Optional, runs categorization rules on create
+	Suggester    *service.CategorySuggester // Optional, learns from created transactions
+	Duplicates   *service.DuplicateDetector // Optional, flags likely duplicates on create
 }
 
 // POST /api/v1/transactions
@@ -40,19 +47,60 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	categoryID, err := uuid.Parse(req.CategoryID)
+	var categoryID *uuid.UUID
+	if req.CategoryID != "" {
+		id, err := uuid.Parse(req.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+			return
+		}
+		// Owned and not in the trash
+		if _, err := h.CategoryRepo.GetCategory(c.Request.Context(), userID, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category"})
+			return
+		}
+		categoryID = &id
+	}
+
+	tagIDs, err := parseUUIDs(req.TagIDs)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	tags := make([]*models.Tag, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		tags = append(tags, &models.Tag{ID: id})
+	}
 
 	// Map Request to Model
 	t := &models.Transaction{
 		UserId:      userID,
 		Amount:      req.Amount,
-		CategoryId:  &categoryID,
+		CategoryId:  categoryID,
 		Description: req.Description,
 		Date:        req.Date,
+		Tags:        tags,
+	}
+
+	// Run categorization rules
+	if h.Rules != nil {
+		if _, err := h.Rules.Apply(c.Request.Context(), t); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply rules"})
+			return
+		}
+		// An explicit category from the client wins over rules
+		if categoryID != nil {
+			t.CategoryId = categoryID
+		}
+	}
+
+	if t.CategoryId == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required (no rule matched)"})
+		return
 	}
 
 	// CALL THE INTERFACE
@@ -61,14 +109,34 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	if h.Suggester != nil {
+		h.Suggester.Observe(t)
+	}
+
+	response := gin.H{
 		"id":         t.ID,
 		"status":     "created",
 		"created_at": t.CreatedAt,
-	})
+	}
+
+	// The row is already stored, so a detection failure must not fail the request
+	if h.Duplicates != nil {
+		candidates, err := h.Duplicates.Flag(c.Request.Context(), t)
+		if err != nil {
+			log.Printf("duplicate detection failed for transaction %s: %v", t.ID, err)
+		} else if len(candidates) > 0 {
+			ids := make([]uuid.UUID, 0, len(candidates))
+			for _, d := range candidates {
+				ids = append(ids, d.DuplicateOfId)
+			}
+			response["possible_duplicates"] = ids
+		}
+	}
+
+	c.JSON(http.StatusCreated, response)
 }
 
-// GET /api/v1/transactions
+// GET /api/v1/transactions?tag=<id>&tag=<id>
 func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
@@ -76,14 +144,87 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.Repo.ListTransactions(c.Request.Context(), userID)
+	tagIDs, err := parseUUIDs(c.QueryArray("tag"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := models.TransactionFilter{TagIDs: tagIDs}
+
+	transactions, err := h.Repo.ListTransactions(c.Request.Context(), userID, filter)
 	if err != nil {
 		// Log the error internally here if you have a logger
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": transactions})
+	jsonWithETag(c, gin.H{"data": transactions})
+}
+
+// GET /api/v1/transactions/:id
+// The ETag is the transaction's version, send it back in If-Match to change it
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return
+	}
+
+	t, err := h.Repo.GetTransaction(c.Request.Context(), userID, transactionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transaction"})
+		return
+	}
+
+	if notModified(c, versionETag(t.Version)) {
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// DELETE /api/v1/transactions/:id
+// Moves the transaction to the trash, see TrashHandler. Requires If-Match.
+func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return
+	}
+
+	versions, ok := ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.DeleteTransaction(c.Request.Context(), userID, transactionID, versions); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Transaction was changed since it was fetched"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transaction"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // GET /api/v1/dashboard
@@ -100,7 +241,7 @@ func (h *TransactionHandler) GetDashboard(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, summary)
+	jsonWithETag(c, summary)
 }
 
 func (h *TransactionHandler) GetPeriodicStats(c *gin.Context) {
@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -26,14 +28,40 @@ func (m *MockTransactionRepo) CreateTransaction(ctx context.Context, t *models.T
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepo) ListTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error) {
-	args := m.Called(ctx, userID)
+func (m *MockTransactionRepo) GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepo) GetTransactionByExternalID(ctx context.Context, userID uuid.UUID, externalID string) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepo) ListTransactions(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error) {
+	args := m.Called(ctx, userID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepo) UpdateTransaction(ctx context.Context, t *models.Transaction) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepo) DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID, versions []int) error {
+	args := m.Called(ctx, userID, transactionID, versions)
+	return args.Error(0)
+}
+
 func (m *MockTransactionRepo) GetSummaryByType(ctx context.Context, userID uuid.UUID) (map[string]int64, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -46,6 +74,10 @@ func (m *MockTransactionRepo) GetPeriodicStats(ctx context.Context, userID uuid.
 	return nil, nil // Not used in this test
 }
 
+func (m *MockTransactionRepo) GetCategoryStats(ctx context.Context, userID uuid.UUID, from time.Time) ([]*models.CategoryStat, error) {
+	return nil, nil // Not used in this test
+}
+
 func TestCreateTransaction(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -61,8 +93,12 @@ func TestCreateTransaction(t *testing.T) {
 		mockRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(nil)
 
 		// Create Handler using the Mock Repo
+		categoryRepo := new(MockCategoryRepo)
+		categoryRepo.On("GetCategory", mock.Anything, dummyUserID, uuid.MustParse(validCategoryID)).Return(&models.Category{}, nil)
+
 		h := &TransactionHandler{
-			Repo: mockRepo,
+			Repo:         mockRepo,
+			CategoryRepo: categoryRepo,
 			// Service is nil because CreateTransaction doesn't use it
 		}
 
@@ -101,8 +137,10 @@ func TestCreateTransaction(t *testing.T) {
 
 		// Expect it to fail this time
 		mockRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(errors.New("db error"))
+		categoryRepo := new(MockCategoryRepo)
+		categoryRepo.On("GetCategory", mock.Anything, dummyUserID, uuid.MustParse(validCategoryID)).Return(&models.Category{}, nil)
 
-		h := &TransactionHandler{Repo: mockRepo}
+		h := &TransactionHandler{Repo: mockRepo, CategoryRepo: categoryRepo}
 
 		r := gin.Default()
 		r.Use(func(ctx *gin.Context) {
@@ -129,4 +167,231 @@ func TestCreateTransaction(t *testing.T) {
 
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Unknown Category", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepo)
+		categoryRepo := new(MockCategoryRepo)
+		categoryRepo.On("GetCategory", mock.Anything, dummyUserID, uuid.MustParse(validCategoryID)).Return(nil, repository.ErrNotFound)
+		h := &TransactionHandler{Repo: mockRepo, CategoryRepo: categoryRepo}
+
+		r := gin.Default()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", dummyUserID)
+			ctx.Next()
+		})
+		r.POST("/api/v1/transactions", h.CreateTransaction)
+
+		w := httptest.NewRecorder()
+		jsonBody := []byte(`{"amount": 1000, "date": "2023-10-27T10:00:00Z", "category_id": "` + validCategoryID + `"}`)
+		req, _ := http.NewRequest("POST", "/api/v1/transactions", bytes.NewBuffer(jsonBody))
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Category not found")
+		mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing Category Without Rules", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepo)
+		h := &TransactionHandler{Repo: mockRepo}
+
+		r := gin.Default()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", dummyUserID)
+			ctx.Next()
+		})
+		r.POST("/api/v1/transactions", h.CreateTransaction)
+
+		w := httptest.NewRecorder()
+		jsonBody := []byte(`{"amount": 1000, "date": "2023-10-27T10:00:00Z", "description": "Test"}`)
+		req, _ := http.NewRequest("POST", "/api/v1/transactions", bytes.NewBuffer(jsonBody))
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "category_id is required")
+		mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	newRouter := func(repo *MockTransactionRepo) *gin.Engine {
+		h := &TransactionHandler{Repo: repo}
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("userID", userID)
+			ctx.Next()
+		})
+		r.DELETE("/api/v1/transactions/:id", h.DeleteTransaction)
+		return r
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		transactionID := uuid.New()
+		mockRepo := new(MockTransactionRepo)
+		mockRepo.On("DeleteTransaction", mock.Anything, userID, transactionID, []int{2}).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/transactions/"+transactionID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		transactionID := uuid.New()
+		mockRepo := new(MockTransactionRepo)
+		mockRepo.On("DeleteTransaction", mock.Anything, userID, transactionID, []int{2}).Return(repository.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/transactions/"+transactionID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Changed Meanwhile", func(t *testing.T) {
+		transactionID := uuid.New()
+		mockRepo := new(MockTransactionRepo)
+		mockRepo.On("DeleteTransaction", mock.Anything, userID, transactionID, []int{2}).Return(repository.ErrVersionMismatch)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/transactions/"+transactionID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("Without Or With A Foreign If-Match", func(t *testing.T) {
+		transactionID := uuid.New()
+		mockRepo := new(MockTransactionRepo)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/transactions/"+transactionID.String(), nil)
+		newRouter(mockRepo).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+		for _, etag := range []string{`W/"2"`, `2`, `"abc"`, `W/"1", "x"`} {
+			w = httptest.NewRecorder()
+			req.Header.Set("If-Match", etag)
+			newRouter(mockRepo).ServeHTTP(w, req)
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code, etag)
+		}
+		mockRepo.AssertNotCalled(t, "DeleteTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Any Or Listed Versions", func(t *testing.T) {
+		transactionID := uuid.New()
+		mockRepo := new(MockTransactionRepo)
+		mockRepo.On("DeleteTransaction", mock.Anything, userID, transactionID, []int(nil)).Return(nil).Once()
+		mockRepo.On("DeleteTransaction", mock.Anything, userID, transactionID, []int{1, 2}).Return(nil).Once()
+
+		for _, etag := range []string{`*`, `"1", W/"3", "2"`} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/api/v1/transactions/"+transactionID.String(), nil)
+			req.Header.Set("If-Match", etag)
+			newRouter(mockRepo).ServeHTTP(w, req)
+			assert.Equal(t, http.StatusNoContent, w.Code, etag)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetTransaction_ETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID, transactionID := uuid.New(), uuid.New()
+
+	mockRepo := new(MockTransactionRepo)
+	mockRepo.On("GetTransaction", mock.Anything, userID, transactionID).Return(&models.Transaction{ID: transactionID, Version: 4}, nil)
+
+	h := &TransactionHandler{Repo: mockRepo}
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.GET("/api/v1/transactions/:id", h.GetTransaction)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/transactions/"+transactionID.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"version":4`)
+
+	w = httptest.NewRecorder()
+	req.Header.Set("If-None-Match", `"3", "4"`)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestListTransactions_RepeatedTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	tagID := uuid.New()
+
+	mockRepo := new(MockTransactionRepo)
+	filter := models.TransactionFilter{TagIDs: []uuid.UUID{tagID}}
+	mockRepo.On("ListTransactions", mock.Anything, userID, filter).Return([]*models.Transaction{}, nil)
+
+	h := &TransactionHandler{Repo: mockRepo}
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.GET("/api/v1/transactions", h.ListTransactions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/transactions?tag="+tagID.String()+"&tag="+tagID.String(), nil)
+	r.ServeHTTP(w, req)
+
+	// The repository counts matching tags against len(TagIDs), a repeat would never match
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListTransactions_IfNoneMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	mockRepo := new(MockTransactionRepo)
+	transactions := []*models.Transaction{{ID: uuid.New(), Amount: 1250, Version: 1}}
+	mockRepo.On("ListTransactions", mock.Anything, userID, mock.Anything).Return(transactions, nil)
+
+	h := &TransactionHandler{Repo: mockRepo}
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.GET("/api/v1/transactions", h.ListTransactions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/transactions", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w = httptest.NewRecorder()
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Any change to the list changes the ETag
+	transactions[0].Version = 2
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
 }
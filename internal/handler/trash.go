@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+)
+
+type TrashHandler struct {
+	Repo    repository.TrashRepository
+	Service *service.TrashService
+}
+
+// GET /api/v1/trash
+// Deleted transactions and categories, most recently deleted first. Each one
+// can be restored until purge_at.
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	transactions, err := h.Repo.ListDeletedTransactions(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+	categories, err := h.Repo.ListDeletedCategories(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	type item struct {
+		Type    string    `json:"type"` // "transaction" or "category"
+		Item    any       `json:"item"`
+		PurgeAt time.Time `json:"purge_at"`
+	}
+	retention := h.Service.RetentionPeriod()
+	items := make([]item, 0, len(transactions)+len(categories))
+	for _, t := range transactions {
+		items = append(items, item{Type: "transaction", Item: t, PurgeAt: t.DeletedAt.Add(retention)})
+	}
+	for _, cat := range categories {
+		items = append(items, item{Type: "category", Item: cat, PurgeAt: cat.DeletedAt.Add(retention)})
+	}
+	slices.SortStableFunc(items, func(a, b item) int { return b.PurgeAt.Compare(a.PurgeAt) })
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// POST /api/v1/trash/transactions/:id/restore
+// Restores its category as well when that was deleted too
+func (h *TrashHandler) RestoreTransaction(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Transaction ID format"})
+		return
+	}
+
+	t, err := h.Repo.RestoreTransaction(c.Request.Context(), userID, transactionID)
+	if err != nil {
+		h.restoreFailed(c, err, "Transaction not found in trash", "Transaction with this external ID was imported again")
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// POST /api/v1/trash/categories/:id/restore
+func (h *TrashHandler) RestoreCategory(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category ID format"})
+		return
+	}
+
+	cat, err := h.Repo.RestoreCategory(c.Request.Context(), userID, categoryID)
+	if err != nil {
+		h.restoreFailed(c, err, "Category not found in trash", "Category with this name already exists")
+		return
+	}
+
+	c.JSON(http.StatusOK, cat)
+}
+
+func (h *TrashHandler) restoreFailed(c *gin.Context, err error, notFound, conflict string) {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		// A live row took the name or external ID since
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore"})
+	}
+}
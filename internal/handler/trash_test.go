@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTrashRepo struct {
+	mock.Mock
+}
+
+func (m *MockTrashRepo) ListDeletedTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTrashRepo) ListDeletedCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Category), args.Error(1)
+}
+
+func (m *MockTrashRepo) RestoreTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockTrashRepo) RestoreCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error) {
+	args := m.Called(ctx, userID, categoryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockTrashRepo) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, []string, error) {
+	return 0, nil, nil // Not used by the handler
+}
+
+func newTrashRouter(repo *MockTrashRepo, userID uuid.UUID) *gin.Engine {
+	h := &TrashHandler{Repo: repo, Service: &service.TrashService{Repo: repo, Retention: 7 * 24 * time.Hour}}
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		ctx.Next()
+	})
+	r.GET("/api/v1/trash", h.ListTrash)
+	r.POST("/api/v1/trash/transactions/:id/restore", h.RestoreTransaction)
+	r.POST("/api/v1/trash/categories/:id/restore", h.RestoreCategory)
+	return r
+}
+
+func TestListTrash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	older := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	repo := new(MockTrashRepo)
+	repo.On("ListDeletedTransactions", mock.Anything, userID).Return([]*models.Transaction{{ID: uuid.New(), DeletedAt: &older}}, nil)
+	repo.On("ListDeletedCategories", mock.Anything, userID).Return([]*models.Category{{ID: uuid.New(), DeletedAt: &newer}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/trash", nil)
+	newTrashRouter(repo, userID).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data []struct {
+			Type    string    `json:"type"`
+			PurgeAt time.Time `json:"purge_at"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, "category", body.Data[0].Type, "most recently deleted first")
+	assert.Equal(t, "transaction", body.Data[1].Type)
+	assert.Equal(t, older.AddDate(0, 0, 7), body.Data[1].PurgeAt)
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	t.Run("Transaction", func(t *testing.T) {
+		transactionID := uuid.New()
+		repo := new(MockTrashRepo)
+		repo.On("RestoreTransaction", mock.Anything, userID, transactionID).Return(&models.Transaction{ID: transactionID}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/trash/transactions/"+transactionID.String()+"/restore", nil)
+		newTrashRouter(repo, userID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), transactionID.String())
+	})
+
+	t.Run("Not In Trash", func(t *testing.T) {
+		transactionID := uuid.New()
+		repo := new(MockTrashRepo)
+		repo.On("RestoreTransaction", mock.Anything, userID, transactionID).Return(nil, repository.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/trash/transactions/"+transactionID.String()+"/restore", nil)
+		newTrashRouter(repo, userID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("External ID Taken", func(t *testing.T) {
+		transactionID := uuid.New()
+		repo := new(MockTrashRepo)
+		repo.On("RestoreTransaction", mock.Anything, userID, transactionID).Return(nil, &pgconn.PgError{Code: "23505"})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/trash/transactions/"+transactionID.String()+"/restore", nil)
+		newTrashRouter(repo, userID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "external ID")
+	})
+
+	t.Run("Category Name Taken", func(t *testing.T) {
+		categoryID := uuid.New()
+		repo := new(MockTrashRepo)
+		repo.On("RestoreCategory", mock.Anything, userID, categoryID).Return(nil, &pgconn.PgError{Code: "23505"})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/trash/categories/"+categoryID.String()+"/restore", nil)
+		newTrashRouter(repo, userID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
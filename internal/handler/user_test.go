@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/olmits/budget-tracker-backend/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,6 +35,10 @@ func (m *MockUserRepo) GetUserByEmail(ctx context.Context, email string) (*model
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepo) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	return nil, nil // Not used in these tests
+}
+
 func TestRegister(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/middleware"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/internal/service"
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+)
+
+type WebhookHandler struct {
+	Repo    repository.WebhookRepository
+	Service *service.WebhookService
+}
+
+type WebhookEndpointRequest struct {
+	URL     string   `json:"url" binding:"required"`
+	Events  []string `json:"events"`  // Empty subscribes to every event
+	Enabled *bool    `json:"enabled"` // Defaults to true
+}
+
+// toEndpoint validates the request and maps it to a model.
+// It writes the error response itself and returns false on failure.
+func (h *WebhookHandler) toEndpoint(c *gin.Context, userID uuid.UUID) (*models.WebhookEndpoint, bool) {
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	e := &models.WebhookEndpoint{
+		UserId:  userID,
+		URL:     req.URL,
+		Events:  req.Events,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+	if e.Events == nil {
+		e.Events = []string{}
+	}
+	if err := h.Service.ValidateEndpoint(c.Request.Context(), e); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return e, true
+}
+
+// POST /api/v1/webhooks
+// The response is the only time the signing secret is shown
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	e, ok := h.toEndpoint(c, userID)
+	if !ok {
+		return
+	}
+
+	e.Secret, err = webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	if err := h.Repo.CreateEndpoint(c.Request.Context(), e); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, e)
+}
+
+// GET /api/v1/webhooks
+func (h *WebhookHandler) ListEndpoints(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpoints, err := h.Repo.ListEndpoints(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+	for _, e := range endpoints {
+		e.Secret = ""
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": endpoints})
+}
+
+// GET /api/v1/webhooks/:id
+func (h *WebhookHandler) GetEndpoint(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Webhook ID format"})
+		return
+	}
+
+	e, err := h.Repo.GetEndpoint(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return
+	}
+	e.Secret = ""
+
+	c.JSON(http.StatusOK, e)
+}
+
+// PUT /api/v1/webhooks/:id
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Webhook ID format"})
+		return
+	}
+
+	e, ok := h.toEndpoint(c, userID)
+	if !ok {
+		return
+	}
+	e.ID = endpointID
+
+	if err := h.Repo.UpdateEndpoint(c.Request.Context(), e); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+	e.Secret = ""
+
+	c.JSON(http.StatusOK, e)
+}
+
+// DELETE /api/v1/webhooks/:id
+// Also drops the delivery log and anything still queued
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Webhook ID format"})
+		return
+	}
+
+	if err := h.Repo.DeleteEndpoint(c.Request.Context(), userID, endpointID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/webhooks/:id/test
+// Sends a sample "webhook.test" event right away and returns the delivery
+func (h *WebhookHandler) TestEndpoint(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Webhook ID format"})
+		return
+	}
+
+	delivery, err := h.Service.SendTest(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// GET /api/v1/webhooks/:id/deliveries?limit=50
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Webhook ID format"})
+		return
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'limit' value, expected 1 to 200"})
+			return
+		}
+	}
+
+	deliveries, err := h.Repo.ListDeliveries(c.Request.Context(), userID, endpointID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
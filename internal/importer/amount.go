@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseAmount converts a decimal string into signed cents. It accepts both
+// "1,234.56" and "1.234,56" styles, a leading "+"/"-" and a trailing "-".
+func ParseAmount(s string) (int64, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ReplaceAll(s, "'", "")
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "−"):
+		negative = true
+		s = strings.TrimLeft(s, "-−")
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasSuffix(s, "-"):
+		negative = true
+		s = strings.TrimSuffix(s, "-")
+	}
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.Trim(s, "()")
+	}
+
+	// The last separator is the decimal one, the others group thousands
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0:
+		// A single comma followed by 3 digits is a thousands separator ("1,234")
+		if strings.Count(s, ",") == 1 && len(s)-lastComma-1 != 3 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	}
+
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+
+	cents := int64(math.Round(value * 100))
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// parseMoney is ParseAmount for display values carrying a currency symbol or
+// code, like "$1,234.56", "-$5" or "42,50 EUR".
+func parseMoney(s string) (int64, error) {
+	number := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789.,-+()", r) {
+			return r
+		}
+		return -1
+	}, s)
+	if number == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return ParseAmount(number)
+}
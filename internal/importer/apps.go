@@ -0,0 +1,276 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// errSkipRow marks rows that are valid but not imported, e.g. transfers
+// between the user's own accounts, which are neither income nor expense.
+var errSkipRow = errors.New("row skipped")
+
+// csvRow gives access to a row by (case-insensitive) column name
+type csvRow struct {
+	header map[string]int
+	values []string
+}
+
+func (r csvRow) get(column string) string {
+	i, ok := r.header[strings.ToLower(column)]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+// appCSVParser reads the CSV export of another budgeting app. Each preset
+// knows the app's columns and how to turn a row into a record.
+type appCSVParser struct {
+	Name     string
+	Required []string                          // Columns that identify the export
+	Record   func(row csvRow) (*Record, error) // May return errSkipRow
+}
+
+func (p *appCSVParser) Parse(r io.Reader) (*Batch, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	headerRow, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CSV header: %w", err)
+	}
+	header := make(map[string]int, len(headerRow))
+	for i, name := range headerRow {
+		name = strings.TrimPrefix(name, "\ufeff") // Excel adds a BOM
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range p.Required {
+		if _, ok := header[strings.ToLower(column)]; !ok {
+			return nil, fmt.Errorf("column %q missing, is this a %s export?", column, p.Name)
+		}
+	}
+
+	batch := &Batch{Format: strings.ToLower(p.Name)}
+	seen := make(map[string]int)
+	for line := 1; ; line++ {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				batch.addError(line, "", err)
+				continue
+			}
+			return nil, err
+		}
+		if len(values) == 1 && strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+
+		record, err := p.Record(csvRow{header: header, values: values})
+		switch {
+		case errors.Is(err, errSkipRow):
+			batch.Skipped++
+			continue
+		case err != nil:
+			batch.addError(line, "", err)
+			continue
+		}
+		if record.ExternalID == "" {
+			record.ExternalID = fallbackID(batch.Format, seen, record)
+		}
+		record.Description = truncate(record.Description, 255)
+		record.Category = truncate(record.Category, 50)
+		batch.Records = append(batch.Records, record)
+	}
+	return batch, nil
+}
+
+// parseAppDate tries the given layouts in order
+func parseAppDate(value string, layouts ...string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func joinDescription(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.Join(strings.Fields(p), " "); p != "" && !containsFold(kept, p) {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " - ")
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ynabParser reads the register export of YNAB ("Account","Flag","Date","Payee",
+// "Category Group/Category","Category Group","Category","Memo","Outflow","Inflow","Cleared").
+var ynabParser = &appCSVParser{
+	Name:     "YNAB",
+	Required: []string{"Date", "Payee", "Outflow", "Inflow"},
+	Record: func(row csvRow) (*Record, error) {
+		payee := row.get("Payee")
+		if strings.HasPrefix(payee, "Transfer : ") {
+			return nil, errSkipRow
+		}
+
+		date, err := parseAppDate(row.get("Date"), "01/02/2006", "2006-01-02", "02.01.2006")
+		if err != nil {
+			return nil, err
+		}
+		outflow, inflow := int64(0), int64(0)
+		if v := row.get("Outflow"); v != "" {
+			if outflow, err = parseMoney(v); err != nil {
+				return nil, err
+			}
+		}
+		if v := row.get("Inflow"); v != "" {
+			if inflow, err = parseMoney(v); err != nil {
+				return nil, err
+			}
+		}
+
+		category := row.get("Category")
+		if category == "" {
+			// Older exports only have "Group: Category"
+			combined := row.get("Category Group/Category")
+			if i := strings.LastIndex(combined, ":"); i >= 0 {
+				category = strings.TrimSpace(combined[i+1:])
+			} else {
+				category = combined
+			}
+		}
+
+		record := &Record{
+			Date:         date,
+			Amount:       inflow - outflow,
+			Description:  joinDescription(payee, row.get("Memo")),
+			Category:     category,
+			CategoryType: "expense",
+		}
+		// Money to be budgeted is income, YNAB has no income categories
+		switch strings.ToLower(category) {
+		case "ready to assign", "to be budgeted":
+			record.Category, record.CategoryType = "", "income"
+		}
+		if record.CategoryType == "expense" && record.Amount > 0 && category == "" {
+			record.CategoryType = "income"
+		}
+		return record, nil
+	},
+}
+
+// Mint categories that move money between own accounts
+var mintTransferCategories = map[string]bool{
+	"transfer": true, "credit card payment": true, "transfer for cash spending": true,
+}
+
+// mintParser reads the Mint transactions export ("Date","Description","Original Description",
+// "Amount","Transaction Type","Category","Account Name","Labels","Notes").
+var mintParser = &appCSVParser{
+	Name:     "Mint",
+	Required: []string{"Date", "Description", "Amount", "Transaction Type", "Category"},
+	Record: func(row csvRow) (*Record, error) {
+		category := row.get("Category")
+		if mintTransferCategories[strings.ToLower(category)] {
+			return nil, errSkipRow
+		}
+
+		date, err := parseAppDate(row.get("Date"), "1/2/2006", "2006-01-02")
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseMoney(row.get("Amount"))
+		if err != nil {
+			return nil, err
+		}
+		if amount < 0 {
+			amount = -amount
+		}
+
+		record := &Record{
+			Date:         date,
+			Amount:       -amount,
+			Description:  joinDescription(row.get("Description"), row.get("Notes")),
+			Category:     category,
+			CategoryType: "expense",
+		}
+		switch strings.ToLower(row.get("Transaction Type")) {
+		case "credit":
+			record.Amount, record.CategoryType = amount, "income"
+		case "debit":
+		default:
+			return nil, fmt.Errorf("invalid transaction type %q", row.get("Transaction Type"))
+		}
+		if strings.EqualFold(category, "Uncategorized") {
+			record.Category = ""
+		}
+		return record, nil
+	},
+}
+
+// fireflyParser reads the Firefly III transaction export ("journal_id","type",
+// "amount","description","date","source_name","destination_name","category",...).
+var fireflyParser = &appCSVParser{
+	Name:     "Firefly",
+	Required: []string{"type", "amount", "description", "date"},
+	Record: func(row csvRow) (*Record, error) {
+		var categoryType, counterparty string
+		switch strings.ToLower(row.get("type")) {
+		case "withdrawal":
+			categoryType, counterparty = "expense", row.get("destination_name")
+		case "deposit":
+			categoryType, counterparty = "income", row.get("source_name")
+		case "transfer", "opening balance", "reconciliation":
+			return nil, errSkipRow
+		default:
+			return nil, fmt.Errorf("invalid transaction type %q", row.get("type"))
+		}
+
+		date, err := parseAppDate(row.get("date"), time.RFC3339, "2006-01-02 15:04:05", "2006-01-02")
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseMoney(row.get("amount"))
+		if err != nil {
+			return nil, err
+		}
+		if amount < 0 {
+			amount = -amount
+		}
+		if categoryType == "expense" {
+			amount = -amount
+		}
+
+		record := &Record{
+			Date:         date,
+			Amount:       amount,
+			Description:  joinDescription(row.get("description"), counterparty),
+			Category:     row.get("category"),
+			CategoryType: categoryType,
+		}
+		if id := row.get("journal_id"); id != "" {
+			record.ExternalID = "firefly:" + id
+		}
+		return record, nil
+	},
+}
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ynabCSV = "\ufeff" + `"Account","Flag","Date","Payee","Category Group/Category","Category Group","Category","Memo","Outflow","Inflow","Cleared"
+"Checking","","03/05/2026","Whole Foods","Everyday: Groceries","Everyday","Groceries","weekly","$42.50","$0.00","Cleared"
+"Checking","","03/15/2026","ACME","Inflow: Ready to Assign","Inflow","Ready to Assign","","$0.00","$2,500.00","Cleared"
+"Checking","","03/16/2026","Transfer : Savings","","","","","$100.00","$0.00","Cleared"
+"Checking","","2026-13-01","Broken","","","","","$1.00","$0.00","Cleared"
+`
+
+const mintCSV = `"Date","Description","Original Description","Amount","Transaction Type","Category","Account Name","Labels","Notes"
+"3/05/2026","Netflix","NETFLIX.COM","15.99","debit","Television","Visa","",""
+"3/15/2026","Paycheck","ACME PAYROLL","2500.00","credit","Paycheck","Checking","",""
+"3/20/2026","Visa payment","PAYMENT THANK YOU","300.00","credit","Credit Card Payment","Visa","",""
+`
+
+const fireflyCSV = `user_id,group_id,journal_id,created_at,updated_at,group_title,type,currency_code,amount,foreign_currency_code,foreign_amount,description,date,source_name,source_iban,source_type,destination_name,destination_iban,destination_type,reconciled,category,budget,bill,tags,notes
+1,10,101,,,,Withdrawal,EUR,-38.00,,,Dinner,2026-03-06T00:00:00+01:00,Checking,,Asset account,Pizzeria Roma,,Expense account,false,Dining out,,,,
+1,11,102,,,,Deposit,EUR,2500.00,,,Salary March,2026-03-31T00:00:00+02:00,ACME GmbH,,Revenue account,Checking,,Asset account,false,Salary,,,,
+1,12,103,,,,Transfer,EUR,-100.00,,,To savings,2026-03-31T00:00:00+02:00,Checking,,Asset account,Savings,,Asset account,false,,,,,
+`
+
+func TestYNABParser(t *testing.T) {
+	batch, err := ynabParser.Parse(strings.NewReader(ynabCSV))
+	require.NoError(t, err)
+
+	require.Len(t, batch.Records, 2)
+	groceries, income := batch.Records[0], batch.Records[1]
+	assert.Equal(t, int64(-4250), groceries.Amount)
+	assert.Equal(t, "Groceries", groceries.Category)
+	assert.Equal(t, "expense", groceries.CategoryType)
+	assert.Equal(t, "Whole Foods - weekly", groceries.Description)
+	assert.Equal(t, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), groceries.Date)
+	assert.True(t, strings.HasPrefix(groceries.ExternalID, "ynab:h"))
+
+	// "Ready to Assign" is income without a category
+	assert.Equal(t, int64(250000), income.Amount)
+	assert.Equal(t, "", income.Category)
+	assert.Equal(t, "income", income.CategoryType)
+
+	assert.Equal(t, 1, batch.Skipped)
+	require.Len(t, batch.Errors, 1)
+	assert.Equal(t, 4, batch.Errors[0].Entry)
+}
+
+func TestMintParser(t *testing.T) {
+	batch, err := mintParser.Parse(strings.NewReader(mintCSV))
+	require.NoError(t, err)
+
+	require.Len(t, batch.Records, 2)
+	assert.Equal(t, int64(-1599), batch.Records[0].Amount)
+	assert.Equal(t, "Television", batch.Records[0].Category)
+	assert.Equal(t, int64(250000), batch.Records[1].Amount)
+	assert.Equal(t, "income", batch.Records[1].CategoryType)
+	assert.Equal(t, 1, batch.Skipped)
+}
+
+func TestFireflyParser(t *testing.T) {
+	batch, err := fireflyParser.Parse(strings.NewReader(fireflyCSV))
+	require.NoError(t, err)
+
+	require.Len(t, batch.Records, 2)
+	dinner, salary := batch.Records[0], batch.Records[1]
+	assert.Equal(t, "firefly:101", dinner.ExternalID)
+	assert.Equal(t, int64(-3800), dinner.Amount)
+	assert.Equal(t, "Dinner - Pizzeria Roma", dinner.Description)
+	assert.Equal(t, "Dining out", dinner.Category)
+	assert.Equal(t, time.Date(2026, time.March, 5, 23, 0, 0, 0, time.UTC), dinner.Date)
+
+	assert.Equal(t, int64(250000), salary.Amount)
+	assert.Equal(t, "income", salary.CategoryType)
+	assert.Equal(t, 1, batch.Skipped)
+}
+
+func TestAppCSVParserWrongExport(t *testing.T) {
+	_, err := fireflyParser.Parse(strings.NewReader(mintCSV))
+	assert.ErrorContains(t, err, "is this a Firefly export?")
+}
@@ -0,0 +1,228 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CAMT053Parser reads ISO 20022 camt.053 (Bank-to-Customer Statement) XML.
+// Element names are matched regardless of the schema version namespace.
+type CAMT053Parser struct{}
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN    string      `xml:"Acct>Id>IBAN"`
+	OtherID string      `xml:"Acct>Id>Othr>Id"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	NtryRef     string       `xml:"NtryRef"`
+	Amount      camtAmount   `xml:"Amt"`
+	Indicator   string       `xml:"CdtDbtInd"`
+	Status      camtStatus   `xml:"Sts"`
+	BookingDate camtDate     `xml:"BookgDt"`
+	ValueDate   camtDate     `xml:"ValDt"`
+	AcctSvcrRef string       `xml:"AcctSvcrRef"`
+	Info        string       `xml:"AddtlNtryInf"`
+	Details     []camtTxDtls `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTxDtls struct {
+	AcctSvcrRef string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID  string      `xml:"Refs>EndToEndId"`
+	Amount      *camtAmount `xml:"Amt"`
+	TxAmount    *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	Indicator   string      `xml:"CdtDbtInd"`
+	Debtor      string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty   string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Creditor    string      `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty string      `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Remittance  []string    `xml:"RmtInf>Ustrd"`
+	Info        string      `xml:"AddtlTxInf"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus is plain text ("BOOK") up to version 06 and wrapped in <Cd> later
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	switch {
+	case d.Date != "":
+		return time.Parse("2006-01-02", strings.TrimSpace(d.Date))
+	case d.DateTime != "":
+		s := strings.TrimSpace(d.DateTime)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid date-time %q", s)
+	default:
+		return time.Time{}, errors.New("missing date")
+	}
+}
+
+func (p *CAMT053Parser) Parse(r io.Reader) (*Batch, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 XML: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, errors.New("no Stmt element found, is this a camt.053 file?")
+	}
+
+	batch := &Batch{Format: "camt053"}
+	seen := make(map[string]int)
+	entryNo := 0
+
+	for _, stmt := range doc.Statements {
+		account := firstNonEmpty(stmt.IBAN, stmt.OtherID)
+
+		for _, entry := range stmt.Entries {
+			entryNo++
+			ref := firstNonEmpty(entry.AcctSvcrRef, entry.NtryRef)
+
+			// Pending entries may still change or disappear
+			if strings.EqualFold(firstNonEmpty(entry.Status.Code, entry.Status.Value), "PDNG") {
+				continue
+			}
+
+			records, err := camtRecords(account, entry)
+			if err != nil {
+				batch.addError(entryNo, ref, err)
+				continue
+			}
+			for _, record := range records {
+				if record.ExternalID == "" {
+					record.ExternalID = fallbackID("camt053:"+account, seen, record)
+				}
+				batch.Records = append(batch.Records, record)
+			}
+		}
+	}
+
+	return batch, nil
+}
+
+// camtRecords maps one entry to records. Batch bookings with per-transaction
+// amounts are split, otherwise the entry is a single record.
+func camtRecords(account string, entry camtEntry) ([]*Record, error) {
+	date, err := entry.BookingDate.parse()
+	if err != nil {
+		// Some banks only fill the value date
+		if date, err = entry.ValueDate.parse(); err != nil {
+			return nil, fmt.Errorf("booking date: %w", err)
+		}
+	}
+
+	split := len(entry.Details) > 1
+	for _, d := range entry.Details {
+		if d.amount() == nil {
+			split = false
+			break
+		}
+	}
+
+	if !split {
+		amount, err := camtSignedAmount(entry.Amount.Value, entry.Indicator)
+		if err != nil {
+			return nil, err
+		}
+		record := &Record{Date: date, Amount: amount}
+		var details camtTxDtls
+		if len(entry.Details) > 0 {
+			details = entry.Details[0]
+		}
+		record.Description = camtDescription(entry, details, amount)
+		if ref := firstNonEmpty(entry.AcctSvcrRef, details.AcctSvcrRef, entry.NtryRef); ref != "" {
+			record.ExternalID = "camt053:" + account + ":" + ref
+		}
+		return []*Record{record}, nil
+	}
+
+	records := make([]*Record, 0, len(entry.Details))
+	for i, d := range entry.Details {
+		amount, err := camtSignedAmount(d.amount().Value, firstNonEmpty(d.Indicator, entry.Indicator))
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		record := &Record{Date: date, Amount: amount, Description: camtDescription(entry, d, amount)}
+		if ref := firstNonEmpty(d.AcctSvcrRef, d.EndToEndID); ref != "" && ref != "NOTPROVIDED" {
+			record.ExternalID = "camt053:" + account + ":" + ref
+		} else if entryRef := firstNonEmpty(entry.AcctSvcrRef, entry.NtryRef); entryRef != "" {
+			record.ExternalID = fmt.Sprintf("camt053:%s:%s:%d", account, entryRef, i+1)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (d camtTxDtls) amount() *camtAmount {
+	if d.Amount != nil && strings.TrimSpace(d.Amount.Value) != "" {
+		return d.Amount
+	}
+	if d.TxAmount != nil && strings.TrimSpace(d.TxAmount.Value) != "" {
+		return d.TxAmount
+	}
+	return nil
+}
+
+func camtSignedAmount(value, indicator string) (int64, error) {
+	amount, err := ParseAmount(value)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	switch strings.ToUpper(strings.TrimSpace(indicator)) {
+	case "CRDT":
+		return amount, nil
+	case "DBIT":
+		return -amount, nil
+	default:
+		return 0, fmt.Errorf("invalid CdtDbtInd %q", indicator)
+	}
+}
+
+// camtDescription is "<counterparty> - <remittance info>"; for money out the
+// counterparty is the creditor, for money in it is the debtor.
+func camtDescription(entry camtEntry, d camtTxDtls, amount int64) string {
+	counterparty := firstNonEmpty(d.Creditor, d.CreditorPty)
+	if amount > 0 {
+		counterparty = firstNonEmpty(d.Debtor, d.DebtorPty)
+	}
+
+	remittance := strings.Join(d.Remittance, " ")
+	if remittance == "" {
+		remittance = firstNonEmpty(d.Info, entry.Info)
+	}
+
+	parts := []string{}
+	for _, s := range []string{counterparty, remittance} {
+		if s = strings.Join(strings.Fields(s), " "); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return truncate(strings.Join(parts, " - "), 255)
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const camt053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>STMT-2026-03</Id>
+      <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id></Acct>
+      <Ntry>
+        <Amt Ccy="EUR">42.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2026-03-05</Dt></BookgDt>
+        <ValDt><Dt>2026-03-06</Dt></ValDt>
+        <AcctSvcrRef>REF-001</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties>
+            <Dbtr><Nm>Jane Doe</Nm></Dbtr>
+            <Cdtr><Nm>REWE Markt GmbH</Nm></Cdtr>
+          </RltdPties>
+          <RmtInf><Ustrd>Einkauf 05.03.</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">2500.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2026-03-15T09:30:00+01:00</DtTm></BookgDt>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>SAL-03</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">2000.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><Dbtr><Nm>ACME GmbH</Nm></Dbtr></RltdPties>
+            <RmtInf><Ustrd>Gehalt Maerz</Ustrd></RmtInf>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">500.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><Dbtr><Nm>ACME GmbH</Nm></Dbtr></RltdPties>
+            <RmtInf><Ustrd>Bonus</Ustrd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+        <NtryRef>BATCH-7</NtryRef>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">9.99</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2026-03-20</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">3.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2026-03-21</Dt></BookgDt>
+        <AddtlNtryInf>Kontofuehrung</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">1.00</Amt>
+        <CdtDbtInd>XXX</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2026-03-22</Dt></BookgDt>
+        <AcctSvcrRef>REF-BAD</AcctSvcrRef>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func TestCAMT053Parser(t *testing.T) {
+	batch, err := (&CAMT053Parser{}).Parse(strings.NewReader(camt053))
+	require.NoError(t, err)
+	require.Len(t, batch.Records, 4)
+
+	first := batch.Records[0]
+	assert.Equal(t, "camt053:DE89370400440532013000:REF-001", first.ExternalID)
+	assert.Equal(t, int64(-4250), first.Amount)
+	assert.Equal(t, "REWE Markt GmbH - Einkauf 05.03.", first.Description)
+	assert.Equal(t, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), first.Date)
+
+	// The batch booking is split per transaction, the counterparty of money in is the debtor
+	salary, bonus := batch.Records[1], batch.Records[2]
+	assert.Equal(t, int64(200000), salary.Amount)
+	assert.Equal(t, "ACME GmbH - Gehalt Maerz", salary.Description)
+	assert.Equal(t, "camt053:DE89370400440532013000:SAL-03", salary.ExternalID)
+	assert.Equal(t, time.Date(2026, time.March, 15, 8, 30, 0, 0, time.UTC), salary.Date)
+	assert.Equal(t, int64(50000), bonus.Amount)
+	assert.Equal(t, "camt053:DE89370400440532013000:BATCH-7:2", bonus.ExternalID)
+
+	// No reference at all: a stable derived ID, pending entries are skipped
+	fee := batch.Records[3]
+	assert.Equal(t, int64(-300), fee.Amount)
+	assert.Equal(t, "Kontofuehrung", fee.Description)
+	assert.True(t, strings.HasPrefix(fee.ExternalID, "camt053:DE89370400440532013000:h"))
+
+	again, err := (&CAMT053Parser{}).Parse(strings.NewReader(camt053))
+	require.NoError(t, err)
+	assert.Equal(t, fee.ExternalID, again.Records[3].ExternalID)
+
+	require.Len(t, batch.Errors, 1)
+	assert.Equal(t, 5, batch.Errors[0].Entry)
+	assert.Equal(t, "REF-BAD", batch.Errors[0].Reference)
+
+	_, err = (&CAMT053Parser{}).Parse(strings.NewReader("<Document></Document>"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+// Package importer turns bank and app export files into plain records
+// that the import service stores as transactions.
+package importer
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+// Record is one statement entry, independent of the file format
+type Record struct {
+	ExternalID   string // Stable source ID, used to make re-imports idempotent
+	Date         time.Time
+	Amount       int64 // Signed cents: positive is money in, negative money out
+	Description  string
+	Category     string // Optional category name from the source
+	CategoryType string // Optional "income" or "expense", derived from the sign when empty
+	// Optional, the transaction the record was exported from when the file is
+	// one of our own exports. Importing it back into the same account is a no-op.
+	TransactionID *uuid.UUID
+}
+
+// Batch is the result of parsing one file. Entries that could not be parsed
+// are reported in Errors instead of failing the whole file.
+type Batch struct {
+	Format  string
+	Records []*Record
+	Errors  []*models.ImportError
+	Skipped int // Entries deliberately left out, e.g. transfers between own accounts
+}
+
+func (b *Batch) addError(entry int, reference string, err error) {
+	b.Errors = append(b.Errors, &models.ImportError{Entry: entry, Reference: reference, Message: err.Error()})
+}
+
+// Parser reads a whole file. It only returns an error when the file
+// as a whole is unreadable (wrong format, broken structure).
+type Parser interface {
+	Parse(r io.Reader) (*Batch, error)
+}
+
+var parsers = map[string]Parser{
+	"ofx":       &OFXParser{},
+	"qfx":       &OFXParser{},
+	"camt053":   &CAMT053Parser{},
+	"mt940":     &MT940Parser{},
+	"ledger":    &JournalParser{},
+	"hledger":   &JournalParser{},
+	"beancount": &JournalParser{},
+	"ynab":      ynabParser,
+	"mint":      mintParser,
+	"firefly":   fireflyParser,
+}
+
+// ForFormat returns the parser registered for a format name (e.g. "ofx")
+func ForFormat(format string) (Parser, error) {
+	p, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import format %q, expected one of %v", format, Formats())
+	}
+	return p, nil
+}
+
+// Formats lists the supported format names
+func Formats() []string {
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fallbackID derives an external ID for entries the bank gave no reference.
+// Identical entries in one file are told apart by their occurrence number,
+// so re-importing the same file still yields the same IDs.
+func fallbackID(prefix string, seen map[string]int, r *Record) string {
+	key := fmt.Sprintf("%s|%d|%s", r.Date.Format("2006-01-02"), r.Amount, r.Description)
+	seen[key]++
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+	return prefix + ":h" + hex.EncodeToString(sum[:8])
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
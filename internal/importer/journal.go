@@ -0,0 +1,274 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JournalParser reads simple plain-text accounting journals in ledger,
+// hledger or beancount syntax. Every Expenses:* or Income:* posting of a
+// transaction becomes a record; the category is the last account component
+// ("Expenses:Food:Dining-out" is "Dining out").
+// Directives (open, balance, price...) and transactions without such a
+// posting, e.g. transfers between asset accounts, are not records.
+type JournalParser struct{}
+
+type journalEntry struct {
+	number      int
+	date        string
+	description string
+	id          string
+	postings    []journalPosting
+}
+
+type journalPosting struct {
+	account string
+	amount  *int64 // nil when elided
+}
+
+var (
+	journalDatePattern = regexp.MustCompile(`^(\d{4})[-/.](\d{2})[-/.](\d{2})(?:=\S+)?$`)
+	journalMetaPattern = regexp.MustCompile(`^([a-z][A-Za-z0-9_-]*):\s*(.*)$`)
+
+	// Beancount directives sharing the "<date> <keyword>" layout of transactions
+	beancountDirectives = map[string]bool{
+		"open": true, "close": true, "balance": true, "pad": true, "note": true, "document": true,
+		"event": true, "price": true, "commodity": true, "query": true, "custom": true,
+	}
+)
+
+func (p *JournalParser) Parse(r io.Reader) (*Batch, error) {
+	batch := &Batch{Format: "ledger"}
+	seen := make(map[string]int)
+	var (
+		entry *journalEntry
+		count int
+	)
+
+	flush := func() {
+		if entry == nil {
+			return
+		}
+		records, err := journalRecords(entry)
+		if err != nil {
+			batch.addError(entry.number, entry.id, err)
+		}
+		for _, record := range records {
+			if record.ExternalID == "" {
+				record.ExternalID = fallbackID("journal", seen, record)
+			}
+			batch.Records = append(batch.Records, record)
+		}
+		entry = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case line[0] != ' ' && line[0] != '\t':
+			// A new top-level item ends the current transaction
+			flush()
+			if header := parseJournalHeader(trimmed); header != nil {
+				count++
+				header.number = count
+				entry = header
+			}
+		case entry == nil:
+			continue
+		case strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#"):
+			if m := journalMetaPattern.FindStringSubmatch(strings.TrimSpace(trimmed[1:])); m != nil && m[1] == "id" {
+				entry.id = strings.Trim(strings.TrimSpace(m[2]), `"`)
+			}
+		default:
+			if m := journalMetaPattern.FindStringSubmatch(trimmed); m != nil {
+				// Beancount metadata
+				if m[1] == "id" {
+					entry.id = strings.Trim(strings.TrimSpace(m[2]), `"`)
+				}
+				continue
+			}
+			posting, err := parseJournalPosting(trimmed)
+			if err != nil {
+				batch.addError(entry.number, entry.id, err)
+				entry = nil
+				continue
+			}
+			entry.postings = append(entry.postings, posting)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	if count == 0 {
+		return nil, errors.New("no transactions found in journal")
+	}
+	return batch, nil
+}
+
+// parseJournalHeader returns nil for lines that do not start a transaction
+func parseJournalHeader(line string) *journalEntry {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !journalDatePattern.MatchString(fields[0]) {
+		return nil
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+	if len(fields) > 1 && beancountDirectives[fields[1]] {
+		return nil
+	}
+
+	entry := &journalEntry{date: fields[0]}
+
+	// Flag ("*", "!", beancount "txn") and ledger "(code)"
+	for _, flag := range []string{"*", "!", "txn"} {
+		if strings.HasPrefix(rest, flag+" ") || rest == flag {
+			rest = strings.TrimSpace(rest[len(flag):])
+			break
+		}
+	}
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.IndexByte(rest, ')'); end > 0 {
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+
+	if strings.HasPrefix(rest, `"`) {
+		// Beancount: ["payee"] "narration" [#tags ^links]
+		var strs []string
+		for strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				break
+			}
+			strs = append(strs, rest[1:end+1])
+			rest = strings.TrimSpace(rest[end+2:])
+		}
+		parts := []string{}
+		for _, s := range strs {
+			if s = strings.TrimSpace(s); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		entry.description = strings.Join(parts, " - ")
+	} else {
+		entry.description = cutJournalComment(rest)
+	}
+	return entry
+}
+
+func parseJournalPosting(line string) (journalPosting, error) {
+	line = cutJournalComment(line)
+	for _, flag := range []string{"* ", "! "} {
+		line = strings.TrimPrefix(line, flag)
+	}
+
+	// The account ends at a tab or two spaces, the amount follows
+	account, amountText := line, ""
+	if i := strings.IndexAny(line, "\t"); i >= 0 {
+		account, amountText = line[:i], line[i+1:]
+	}
+	if i := strings.Index(account, "  "); i >= 0 {
+		account, amountText = account[:i], account[i+2:]+" "+amountText
+	}
+	posting := journalPosting{account: strings.TrimSpace(account)}
+
+	// Drop prices, costs and balance assertions
+	if i := strings.IndexAny(amountText, "@{="); i >= 0 {
+		amountText = amountText[:i]
+	}
+	if amountText = strings.TrimSpace(amountText); amountText == "" {
+		return posting, nil
+	}
+
+	amount, err := parseMoney(amountText)
+	if err != nil {
+		return posting, fmt.Errorf("posting %s: %w", posting.account, err)
+	}
+	posting.amount = &amount
+	return posting, nil
+}
+
+// cutJournalComment strips a trailing ";" comment
+func cutJournalComment(s string) string {
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func journalRecords(entry *journalEntry) ([]*Record, error) {
+	m := journalDatePattern.FindStringSubmatch(entry.date)
+	date, err := time.Parse("2006-01-02", m[1]+"-"+m[2]+"-"+m[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", entry.date)
+	}
+
+	// One posting may leave its amount out, it balances the others
+	var sum int64
+	elided := -1
+	for i, p := range entry.postings {
+		if p.amount == nil {
+			if elided >= 0 {
+				return nil, errors.New("more than one posting without an amount")
+			}
+			elided = i
+			continue
+		}
+		sum += *p.amount
+	}
+	if elided >= 0 {
+		missing := -sum
+		entry.postings[elided].amount = &missing
+	}
+
+	var categories []journalPosting
+	for _, p := range entry.postings {
+		root, _, _ := strings.Cut(p.account, ":")
+		if strings.EqualFold(root, "Expenses") || strings.EqualFold(root, "Income") {
+			categories = append(categories, p)
+		}
+	}
+	if len(categories) == 0 {
+		return nil, errors.New("no Expenses or Income posting")
+	}
+
+	records := make([]*Record, 0, len(categories))
+	for i, p := range categories {
+		components := strings.Split(p.account, ":")
+		record := &Record{
+			Date:         date,
+			Amount:       -*p.amount, // Expenses are debits, money out of the asset account
+			Description:  truncate(entry.description, 255),
+			Category:     truncate(strings.ReplaceAll(components[len(components)-1], "-", " "), 50),
+			CategoryType: "expense",
+		}
+		if strings.EqualFold(components[0], "Income") {
+			record.CategoryType = "income"
+		}
+		if entry.id != "" {
+			record.ExternalID = "journal:" + entry.id
+			if len(categories) > 1 {
+				record.ExternalID = fmt.Sprintf("journal:%s:%d", entry.id, i+1)
+			} else if id, err := uuid.Parse(entry.id); err == nil {
+				// Our journal export tags every transaction with its ID
+				record.TransactionID = &id
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
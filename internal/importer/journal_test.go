@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ledgerJournal = `; Hand-written ledger file
+account Assets:Checking
+
+2026/03/05 * (1042) Whole Foods  ; weekly shop
+    Expenses:Food:Groceries        $42.50
+    Expenses:Household              $7.50
+    Assets:Checking
+
+2026-03-15 ACME Payroll
+    ; id: pay-03
+    Assets:Checking              2,500.00 USD
+    Income:Salary
+
+2026-03-20 Transfer to savings
+    Assets:Savings                100.00 USD
+    Assets:Checking
+
+2026-03-21 Broken
+    Expenses:Misc                 abc USD
+    Assets:Checking
+`
+
+const beancountJournal = `option "operating_currency" "EUR"
+
+2026-01-01 open Assets:Checking
+2026-01-01 open Expenses:Dining-out
+
+2026-03-06 * "Pizzeria Roma" "Dinner with friends" #trip
+  id: "abc-1"
+  Expenses:Dining-out   38.00 EUR
+  Assets:Checking
+
+2026-03-07 balance Assets:Checking  100.00 EUR
+`
+
+func TestJournalParser(t *testing.T) {
+	t.Run("Ledger", func(t *testing.T) {
+		batch, err := (&JournalParser{}).Parse(strings.NewReader(ledgerJournal))
+		require.NoError(t, err)
+
+		// A split transaction gives one record per category posting
+		require.Len(t, batch.Records, 3)
+		groceries, household, salary := batch.Records[0], batch.Records[1], batch.Records[2]
+		assert.Equal(t, "Groceries", groceries.Category)
+		assert.Equal(t, int64(-4250), groceries.Amount)
+		assert.Equal(t, "Whole Foods", groceries.Description)
+		assert.Equal(t, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), groceries.Date)
+		assert.Equal(t, int64(-750), household.Amount)
+		assert.NotEqual(t, groceries.ExternalID, household.ExternalID)
+
+		// The elided income posting balances the asset one
+		assert.Equal(t, "journal:pay-03", salary.ExternalID)
+		assert.Equal(t, int64(250000), salary.Amount)
+		assert.Equal(t, "income", salary.CategoryType)
+
+		// Transfers and broken postings are reported per entry
+		require.Len(t, batch.Errors, 2)
+		assert.Equal(t, 3, batch.Errors[0].Entry)
+		assert.Equal(t, 4, batch.Errors[1].Entry)
+	})
+
+	t.Run("Beancount", func(t *testing.T) {
+		batch, err := (&JournalParser{}).Parse(strings.NewReader(beancountJournal))
+		require.NoError(t, err)
+		require.Empty(t, batch.Errors)
+
+		require.Len(t, batch.Records, 1)
+		record := batch.Records[0]
+		assert.Equal(t, "journal:abc-1", record.ExternalID)
+		assert.Equal(t, "Pizzeria Roma - Dinner with friends", record.Description)
+		assert.Equal(t, "Dining out", record.Category)
+		assert.Equal(t, int64(-3800), record.Amount)
+	})
+
+	t.Run("Re-parsing gives the same IDs", func(t *testing.T) {
+		first, err := (&JournalParser{}).Parse(strings.NewReader(ledgerJournal))
+		require.NoError(t, err)
+		second, err := (&JournalParser{}).Parse(strings.NewReader(ledgerJournal))
+		require.NoError(t, err)
+		assert.Equal(t, first.Records[0].ExternalID, second.Records[0].ExternalID)
+	})
+
+	t.Run("Exported transaction IDs", func(t *testing.T) {
+		journal := `2026-03-06 * Pizzeria
+    ; id: 4f9d2c1e-8b7a-4c3d-9e2f-1a2b3c4d5e6f
+    Expenses:Dining-out        38.00 EUR
+    Assets:Checking
+`
+		batch, err := (&JournalParser{}).Parse(strings.NewReader(journal))
+		require.NoError(t, err)
+		require.Len(t, batch.Records, 1)
+		require.NotNil(t, batch.Records[0].TransactionID)
+		assert.Equal(t, "4f9d2c1e-8b7a-4c3d-9e2f-1a2b3c4d5e6f", batch.Records[0].TransactionID.String())
+
+		// Other IDs are the source's own
+		batch, err = (&JournalParser{}).Parse(strings.NewReader(beancountJournal))
+		require.NoError(t, err)
+		assert.Nil(t, batch.Records[0].TransactionID)
+	})
+
+	t.Run("No transactions", func(t *testing.T) {
+		_, err := (&JournalParser{}).Parse(strings.NewReader("option \"title\" \"x\"\n"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MT940Parser reads SWIFT MT940 customer statements. Every :61: statement
+// line becomes a record, described by the :86: information field after it.
+type MT940Parser struct{}
+
+// mt940Field is one ":tag:value" field; continuation lines are kept with "\n"
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+var mt940TagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+
+func (p *MT940Parser) Parse(r io.Reader) (*Batch, error) {
+	fields, err := readMT940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &Batch{Format: "mt940"}
+	seen := make(map[string]int)
+	var (
+		account string
+		line    *mt940Field // Pending :61: waiting for its :86:
+		count   int
+	)
+
+	flush := func(info string) {
+		if line == nil {
+			return
+		}
+		record, ref, err := mt940Record(account, line.value, info)
+		if err != nil {
+			batch.addError(count, ref, err)
+		} else {
+			if record.ExternalID == "" {
+				record.ExternalID = fallbackID("mt940:"+account, seen, record)
+			}
+			batch.Records = append(batch.Records, record)
+		}
+		line = nil
+	}
+
+	for i := range fields {
+		f := &fields[i]
+		switch f.tag {
+		case "25":
+			flush("")
+			account = strings.TrimSpace(f.value)
+		case "61":
+			flush("")
+			line = f
+			count++
+		case "86":
+			flush(f.value)
+		default:
+			flush("")
+		}
+	}
+	flush("")
+
+	if count == 0 {
+		return nil, errors.New("no :61: statement lines found in MT940 file")
+	}
+	return batch, nil
+}
+
+// readMT940Fields splits the message text into fields, dropping the SWIFT
+// envelope ("{1:...}{4:" and "-}") that some banks leave in exports.
+func readMT940Fields(r io.Reader) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r ")
+		if i := strings.Index(text, "{4:"); i >= 0 {
+			text = text[i+3:]
+		}
+		if text == "" || text == "-" || strings.HasPrefix(text, "-}") || strings.HasPrefix(text, "{") {
+			continue
+		}
+
+		if m := mt940TagPattern.FindStringSubmatch(text); m != nil {
+			fields = append(fields, mt940Field{tag: m[1], value: text[len(m[0]):]})
+			continue
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// :61: value date (YYMMDD), optional entry date (MMDD), mark (C, D, RC, RD),
+// optional funds code, amount, transaction type, customer reference and
+// an optional "//bank reference", supplementary details on the next line.
+var mt940LinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d*)([NSF][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?(?:\n(.*))?`)
+
+func mt940Record(account, line, info string) (*Record, string, error) {
+	m := mt940LinePattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, "", fmt.Errorf("invalid :61: line %q", truncate(strings.ReplaceAll(line, "\n", " "), 60))
+	}
+	customerRef, bankRef := strings.TrimSpace(m[7]), strings.TrimSpace(m[8])
+	ref := firstNonEmpty(bankRef, customerRef)
+
+	date, err := mt940Date(m[1], m[2])
+	if err != nil {
+		return nil, ref, err
+	}
+
+	amount, err := ParseAmount(strings.Replace(m[5], ",", ".", 1) + "0")
+	if err != nil {
+		return nil, ref, err
+	}
+	// A reversal of a credit takes money out and vice versa
+	if m[3] == "D" || m[3] == "RC" {
+		amount = -amount
+	}
+
+	description := mt940Description(info)
+	if description == "" {
+		description = firstNonEmpty(m[9], customerRef)
+	}
+
+	record := &Record{Date: date, Amount: amount, Description: truncate(description, 255)}
+	for _, r := range []string{bankRef, customerRef} {
+		if r != "" && !strings.EqualFold(r, "NONREF") {
+			record.ExternalID = "mt940:" + account + ":" + r
+			break
+		}
+	}
+	return record, ref, nil
+}
+
+// mt940Date prefers the booking (entry) date over the value date. The entry
+// date has no year: it is taken from the value date, adjusted at year end.
+func mt940Date(valueDate, entryDate string) (time.Time, error) {
+	value, err := time.Parse("060102", valueDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value date %q", valueDate)
+	}
+	if entryDate == "" {
+		return value, nil
+	}
+
+	booked, err := time.Parse("20060102", fmt.Sprintf("%04d%s", value.Year(), entryDate))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid entry date %q", entryDate)
+	}
+	switch {
+	case booked.Sub(value) > 180*24*time.Hour:
+		booked = booked.AddDate(-1, 0, 0)
+	case value.Sub(booked) > 180*24*time.Hour:
+		booked = booked.AddDate(1, 0, 0)
+	}
+	return booked, nil
+}
+
+// mt940Description turns a :86: field into "<counterparty> - <remittance info>".
+// The structured German (DFÜ) layout "ddd?00...?20...?32..." is split into its
+// subfields; anything else is used as free text.
+func mt940Description(info string) string {
+	info = strings.ReplaceAll(info, "\n", "")
+	if len(info) < 4 || info[3] != '?' {
+		return strings.Join(strings.Fields(info), " ")
+	}
+
+	var counterparty, remittance, posting []string
+	for _, part := range strings.Split(info[3:], "?")[1:] {
+		if len(part) < 2 {
+			continue
+		}
+		code, text := part[:2], strings.TrimSpace(part[2:])
+		if text == "" {
+			continue
+		}
+		switch {
+		case code == "32" || code == "33":
+			counterparty = append(counterparty, text)
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			remittance = append(remittance, text)
+		case code == "00":
+			posting = append(posting, text)
+		}
+	}
+
+	if len(remittance) == 0 {
+		remittance = posting
+	}
+	parts := []string{}
+	for _, s := range []string{strings.Join(counterparty, ""), strings.Join(remittance, " ")} {
+		if s = strings.Join(strings.Fields(s), " "); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mt940 = `{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:
+:20:STARTUMS
+:25:37040044/0532013000
+:28C:00001/001
+:60F:C260301EUR1234,56
+:61:2603050305DR42,50NMSCNONREF//BANKREF123
+:86:005?00KARTENZAHLUNG?20EREF+2026030512?21Einkauf
+ 05.03.?32REWE MARKT?33GMBH
+:61:2512311231C2500,NTRFSAL03
+:86:Gehalt Dezember ACME GmbH
+:61:2601020102D3,NCHGNONREF
+:86:Kontofuehrung
+:61:26010X
+:86:broken
+:62F:C260331EUR3691,06
+-}`
+
+func TestMT940Parser(t *testing.T) {
+	batch, err := (&MT940Parser{}).Parse(strings.NewReader(mt940))
+	require.NoError(t, err)
+	require.Len(t, batch.Records, 3)
+
+	first := batch.Records[0]
+	assert.Equal(t, "mt940:37040044/0532013000:BANKREF123", first.ExternalID)
+	assert.Equal(t, int64(-4250), first.Amount)
+	assert.Equal(t, "REWE MARKTGMBH - EREF+2026030512 Einkauf 05.03.", first.Description)
+	assert.Equal(t, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), first.Date)
+
+	salary := batch.Records[1]
+	assert.Equal(t, int64(250000), salary.Amount)
+	assert.Equal(t, "mt940:37040044/0532013000:SAL03", salary.ExternalID)
+	assert.Equal(t, "Gehalt Dezember ACME GmbH", salary.Description)
+	assert.Equal(t, time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC), salary.Date)
+
+	fee := batch.Records[2]
+	assert.Equal(t, int64(-300), fee.Amount)
+	assert.True(t, strings.HasPrefix(fee.ExternalID, "mt940:37040044/0532013000:h"))
+
+	require.Len(t, batch.Errors, 1)
+	assert.Equal(t, 4, batch.Errors[0].Entry)
+
+	_, err = (&MT940Parser{}).Parse(strings.NewReader(":20:X\n:25:Y\n"))
+	assert.Error(t, err)
+}
+
+func TestMT940Date(t *testing.T) {
+	// Booked in January for a December value date
+	got, err := mt940Date("251231", "0102")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = mt940Date("260102", "1231")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC), got)
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OFXParser reads OFX 1.x (SGML) and 2.x (XML) statements, including the
+// QFX flavour. Both versions are handled by the same tokenizer: SGML leaf
+// elements have no closing tag, so we simply ignore closing tags of leaves.
+type OFXParser struct{}
+
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+func (p *OFXParser) Parse(r io.Reader) (*Batch, error) {
+	content, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the 1.x "KEY:VALUE" header or the 2.x XML prolog
+	body := string(content)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("not an OFX file: <OFX> element missing")
+	}
+	body = body[start:]
+
+	batch := &Batch{Format: "ofx"}
+	var (
+		account string            // Current ACCTID, FITIDs are only unique per account
+		entry   map[string]string // Fields of the STMTTRN being read, nil outside of one
+		count   int
+	)
+
+	for len(body) > 0 {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(body[open:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(body[open+1 : open+end]))
+		body = body[open+end+1:]
+
+		// Text up to the next tag is the element value (SGML style leaf)
+		next := strings.IndexByte(body, '<')
+		if next < 0 {
+			next = len(body)
+		}
+		value := strings.TrimSpace(ofxEntities.Replace(body[:next]))
+
+		switch {
+		case strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+			continue
+		case tag == "STMTTRN":
+			entry = map[string]string{}
+			count++
+		case tag == "/STMTTRN":
+			if entry != nil {
+				record, err := ofxRecord(account, entry)
+				if err != nil {
+					batch.addError(count, entry["FITID"], err)
+				} else {
+					batch.Records = append(batch.Records, record)
+				}
+			}
+			entry = nil
+		case tag == "ACCTID" && entry == nil:
+			account = value
+		case strings.HasPrefix(tag, "/"):
+			continue
+		case entry != nil && value != "":
+			entry[tag] = value
+		}
+	}
+
+	if count == 0 && len(batch.Errors) == 0 {
+		return nil, errors.New("no STMTTRN entries found in OFX file")
+	}
+	return batch, nil
+}
+
+func ofxRecord(account string, entry map[string]string) (*Record, error) {
+	fitID := entry["FITID"]
+	if fitID == "" {
+		return nil, errors.New("missing FITID")
+	}
+
+	date, err := parseOFXDate(entry["DTPOSTED"])
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := ParseAmount(entry["TRNAMT"])
+	if err != nil {
+		return nil, err
+	}
+
+	description := entry["NAME"]
+	if description == "" {
+		description = entry["PAYEE"]
+	}
+	if memo := entry["MEMO"]; memo != "" && !strings.EqualFold(memo, description) {
+		if description == "" {
+			description = memo
+		} else {
+			description += " - " + memo
+		}
+	}
+	if description == "" {
+		description = entry["TRNTYPE"]
+	}
+
+	externalID := "ofx:" + fitID
+	if account != "" {
+		externalID = "ofx:" + account + ":" + fitID
+	}
+
+	return &Record{
+		ExternalID:  externalID,
+		Date:        date,
+		Amount:      amount,
+		Description: truncate(description, 255),
+	}, nil
+}
+
+// OFX dates look like "20260301", "20260301120000" or "20260301120000.000[-5:EST]"
+var ofxDatePattern = regexp.MustCompile(`^(\d{8})(\d{6})?(?:\.\d+)?(?:\[([+-]?\d+(?:\.\d+)?)(?::\w+)?\])?`)
+
+func parseOFXDate(s string) (time.Time, error) {
+	m := ofxDatePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", s)
+	}
+
+	layout, value := "20060102", m[1]
+	if m[2] != "" {
+		layout, value = "20060102150405", m[1]+m[2]
+	}
+
+	loc := time.UTC
+	if m[3] != "" {
+		hours, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid DTPOSTED timezone %q", s)
+		}
+		loc = time.FixedZone("", int(hours*3600))
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", s)
+	}
+	return t.UTC(), nil
+}
+
+// truncate cuts s to at most n runes (DB column limits)
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>121000248<ACCTID>12345678<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20260301<DTEND>20260331
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260305120000.000[-5:EST]
+<TRNAMT>-42.50
+<FITID>2026030501
+<NAME>WHOLE FOODS &amp; CO
+<MEMO>POS PURCHASE
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260315
+<TRNAMT>2500.00
+<FITID>2026031501
+<NAME>ACME PAYROLL
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260316
+<TRNAMT>abc
+<FITID>2026031601
+<NAME>BROKEN
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CCACCTFROM><ACCTID>4111XXXX1111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>DEBIT</TRNTYPE>
+        <DTPOSTED>20260402</DTPOSTED>
+        <TRNAMT>-9.99</TRNAMT>
+        <FITID>A1</FITID>
+        <NAME>NETFLIX.COM</NAME>
+      </STMTTRN>
+    </BANKTRANLIST>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>`
+
+func TestOFXParser(t *testing.T) {
+	t.Run("SGML (OFX 1.x)", func(t *testing.T) {
+		batch, err := (&OFXParser{}).Parse(strings.NewReader(ofxSGML))
+		require.NoError(t, err)
+
+		require.Len(t, batch.Records, 2)
+		first := batch.Records[0]
+		assert.Equal(t, "ofx:12345678:2026030501", first.ExternalID)
+		assert.Equal(t, int64(-4250), first.Amount)
+		assert.Equal(t, "WHOLE FOODS & CO - POS PURCHASE", first.Description)
+		assert.Equal(t, time.Date(2026, time.March, 5, 17, 0, 0, 0, time.UTC), first.Date)
+
+		assert.Equal(t, int64(250000), batch.Records[1].Amount)
+
+		// The broken entry is reported, not fatal
+		require.Len(t, batch.Errors, 1)
+		assert.Equal(t, 3, batch.Errors[0].Entry)
+		assert.Equal(t, "2026031601", batch.Errors[0].Reference)
+	})
+
+	t.Run("XML (OFX 2.x)", func(t *testing.T) {
+		batch, err := (&OFXParser{}).Parse(strings.NewReader(ofxXML))
+		require.NoError(t, err)
+
+		require.Len(t, batch.Records, 1)
+		assert.Equal(t, "ofx:4111XXXX1111:A1", batch.Records[0].ExternalID)
+		assert.Equal(t, int64(-999), batch.Records[0].Amount)
+		assert.Equal(t, "NETFLIX.COM", batch.Records[0].Description)
+	})
+
+	t.Run("Not OFX", func(t *testing.T) {
+		_, err := (&OFXParser{}).Parse(strings.NewReader("date,amount\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]int64{
+		"42.50":     4250,
+		"-42.5":     -4250,
+		"+1,234.56": 123456,
+		"1.234,56":  123456,
+		"12,30":     1230,
+		"1,234":     123400,
+		"15.00-":    -1500,
+		"(7.25)":    -725,
+	}
+	for input, want := range cases {
+		got, err := ParseAmount(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseAmount("abc")
+	assert.Error(t, err)
+}
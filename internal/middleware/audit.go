@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// Client supplied request IDs are kept when they are reasonably short and printable
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID gives every request an ID, the client's X-Request-ID when it sent
+// a valid one, and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set("requestID", requestID)
+		c.Header(HeaderRequestID, requestID)
+		c.Next()
+	}
+}
+
+// AuditMeta hands the user, IP, user agent and request ID of the request to
+// the repositories, which record them in the audit log. It must run after
+// AuthMiddleware. The IP comes from X-Forwarded-For only when the engine
+// trusts the peer as a proxy, see gin.Engine.SetTrustedProxies.
+func AuditMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := models.AuditMeta{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: c.GetString("requestID"),
+		}
+		if userID, err := GetUserID(c); err == nil {
+			meta.ActorId = &userID
+		}
+		c.Request = c.Request.WithContext(repository.WithAuditMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("requestID")) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderRequestID, "req-42")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "req-42", w.Header().Get(HeaderRequestID))
+	assert.Equal(t, "req-42", w.Body.String())
+
+	// Unusable IDs are replaced
+	w = httptest.NewRecorder()
+	req.Header.Set(HeaderRequestID, "bad id\n")
+	r.ServeHTTP(w, req)
+	_, err := uuid.Parse(w.Header().Get(HeaderRequestID))
+	assert.NoError(t, err)
+}
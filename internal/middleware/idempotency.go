@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on responses replayed from a previous request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// IdempotencyKeyTTL is how long a response is replayed for its key
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyLockTTL is how long a request holds its key before storing a
+	// response. A retry after it runs again: the first request's process died.
+	IdempotencyLockTTL = 5 * time.Minute
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers stored with the body and sent
+// again on replays. Per request headers like X-Request-ID are left out.
+var replayedHeaders = []string{"Content-Type", "Content-Disposition", "ETag", "Last-Modified", "Location"}
+
+// responseRecorder keeps a copy of what the handler writes
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests with an Idempotency-Key header safe to retry:
+// the response of the first request is stored per user and key and replayed
+// for IdempotencyKeyTTL. A key reused with another request gets a 409, so does
+// a retry while the first request is still running, for up to
+// IdempotencyLockTTL. Server errors are not stored, the request can be
+// retried with the same key. It must run after AuthMiddleware.
+//
+// The body is read before the handler runs to fingerprint it, maxBodySize
+// must cover the largest body a route accepts (uploads and imports).
+func Idempotency(repo repository.IdempotencyRepository, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+		userID, err := GetUserID(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(c.Request, body)
+		ctx := c.Request.Context()
+		now := time.Now()
+		stored, err := repo.ReserveKey(ctx, userID, key, fingerprint, now.Add(IdempotencyLockTTL), now.Add(IdempotencyKeyTTL))
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key just failed, retry it"})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Idempotency-Key"})
+			return
+		case stored != nil && stored.Fingerprint != fingerprint:
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			return
+		case stored != nil && stored.StatusCode == nil:
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			return
+		case stored != nil:
+			for name, value := range stored.Headers {
+				c.Header(name, value)
+			}
+			c.Header(HeaderIdempotentReplayed, "true")
+			c.Data(*stored.StatusCode, stored.Headers["Content-Type"], stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// A client that gave up waiting retries with the same key, the
+		// response must be stored even though its request is canceled
+		ctx = context.WithoutCancel(ctx)
+		saved := false
+		defer func() {
+			// Server errors, a failed save and panics free the key for a retry
+			if saved {
+				return
+			}
+			if err := repo.ReleaseKey(ctx, userID, key); err != nil {
+				log.Printf("idempotency: release key %q: %v", key, err)
+			}
+		}()
+		c.Next()
+
+		if status := recorder.Status(); status < http.StatusInternalServerError {
+			headers := make(map[string]string)
+			for _, name := range replayedHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+			err := repo.SaveResponse(ctx, userID, key, status, headers, recorder.body.Bytes())
+			if err != nil {
+				log.Printf("idempotency: save response for key %q: %v", key, err)
+			}
+			saved = err == nil
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, path, query and body.
+// Multipart bodies are identified by their parts, so a retried upload matches
+// even though the client picked a new boundary.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	if digest, ok := multipartDigest(r.Header.Get("Content-Type"), body); ok {
+		h.Write(digest)
+	} else {
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// multipartDigest hashes the name, file name, content type and content of
+// each part. ok is false when the body is not a well-formed multipart body.
+func multipartDigest(contentType string, body []byte) (digest []byte, ok bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, false
+	}
+
+	h := sha256.New()
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return h.Sum(nil), true
+		}
+		if err != nil {
+			return nil, false
+		}
+		content := sha256.New()
+		if _, err := io.Copy(content, part); err != nil {
+			return nil, false
+		}
+		fmt.Fprintf(h, "%q %q %q %x\n", part.FormName(), part.FileName(), part.Header.Get("Content-Type"), content.Sum(nil))
+	}
+}
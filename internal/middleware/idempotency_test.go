@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// memIdempotencyRepo keeps the keys in memory, keyed by user and key
+type memIdempotencyRepo struct {
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyKey
+}
+
+func (r *memIdempotencyRepo) ReserveKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, lockedUntil, expiresAt time.Time) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.keys == nil {
+		r.keys = make(map[string]*models.IdempotencyKey)
+	}
+	now := time.Now()
+	if k, ok := r.keys[userID.String()+key]; ok && k.ExpiresAt.After(now) && (k.StatusCode != nil || k.LockedUntil.After(now)) {
+		stored := *k
+		return &stored, nil
+	}
+	r.keys[userID.String()+key] = &models.IdempotencyKey{
+		UserId: userID, Key: key, Fingerprint: fingerprint, LockedUntil: &lockedUntil, ExpiresAt: expiresAt,
+	}
+	return nil, nil
+}
+
+func (r *memIdempotencyRepo) SaveResponse(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := r.keys[userID.String()+key]
+	k.StatusCode, k.Headers, k.Body, k.LockedUntil = &statusCode, headers, body, nil
+	return nil
+}
+
+func (r *memIdempotencyRepo) ReleaseKey(ctx context.Context, userID uuid.UUID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, userID.String()+key)
+	return nil
+}
+
+func (r *memIdempotencyRepo) DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil // Not used by the middleware
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	repo := &memIdempotencyRepo{}
+
+	calls := 0
+	status := http.StatusCreated
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	r.Use(Idempotency(repo, 1<<20))
+	r.POST("/transactions", func(c *gin.Context) {
+		calls++
+		var body struct {
+			Amount int64 `json:"amount"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Location", "/transactions/42")
+		c.Header("ETag", `"1"`)
+		c.JSON(status, gin.H{"amount": body.Amount, "call": calls})
+	})
+	r.GET("/transactions", func(c *gin.Context) {
+		calls++
+		c.Status(http.StatusOK)
+	})
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Replays The First Response", func(t *testing.T) {
+		calls = 0
+		first := post("key-1", `{"amount": 1250}`)
+		assert.Equal(t, http.StatusCreated, first.Code)
+
+		retry := post("key-1", `{"amount": 1250}`)
+		assert.Equal(t, 1, calls, "the handler runs once")
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+		assert.Equal(t, "/transactions/42", retry.Header().Get("Location"))
+		assert.Equal(t, `"1"`, retry.Header().Get("ETag"))
+		assert.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+		assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+	})
+
+	t.Run("Key Reused With Another Payload", func(t *testing.T) {
+		calls = 0
+		post("key-2", `{"amount": 1250}`)
+		w := post("key-2", `{"amount": 9999}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Still Processing", func(t *testing.T) {
+		// The first request with this key and payload has not finished
+		fingerprint := requestFingerprint(httptest.NewRequest(http.MethodPost, "/transactions", nil), []byte(`{"amount": 1250}`))
+		_, err := repo.ReserveKey(context.Background(), userID, "key-3", fingerprint, time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		w := post("key-3", `{"amount": 1250}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "still being processed")
+	})
+
+	t.Run("Abandoned Request", func(t *testing.T) {
+		// The process handling the first request died, its lock ran out
+		calls = 0
+		fingerprint := requestFingerprint(httptest.NewRequest(http.MethodPost, "/transactions", nil), []byte(`{"amount": 1250}`))
+		_, err := repo.ReserveKey(context.Background(), userID, "key-7", fingerprint, time.Now().Add(-time.Second), time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, post("key-7", `{"amount": 1250}`).Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Client Errors Are Replayed", func(t *testing.T) {
+		calls = 0
+		assert.Equal(t, http.StatusBadRequest, post("key-4", `{"amount": "x"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, post("key-4", `{"amount": "x"}`).Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Server Errors Free The Key", func(t *testing.T) {
+		calls = 0
+		status = http.StatusInternalServerError
+		assert.Equal(t, http.StatusInternalServerError, post("key-5", `{"amount": 1250}`).Code)
+		status = http.StatusCreated
+		assert.Equal(t, http.StatusCreated, post("key-5", `{"amount": 1250}`).Code)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Without Key Or On GET", func(t *testing.T) {
+		calls = 0
+		post("", `{"amount": 1250}`)
+		post("", `{"amount": 1250}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/transactions", nil)
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Keys Are Per User", func(t *testing.T) {
+		_, err := repo.ReserveKey(context.Background(), uuid.New(), "key-6", "fingerprint", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, post("key-6", `{"amount": 1250}`).Code)
+	})
+}
+
+func TestIdempotency_Multipart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	calls := 0
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	r.Use(Idempotency(&memIdempotencyRepo{}, 1<<10))
+	r.POST("/attachments", func(c *gin.Context) {
+		calls++
+		if _, err := c.FormFile("file"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	upload := func(key, boundary, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		assert.NoError(t, mw.SetBoundary(boundary))
+		part, err := mw.CreateFormFile("file", "receipt.txt")
+		assert.NoError(t, err)
+		part.Write([]byte(content))
+		assert.NoError(t, mw.Close())
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/attachments", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set(HeaderIdempotencyKey, key)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// A retry carries a new boundary, it is still the same upload
+	assert.Equal(t, http.StatusCreated, upload("key-1", "first-boundary", "coffee 4.20").Code)
+	retry := upload("key-1", "second-boundary", "coffee 4.20")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, 1, calls)
+
+	// Another file is another request
+	assert.Equal(t, http.StatusConflict, upload("key-1", "third-boundary", "lunch 12.50").Code)
+
+	// Over the cap the body is refused before the handler runs
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload("key-2", "first-boundary", strings.Repeat("x", 2<<10)).Code)
+	assert.Equal(t, 1, calls)
+}
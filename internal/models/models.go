@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,11 +15,13 @@ type User struct {
 }
 
 type Category struct {
-	ID        uuid.UUID `json:"id"`
-	UserId    uuid.UUID `json:"user_id"`
-	Name      string    `json:"name"`
-	Type      string    `json:"type"` // "income" or "expense"
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID  `json:"id"`
+	UserId    uuid.UUID  `json:"user_id"`
+	Name      string     `json:"name"`
+	Type      string     `json:"type"` // "income" or "expense"
+	CreatedAt time.Time  `json:"created_at"`
+	Version   int        `json:"version"`              // Bumped by every change, see ETag
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Set while in the trash
 }
 
 type Transaction struct {
@@ -31,6 +34,179 @@ type Transaction struct {
 	Description  string     `json:"description"`
 	Date         time.Time  `json:"date"`
 	CreatedAt    time.Time  `json:"created_at"`
+	Tags         []*Tag     `json:"tags"`
+	ExternalID   *string    `json:"external_id,omitempty"` // ID in the source system (bank import, sync)
+	Version      int        `json:"version"`               // Bumped by every change, see ETag
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`  // Set while in the trash
+}
+
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	UserId    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Attachment is a receipt (photo or PDF) linked to a transaction.
+// The content itself lives in blob storage under StorageKey.
+type Attachment struct {
+	ID            uuid.UUID `json:"id"`
+	UserId        uuid.UUID `json:"user_id"`
+	TransactionId uuid.UUID `json:"transaction_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	SizeBytes     int64     `json:"size_bytes"`
+	StorageKey    string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Rule automatically categorizes transactions. Every non-empty condition
+// must match for the actions to be applied.
+type Rule struct {
+	ID       uuid.UUID `json:"id"`
+	UserId   uuid.UUID `json:"user_id"`
+	Name     string    `json:"name"`
+	Priority int       `json:"priority"` // Higher runs first
+	Enabled  bool      `json:"enabled"`
+
+	// Conditions
+	DescriptionPattern string `json:"description_pattern"`
+	MatchType          string `json:"match_type"` // "contains" or "regex"
+	MinAmount          *int64 `json:"min_amount"` // Cents, inclusive
+	MaxAmount          *int64 `json:"max_amount"` // Cents, inclusive
+	Weekdays           []int  `json:"weekdays"`   // 0 = Sunday ... 6 = Saturday
+
+	// Actions
+	SetCategoryId  *uuid.UUID  `json:"set_category_id"`
+	SetDescription *string     `json:"set_description"`
+	AddTagIds      []uuid.UUID `json:"add_tag_ids"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FieldChange describes a single field modified by rules
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// RuleChange is the diff produced by running rules against one transaction
+type RuleChange struct {
+	TransactionID uuid.UUID     `json:"transaction_id"`
+	Description   string        `json:"description"`
+	MatchedRules  []uuid.UUID   `json:"matched_rules"`
+	Changes       []FieldChange `json:"changes"`
+}
+
+// CategorySuggestion is a predicted category for a description
+type CategorySuggestion struct {
+	CategoryID   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Confidence   float64   `json:"confidence"` // 0..1
+}
+
+const (
+	DuplicateStatusPending   = "pending"
+	DuplicateStatusDismissed = "dismissed"
+)
+
+// DuplicateCandidate links a transaction to an older one it probably duplicates
+type DuplicateCandidate struct {
+	ID            uuid.UUID    `json:"id"`
+	UserId        uuid.UUID    `json:"user_id"`
+	TransactionId uuid.UUID    `json:"transaction_id"`  // The newer, suspected duplicate
+	DuplicateOfId uuid.UUID    `json:"duplicate_of_id"` // The row it matches
+	Score         float64      `json:"score"`           // 0..1, higher is more likely
+	Status        string       `json:"status"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Transaction   *Transaction `json:"transaction,omitempty"`
+	DuplicateOf   *Transaction `json:"duplicate_of,omitempty"`
+}
+
+// ImportError describes a statement entry that could not be imported
+type ImportError struct {
+	Entry     int    `json:"entry"`               // 1-based position in the file
+	Reference string `json:"reference,omitempty"` // Bank reference when known
+	Message   string `json:"message"`
+}
+
+// ImportReport summarizes the outcome of a statement import
+type ImportReport struct {
+	Format            string         `json:"format"`
+	Total             int            `json:"total"`              // Entries found in the file
+	Created           int            `json:"created"`            // New transactions
+	Updated           int            `json:"updated"`            // Existing transactions the source corrected (sync only)
+	Skipped           int            `json:"skipped"`            // Already imported (same external ID) or not importable (transfers)
+	Duplicates        int            `json:"duplicates"`         // Created but flagged as likely duplicates
+	Failed            int            `json:"failed"`             // Unreadable or rejected entries, see Errors
+	CategoriesCreated []string       `json:"categories_created"` // Names of the categories the import added
+	Errors            []*ImportError `json:"errors"`
+}
+
+// BankAccount is an account linked through a bank sync provider
+type BankAccount struct {
+	ID               uuid.UUID  `json:"id"`
+	UserId           uuid.UUID  `json:"user_id"`
+	Provider         string     `json:"provider"`
+	ExternalID       string     `json:"external_id"` // Account ID at the provider
+	Name             string     `json:"name"`
+	Mask             string     `json:"mask"`
+	Currency         string     `json:"currency"`
+	Balance          int64      `json:"balance"` // Cents
+	BalanceUpdatedAt *time.Time `json:"balance_updated_at"`
+	Cursor           string     `json:"-"` // Provider sync position
+	LastSyncedAt     *time.Time `json:"last_synced_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Goal is a savings target. Contributions are the transactions of CategoryId
+// since StartDate, or how much the balance of BankAccountId grew since StartDate.
+type Goal struct {
+	ID            uuid.UUID     `json:"id"`
+	UserId        uuid.UUID     `json:"user_id"`
+	Name          string        `json:"name"`
+	TargetAmount  int64         `json:"target_amount"` // Cents
+	StartDate     time.Time     `json:"start_date"`
+	TargetDate    *time.Time    `json:"target_date"`
+	CategoryId    *uuid.UUID    `json:"category_id"`
+	BankAccountId *uuid.UUID    `json:"bank_account_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Progress      *GoalProgress `json:"progress,omitempty"`
+}
+
+// GoalProgress is computed on read, it is never stored
+type GoalProgress struct {
+	Saved     int64   `json:"saved"`     // Cents
+	Remaining int64   `json:"remaining"` // Cents, 0 once reached
+	Percent   float64 `json:"percent"`   // 0..100
+	Completed bool    `json:"completed"`
+	// Cents saved per month since the start
+	AverageMonthly int64 `json:"average_monthly"`
+
+	// Only set for goals with a target date
+	MonthsLeft      *int   `json:"months_left,omitempty"`
+	RequiredMonthly *int64 `json:"required_monthly,omitempty"` // Cents to save per month from now on
+	ExpectedByNow   *int64 `json:"expected_by_now,omitempty"`  // Cents saved by now at a steady pace
+	OnTrack         *bool  `json:"on_track,omitempty"`
+}
+
+// TransactionFilter narrows down the ListTransactions result.
+// Zero value means "no filtering".
+type TransactionFilter struct {
+	TagIDs []uuid.UUID // Transaction must carry every listed tag, no repeats
+	From   time.Time   // Inclusive, zero means unbounded
+	To     time.Time   // Exclusive, zero means unbounded
+	Amount *int64      // Exact amount in cents
+}
+
+// TagTotal holds the aggregated amounts for a single tag
+type TagTotal struct {
+	TagID   uuid.UUID `json:"tag_id"`
+	TagName string    `json:"tag_name"`
+	Income  int64     `json:"income"`
+	Expense int64     `json:"expense"`
+	Count   int64     `json:"count"`
 }
 
 type PeriodicStat struct {
@@ -39,8 +215,302 @@ type PeriodicStat struct {
 	Income  int64  `json:"income"`
 }
 
+// CategoryStat is the total of one category in a month
+type CategoryStat struct {
+	Period       string    `json:"period"` // YYYY-MM-DD, first day of the month
+	CategoryId   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Type         string    `json:"type"`
+	Amount       int64     `json:"amount"` // Cents
+}
+
 type DashboardSummary struct {
 	TotalIncome  int64 `json:"total_income"`
 	TotalExpense int64 `json:"total_expense"`
 	NetBalance   int64 `json:"net_balance"`
 }
+
+// MonthlyAmount is a per category and month total, Month is the first day of the month
+type MonthlyAmount struct {
+	CategoryId uuid.UUID `json:"category_id"`
+	Month      time.Time `json:"month"`
+	Amount     int64     `json:"amount"` // Cents
+}
+
+// Envelope is the state of one expense category in a budget month.
+// Available = Carryover + Assigned - Activity, and it becomes the next month's carryover.
+type Envelope struct {
+	CategoryId   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Carryover    int64     `json:"carryover"` // Cents left (or overspent, negative) at the end of last month
+	Assigned     int64     `json:"assigned"`  // Cents assigned this month
+	Activity     int64     `json:"activity"`  // Cents spent this month
+	Available    int64     `json:"available"` // Cents, negative when overspent
+}
+
+// BudgetMonth is the envelope budget of one month, computed from transaction history
+type BudgetMonth struct {
+	Month         string      `json:"month"`           // YYYY-MM
+	Income        int64       `json:"income"`          // Cents received this month
+	Assigned      int64       `json:"assigned"`        // Cents assigned to envelopes this month
+	ReadyToAssign int64       `json:"ready_to_assign"` // Income so far minus everything assigned so far
+	Envelopes     []*Envelope `json:"envelopes"`
+}
+
+// RecurringItem is a transaction that repeats at a regular interval, detected from history
+type RecurringItem struct {
+	Description  string    `json:"description"`
+	CategoryId   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Type         string    `json:"type"`
+	Amount       int64     `json:"amount"`      // Cents, median of the occurrences at the current price
+	Interval     string    `json:"interval"`    // "weekly", "biweekly", "monthly", "quarterly" or "yearly"
+	Occurrences  int       `json:"occurrences"` // Times seen in history
+	LastDate     time.Time `json:"last_date"`
+	NextDate     time.Time `json:"next_date"`
+}
+
+// VariableSpend is the average daily spending of a category outside recurring items
+type VariableSpend struct {
+	CategoryId   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	DailyAverage int64     `json:"daily_average"` // Cents
+}
+
+// ForecastDay is the projected end-of-day balance. Low and High bound the
+// range the balance should stay in, given how much variable spending varies.
+type ForecastDay struct {
+	Date      string `json:"date"`      // YYYY-MM-DD
+	Recurring int64  `json:"recurring"` // Cents, net of the recurring items expected that day
+	Variable  int64  `json:"variable"`  // Cents of average variable spending
+	Balance   int64  `json:"balance"`   // Cents, expected
+	Low       int64  `json:"low"`
+	High      int64  `json:"high"`
+}
+
+// ThresholdCrossing is a day the expected balance moves below or back above the threshold
+type ThresholdCrossing struct {
+	Date      string `json:"date"`      // YYYY-MM-DD
+	Direction string `json:"direction"` // "below" or "above"
+	Balance   int64  `json:"balance"`   // Cents
+}
+
+// Forecast projects the balance day by day
+type Forecast struct {
+	StartBalance  int64                `json:"start_balance"` // Cents, today
+	Threshold     int64                `json:"threshold"`     // Cents
+	Confidence    float64              `json:"confidence"`    // Probability covered by the Low..High bands
+	Days          []*ForecastDay       `json:"days"`
+	Crossings     []*ThresholdCrossing `json:"crossings"`
+	Recurring     []*RecurringItem     `json:"recurring"`
+	VariableSpend []*VariableSpend     `json:"variable_spend"`
+}
+
+// PriceChange is a recurring charge billed at a different amount than the time before
+type PriceChange struct {
+	Date time.Time `json:"date"`
+	From int64     `json:"from"` // Cents
+	To   int64     `json:"to"`   // Cents
+}
+
+// Subscription is a recurring expense detected from history
+type Subscription struct {
+	RecurringItem
+	CurrentAmount int64          `json:"current_amount"` // Cents, the latest charge
+	AnnualCost    int64          `json:"annual_cost"`    // Cents per year at the current amount
+	PriceChanges  []*PriceChange `json:"price_changes"`  // Oldest first
+}
+
+const (
+	AnomalyKindTransaction = "transaction"
+	AnomalyKindMonth       = "month"
+)
+
+// Anomaly is a transaction or a month of a category whose spending is far
+// above the category's baseline: ZScore standard deviations above the mean.
+type Anomaly struct {
+	Kind          string     `json:"kind"` // "transaction" or "month"
+	Date          time.Time  `json:"date"` // Transaction date, or first day of the month
+	CategoryId    uuid.UUID  `json:"category_id"`
+	CategoryName  string     `json:"category_name"`
+	TransactionId *uuid.UUID `json:"transaction_id,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	Amount        int64      `json:"amount"`   // Cents
+	Baseline      int64      `json:"baseline"` // Cents, mean of the baseline
+	StdDev        int64      `json:"std_dev"`  // Cents
+	ZScore        float64    `json:"z_score"`
+}
+
+const (
+	NotificationKindBudgetOverrun = "budget_overrun"
+	NotificationKindBillReminder  = "bill_reminder"
+	NotificationKindAnomaly       = "anomaly"
+)
+
+// Notification is an entry of the in-app inbox
+type Notification struct {
+	ID        uuid.UUID      `json:"id"`
+	UserId    uuid.UUID      `json:"user_id"`
+	Kind      string         `json:"kind"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	Data      map[string]any `json:"data"` // Kind specific details, e.g. the transaction ID
+	ReadAt    *time.Time     `json:"read_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Key       string         `json:"-"` // Optional, a user gets one notification per key at most
+}
+
+// NotificationPreference enables a delivery channel besides the inbox
+type NotificationPreference struct {
+	Channel string   `json:"channel"` // "email", "webhook" or "log"
+	Enabled bool     `json:"enabled"`
+	Target  string   `json:"target"` // Email address or URL, unused by "log"
+	Kinds   []string `json:"kinds"`  // Kinds to deliver, empty means all
+}
+
+// Event types sent to webhook endpoints and real-time clients
+const (
+	EventTransactionCreated  = "transaction.created"
+	EventTransactionUpdated  = "transaction.updated"
+	EventTransactionDeleted  = "transaction.deleted"
+	EventTransactionRestored = "transaction.restored"
+	EventCategoryCreated     = "category.created"
+	EventCategoryDeleted     = "category.deleted"
+	EventCategoryRestored    = "category.restored"
+	EventWebhookTest         = "webhook.test"
+	// Sent over the real-time stream only, after every transaction change
+	EventDashboardSummary = "dashboard.summary"
+)
+
+// Event is a change in the user's data, serialized as the webhook payload
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookEndpoint receives the events it subscribed to
+type WebhookEndpoint struct {
+	ID        uuid.UUID `json:"id"`
+	UserId    uuid.UUID `json:"user_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // Only returned when the endpoint is created
+	Events    []string  `json:"events"`           // Empty means every event
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookDelivery is one event queued for one endpoint, with the outcome of the last attempt
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointId     uuid.UUID       `json:"endpoint_id"`
+	UserId         uuid.UUID       `json:"user_id"`
+	EventId        uuid.UUID       `json:"event_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusDead      = "dead" // Out of attempts or failed permanently
+)
+
+// Job is a unit of background work, run by the handler registered for its type
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	UserId      *uuid.UUID      `json:"user_id"` // Nil for system jobs
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"` // Next attempt while queued
+	LastError   *string         `json:"last_error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+}
+
+// OutboxEvent is an event stored with the change that raised it, waiting to be published
+type OutboxEvent struct {
+	Seq      int64
+	UserId   uuid.UUID
+	Event    *Event // Data holds the JSON payload as json.RawMessage
+	Attempts int
+}
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"  // Moved to the trash
+	AuditActionRestore = "restore" // Taken out of the trash
+
+	AuditEntityTransaction = "transaction"
+	AuditEntityCategory    = "category"
+	AuditEntityBudget      = "budget"
+)
+
+// AuditEntry records one change of a user's data, with the state before and after it
+type AuditEntry struct {
+	ID         uuid.UUID       `json:"id"`
+	UserId     uuid.UUID       `json:"user_id"`
+	ActorId    *uuid.UUID      `json:"actor_id"` // Nil for background work (bank sync, jobs)
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityId   uuid.UUID       `json:"entity_id"`
+	Before     json.RawMessage `json:"before"` // Null on create
+	After      json.RawMessage `json:"after"`  // Null on delete
+	IP         *string         `json:"ip"`
+	UserAgent  *string         `json:"user_agent"`
+	RequestID  *string         `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditMeta describes where a change comes from, see repository.WithAuditMeta
+type AuditMeta struct {
+	ActorId   *uuid.UUID
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// AuditFilter narrows the audit log, zero values match everything
+type AuditFilter struct {
+	Action     string
+	EntityType string
+	EntityId   *uuid.UUID
+	ActorId    *uuid.UUID
+	From       time.Time  // Inclusive
+	To         time.Time  // Exclusive
+	Before     *uuid.UUID // Cursor: only entries older than this one
+	Limit      int
+}
+
+// IdempotencyKey is a POST request identified by its Idempotency-Key header and
+// the response it got
+type IdempotencyKey struct {
+	UserId      uuid.UUID
+	Key         string
+	Fingerprint string            // Hash of the request, a reused key must come with the same request
+	StatusCode  *int              // Nil while the request is being processed
+	Headers     map[string]string // Response headers replayed with the body
+	Body        []byte
+	CreatedAt   time.Time
+	LockedUntil *time.Time // While processing, a retry after it takes the key over
+	ExpiresAt   time.Time
+}
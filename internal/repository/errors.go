@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrNotFound is returned when the requested row does not exist
+// or does not belong to the requesting user.
+var ErrNotFound = errors.New("not found")
+
+// ErrInUse is returned when a row cannot be deleted while others refer to it
+var ErrInUse = errors.New("in use")
+
+// ErrVersionMismatch is returned when a row changed since the version the
+// caller based its write on
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// checkVersion enforces an If-Match precondition: the row's version must be
+// one of versions. Nil versions stand for "If-Match: *" and match any version.
+func checkVersion(versions []int, version int) error {
+	if versions != nil && !slices.Contains(versions, version) {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// ErrLeaseLost is returned when a job's lease expired and another worker
+// claimed it before the result was saved
+var ErrLeaseLost = errors.New("lease lost")
+
+// ErrAlreadyQueued is returned when a replayed event, or a notification with
+// a key already used, was already queued for delivery
+var ErrAlreadyQueued = errors.New("already queued")
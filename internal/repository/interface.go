@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/olmits/budget-tracker-backend/internal/models"
@@ -10,17 +11,245 @@ import (
 // TransactionRepository defines "what" we need from the DB, not "how"
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, t *models.Transaction) error
-	ListTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error)
+	GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error)
+	GetTransactionByExternalID(ctx context.Context, userID uuid.UUID, externalID string) (*models.Transaction, error)
+	ListTransactions(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error)
+	// UpdateTransaction overwrites the editable fields, including the full set of tags
+	UpdateTransaction(ctx context.Context, t *models.Transaction) error
+	// DeleteTransaction moves a transaction to the trash, failing with
+	// ErrVersionMismatch unless it is at one of versions (nil for any)
+	DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID, versions []int) error
 	GetSummaryByType(ctx context.Context, userID uuid.UUID) (map[string]int64, error)
 	GetPeriodicStats(ctx context.Context, userID uuid.UUID) ([]*models.PeriodicStat, error)
+	// GetCategoryStats totals the transactions per category and month, from "from" (inclusive)
+	GetCategoryStats(ctx context.Context, userID uuid.UUID, from time.Time) ([]*models.CategoryStat, error)
 }
 
 type CategoryRepository interface {
 	CreateCategory(ctx context.Context, c *models.Category) error
+	GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error)
 	ListCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error)
+	// DeleteCategory moves a category to the trash. Its transactions, goals and
+	// the rules setting it move to reassignTo first. Without one, a category
+	// still used by transactions or goals fails with ErrInUse and its rules are
+	// disabled. It fails with ErrVersionMismatch unless the category is at one
+	// of versions (nil for any).
+	DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, versions []int, reassignTo *uuid.UUID) error
 }
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// ListUserIDs returns the IDs of every user, for the jobs that scan them all
+	ListUserIDs(ctx context.Context) ([]uuid.UUID, error)
+}
+
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	ListTags(ctx context.Context, userID uuid.UUID) ([]*models.Tag, error)
+	GetTag(ctx context.Context, userID, tagID uuid.UUID) (*models.Tag, error)
+	UpdateTag(ctx context.Context, tag *models.Tag) error
+	DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error
+	// SetTransactionTags replaces the full set of tags attached to a transaction,
+	// failing with ErrVersionMismatch unless it is at one of versions (nil for any).
+	// It returns the transaction's new version.
+	SetTransactionTags(ctx context.Context, userID, transactionID uuid.UUID, versions []int, tagIDs []uuid.UUID) (int, error)
+	// GetTotalsByTag aggregates amounts per tag for transactions dated within [from, to)
+	GetTotalsByTag(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.TagTotal, error)
+}
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, a *models.Attachment) error
+	ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]*models.Attachment, error)
+	GetAttachment(ctx context.Context, userID, attachmentID uuid.UUID) (*models.Attachment, error)
+	DeleteAttachment(ctx context.Context, userID, attachmentID uuid.UUID) error
+}
+
+type RuleRepository interface {
+	CreateRule(ctx context.Context, rule *models.Rule) error
+	// ListRules returns the user's rules ordered by priority (highest first)
+	ListRules(ctx context.Context, userID uuid.UUID) ([]*models.Rule, error)
+	GetRule(ctx context.Context, userID, ruleID uuid.UUID) (*models.Rule, error)
+	UpdateRule(ctx context.Context, rule *models.Rule) error
+	DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error
+}
+
+type DuplicateRepository interface {
+	// SaveCandidate inserts the pair or refreshes its score, keeping any previous decision
+	SaveCandidate(ctx context.Context, c *models.DuplicateCandidate) error
+	// ListCandidates returns the pairs with the given status, both transactions included
+	ListCandidates(ctx context.Context, userID uuid.UUID, status string) ([]*models.DuplicateCandidate, error)
+	DismissCandidate(ctx context.Context, userID, candidateID uuid.UUID) error
+	// MergeCandidate moves tags and attachments of the duplicate onto the original
+	// and deletes the duplicate. It returns the ID of the kept transaction.
+	MergeCandidate(ctx context.Context, userID, candidateID uuid.UUID) (uuid.UUID, error)
+}
+
+type BankAccountRepository interface {
+	// UpsertAccount creates the account or, when already linked, refreshes its name, mask and balance
+	UpsertAccount(ctx context.Context, a *models.BankAccount) error
+	ListAccounts(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error)
+	GetAccount(ctx context.Context, userID, accountID uuid.UUID) (*models.BankAccount, error)
+	UpdateCursor(ctx context.Context, userID, accountID uuid.UUID, cursor string, syncedAt time.Time) error
+	UpdateBalance(ctx context.Context, userID, accountID uuid.UUID, balance int64, asOf time.Time) error
+	// BalanceAt returns the balance recorded at or before at, or the first one
+	// when the account was linked later. ErrNotFound when none was recorded.
+	BalanceAt(ctx context.Context, userID, accountID uuid.UUID, at time.Time) (int64, error)
+	DeleteAccount(ctx context.Context, userID, accountID uuid.UUID) error
+}
+
+type GoalRepository interface {
+	CreateGoal(ctx context.Context, g *models.Goal) error
+	ListGoals(ctx context.Context, userID uuid.UUID) ([]*models.Goal, error)
+	GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*models.Goal, error)
+	UpdateGoal(ctx context.Context, g *models.Goal) error
+	DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error
+	// SumCategory totals the transactions of a category dated from "from" (inclusive)
+	SumCategory(ctx context.Context, userID, categoryID uuid.UUID, from time.Time) (int64, error)
+}
+
+type BudgetRepository interface {
+	// ListAssigned returns the amounts assigned per envelope and month, for months before "to"
+	ListAssigned(ctx context.Context, userID uuid.UUID, to time.Time) ([]*models.MonthlyAmount, error)
+	// ListActivity totals the transactions per category and month, dated before "to"
+	ListActivity(ctx context.Context, userID uuid.UUID, to time.Time) ([]*models.MonthlyAmount, error)
+	SetAssigned(ctx context.Context, userID, categoryID uuid.UUID, month time.Time, amount int64) error
+	// LockBudget holds the user's budget until the end of the transaction it
+	// runs in, serializing it with the other budget writes. Run it in a unit
+	// of work: on its own the lock is released right away.
+	LockBudget(ctx context.Context, userID uuid.UUID) error
+	// MoveAssigned shifts amount from one envelope to another within a month, atomically.
+	// A nil side stands for the "ready to assign" pool and is left untouched.
+	MoveAssigned(ctx context.Context, userID uuid.UUID, month time.Time, from, to *uuid.UUID, amount int64) error
+}
+
+type NotificationRepository interface {
+	// CreateNotification fails with ErrAlreadyQueued when the user already
+	// got a notification with the same key
+	CreateNotification(ctx context.Context, n *models.Notification) error
+	// ListNotifications returns the newest notifications first
+	ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, userID, notificationID uuid.UUID) error
+	// MarkAllRead returns how many notifications were unread
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	ListPreferences(ctx context.Context, userID uuid.UUID) ([]*models.NotificationPreference, error)
+	// SavePreferences replaces all the channel preferences of the user
+	SavePreferences(ctx context.Context, userID uuid.UUID, prefs []*models.NotificationPreference) error
+}
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error
+	ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*models.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*models.WebhookEndpoint, error)
+	// UpdateEndpoint changes the URL, events and enabled flag, the secret stays
+	UpdateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error
+	// ListSubscribers returns the enabled endpoints of the user that receive the event
+	ListSubscribers(ctx context.Context, userID uuid.UUID, event string) ([]*models.WebhookEndpoint, error)
+
+	// EnqueueDelivery returns ErrAlreadyQueued when the endpoint already has a delivery of the event
+	EnqueueDelivery(ctx context.Context, d *models.WebhookDelivery) error
+	// ClaimDueDeliveries picks up to limit pending deliveries due at now and moves
+	// their next attempt to now+lease, so concurrent workers do not send them too
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error)
+	// SaveAttempt stores the status, attempts, next attempt and last outcome of a delivery
+	SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error
+	// ListDeliveries returns the delivery log of an endpoint, newest first
+	ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+}
+
+// PubSubRepository broadcasts messages to every API instance
+type PubSubRepository interface {
+	Notify(ctx context.Context, channel, payload string) error
+	// Listen calls handle with each message published on the channel until
+	// ctx is done or the connection fails
+	Listen(ctx context.Context, channel string, handle func(payload string)) error
+}
+
+type JobRepository interface {
+	EnqueueJob(ctx context.Context, j *models.Job) error
+	// ClaimJobs marks up to limit due jobs of the given types as running, counts
+	// the attempt and leases them until now+lease. Running jobs whose lease
+	// expired (their worker died) are claimed again.
+	ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, types []string, limit int) ([]*models.Job, error)
+	// SaveJobResult stores the status, next run, last error and finish time and
+	// ends the lease. It fails with ErrLeaseLost when the job is no longer
+	// running the attempt j was claimed for.
+	SaveJobResult(ctx context.Context, j *models.Job) error
+	GetJob(ctx context.Context, userID, jobID uuid.UUID) (*models.Job, error)
+	// ListJobs returns the user's jobs, newest first, all statuses when status is empty
+	ListJobs(ctx context.Context, userID uuid.UUID, status string, limit int) ([]*models.Job, error)
+	// RetryJob queues a dead job again with a fresh set of attempts
+	RetryJob(ctx context.Context, userID, jobID uuid.UUID) (*models.Job, error)
+}
+
+// OutboxRepository reads the events recorded by repository writes
+type OutboxRepository interface {
+	// ClaimEvents leases up to limit unpublished events until now+lease, oldest first
+	ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error)
+	// DeleteEvent removes a published event
+	DeleteEvent(ctx context.Context, seq int64) error
+	// ReleaseEvent records a failed publish and hides the event until retryAt
+	ReleaseEvent(ctx context.Context, seq int64, retryAt time.Time, lastError string) error
+}
+
+// TrashRepository lists, restores and purges deleted transactions and categories
+type TrashRepository interface {
+	ListDeletedTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error)
+	ListDeletedCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error)
+	// RestoreTransaction takes a transaction out of the trash, with its category when that was deleted too
+	RestoreTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error)
+	RestoreCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error)
+	// PurgeDeleted permanently removes what was deleted before cutoff. It returns
+	// how many rows it removed and the blob keys of the receipts removed with them.
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, []string, error)
+}
+
+type AuditRepository interface {
+	// ListAuditEntries returns the entries matching the filter, newest first
+	ListAuditEntries(ctx context.Context, userID uuid.UUID, filter models.AuditFilter) ([]*models.AuditEntry, error)
+}
+
+// IdempotencyRepository stores the responses of requests sent with an Idempotency-Key
+type IdempotencyRepository interface {
+	// ReserveKey claims the key for a new request, locked until lockedUntil and
+	// kept until expiresAt, and returns nil. When the key is taken, neither
+	// expired nor left unfinished past its lock, it returns the stored key instead.
+	ReserveKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, lockedUntil, expiresAt time.Time) (*models.IdempotencyKey, error)
+	// SaveResponse stores the response of a reserved key, replayed on retries
+	SaveResponse(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error
+	// ReleaseKey forgets a reserved key so the request can be retried
+	ReleaseKey(ctx context.Context, userID uuid.UUID, key string) error
+	// DeleteExpiredKeys removes the keys that expired before now and returns how many
+	DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// UnitOfWork runs several repository calls atomically
+type UnitOfWork interface {
+	// Do calls fn with repositories bound to one database transaction, committed
+	// when fn returns nil and rolled back when it returns an error
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+// Repositories are the repositories of one unit of work. Tests fill in only
+// the ones the code under test uses.
+type Repositories struct {
+	Transactions  TransactionRepository
+	Categories    CategoryRepository
+	Users         UserRepository
+	Tags          TagRepository
+	Attachments   AttachmentRepository
+	Rules         RuleRepository
+	Duplicates    DuplicateRepository
+	BankAccounts  BankAccountRepository
+	Goals         GoalRepository
+	Budgets       BudgetRepository
+	Notifications NotificationRepository
+	Webhooks      WebhookRepository
+	Jobs          JobRepository
+	Outbox        OutboxRepository
+	Trash         TrashRepository
+	Audit         AuditRepository
+	Idempotency   IdempotencyRepository
 }
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresAttachmentRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresAttachmentRepo) CreateAttachment(ctx context.Context, a *models.Attachment) error {
+	sql := `INSERT INTO attachments (user_id, transaction_id, file_name, content_type, size_bytes, storage_key)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at`
+	return r.DB.QueryRow(ctx, sql,
+		a.UserId, a.TransactionId, a.FileName, a.ContentType, a.SizeBytes, a.StorageKey,
+	).Scan(&a.ID, &a.CreatedAt)
+}
+
+func (r *PostgresAttachmentRepo) ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]*models.Attachment, error) {
+	sql := `SELECT id, file_name, content_type, size_bytes, storage_key, created_at
+			FROM attachments
+			WHERE user_id = $1 AND transaction_id = $2
+			ORDER BY created_at ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*models.Attachment
+
+	for rows.Next() {
+		a := &models.Attachment{UserId: userID, TransactionId: transactionID}
+		if err := rows.Scan(&a.ID, &a.FileName, &a.ContentType, &a.SizeBytes, &a.StorageKey, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *PostgresAttachmentRepo) GetAttachment(ctx context.Context, userID, attachmentID uuid.UUID) (*models.Attachment, error) {
+	sql := `SELECT id, user_id, transaction_id, file_name, content_type, size_bytes, storage_key, created_at
+			FROM attachments
+			WHERE id = $1 AND user_id = $2`
+
+	a := &models.Attachment{}
+	err := r.DB.QueryRow(ctx, sql, attachmentID, userID).Scan(
+		&a.ID, &a.UserId, &a.TransactionId, &a.FileName, &a.ContentType, &a.SizeBytes, &a.StorageKey, &a.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+func (r *PostgresAttachmentRepo) DeleteAttachment(ctx context.Context, userID, attachmentID uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM attachments WHERE id = $1 AND user_id = $2`, attachmentID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
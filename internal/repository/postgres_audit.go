@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type auditMetaKey struct{}
+
+// WithAuditMeta attaches the origin of the changes made with ctx, every audit
+// entry written by the repositories records it
+func WithAuditMeta(ctx context.Context, meta models.AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+// recordAudit stores an audit entry within the caller's transaction, so the
+// entry exists if and only if the change commits. before and after are
+// marshalled to JSON, nil is stored as NULL.
+func recordAudit(ctx context.Context, tx pgx.Tx, userID uuid.UUID, action, entityType string, entityID uuid.UUID, before, after any) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	meta, _ := ctx.Value(auditMetaKey{}).(models.AuditMeta)
+	_, err = tx.Exec(ctx,
+		`INSERT INTO audit_log (user_id, actor_id, action, entity_type, entity_id, before, after, ip, user_agent, request_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''))`,
+		userID, meta.ActorId, action, entityType, entityID, beforeJSON, afterJSON, meta.IP, meta.UserAgent, meta.RequestID,
+	)
+	return err
+}
+
+// auditJSON marshals v, nil pointers included, to NULL-able JSON
+func auditJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
+
+// lockTransaction locks a transaction until tx ends and returns its current
+// state, the "before" of the audit entry of the change about to be made
+func lockTransaction(ctx context.Context, tx pgx.Tx, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	var locked bool
+	err := tx.QueryRow(ctx,
+		`SELECT true FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		transactionID, userID,
+	).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return (&PostgresTransactionRepo{DB: tx}).GetTransaction(ctx, userID, transactionID)
+}
+
+// auditTransaction records a change of a transaction, reading its state
+// after the change (with tags and category name) within tx. It returns that
+// state, nil after a delete.
+func auditTransaction(ctx context.Context, tx pgx.Tx, userID uuid.UUID, action string, transactionID uuid.UUID, before *models.Transaction) (*models.Transaction, error) {
+	var after *models.Transaction
+	if action != models.AuditActionDelete {
+		var err error
+		after, err = (&PostgresTransactionRepo{DB: tx}).GetTransaction(ctx, userID, transactionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return after, recordAudit(ctx, tx, userID, action, models.AuditEntityTransaction, transactionID, before, after)
+}
+
+type PostgresAuditRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresAuditRepo) ListAuditEntries(ctx context.Context, userID uuid.UUID, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	sql := `SELECT id, user_id, actor_id, action, entity_type, entity_id, before, after,
+					ip, user_agent, request_id, created_at
+			FROM audit_log
+			WHERE user_id = $1`
+	args := []any{userID}
+
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		sql += fmt.Sprintf(`
+			AND action = $%d`, len(args))
+	}
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		sql += fmt.Sprintf(`
+			AND entity_type = $%d`, len(args))
+	}
+	if filter.EntityId != nil {
+		args = append(args, *filter.EntityId)
+		sql += fmt.Sprintf(`
+			AND entity_id = $%d`, len(args))
+	}
+	if filter.ActorId != nil {
+		args = append(args, *filter.ActorId)
+		sql += fmt.Sprintf(`
+			AND actor_id = $%d`, len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		sql += fmt.Sprintf(`
+			AND created_at >= $%d`, len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		sql += fmt.Sprintf(`
+			AND created_at < $%d`, len(args))
+	}
+	// Keyset pagination, an unknown cursor matches nothing
+	if filter.Before != nil {
+		args = append(args, *filter.Before)
+		sql += fmt.Sprintf(`
+			AND (created_at, id) < (SELECT created_at, id FROM audit_log WHERE id = $%d AND user_id = $1)`, len(args))
+	}
+	args = append(args, filter.Limit)
+	sql += fmt.Sprintf(`
+			ORDER BY created_at DESC, id DESC
+			LIMIT $%d`, len(args))
+
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+
+	for rows.Next() {
+		e := &models.AuditEntry{}
+		if err := rows.Scan(
+			&e.ID, &e.UserId, &e.ActorId, &e.Action, &e.EntityType, &e.EntityId,
+			&e.Before, &e.After, &e.IP, &e.UserAgent, &e.RequestID, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresBankAccountRepo struct {
+	DB DBTX
+}
+
+const bankAccountColumns = `id, user_id, provider, external_id, name, mask, currency,
+					balance, balance_updated_at, sync_cursor, last_synced_at, created_at`
+
+func scanBankAccount(row pgx.Row) (*models.BankAccount, error) {
+	a := &models.BankAccount{}
+	err := row.Scan(
+		&a.ID, &a.UserId, &a.Provider, &a.ExternalID, &a.Name, &a.Mask, &a.Currency,
+		&a.Balance, &a.BalanceUpdatedAt, &a.Cursor, &a.LastSyncedAt, &a.CreatedAt,
+	)
+	return a, err
+}
+
+func (r *PostgresBankAccountRepo) UpsertAccount(ctx context.Context, a *models.BankAccount) error {
+	// The cursor survives re-linking, so history is not fetched twice
+	sql := `INSERT INTO bank_accounts (user_id, provider, external_id, name, mask, currency, balance, balance_updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (user_id, provider, external_id) DO UPDATE SET
+				name = EXCLUDED.name, mask = EXCLUDED.mask, currency = EXCLUDED.currency,
+				balance = EXCLUDED.balance, balance_updated_at = EXCLUDED.balance_updated_at
+			RETURNING ` + bankAccountColumns
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	saved, err := scanBankAccount(tx.QueryRow(ctx, sql,
+		a.UserId, a.Provider, a.ExternalID, a.Name, a.Mask, a.Currency, a.Balance, a.BalanceUpdatedAt,
+	))
+	if err != nil {
+		return err
+	}
+	if saved.BalanceUpdatedAt != nil {
+		if err := recordBalance(ctx, tx, saved.ID, saved.Balance, *saved.BalanceUpdatedAt); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	*a = *saved
+	return nil
+}
+
+func (r *PostgresBankAccountRepo) ListAccounts(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error) {
+	sql := `SELECT ` + bankAccountColumns + `
+			FROM bank_accounts
+			WHERE user_id = $1
+			ORDER BY created_at ASC, name ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*models.BankAccount
+
+	for rows.Next() {
+		a, err := scanBankAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (r *PostgresBankAccountRepo) GetAccount(ctx context.Context, userID, accountID uuid.UUID) (*models.BankAccount, error) {
+	sql := `SELECT ` + bankAccountColumns + ` FROM bank_accounts WHERE id = $1 AND user_id = $2`
+
+	a, err := scanBankAccount(r.DB.QueryRow(ctx, sql, accountID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+func (r *PostgresBankAccountRepo) UpdateCursor(ctx context.Context, userID, accountID uuid.UUID, cursor string, syncedAt time.Time) error {
+	return r.exec(ctx,
+		`UPDATE bank_accounts SET sync_cursor = $1, last_synced_at = $2 WHERE id = $3 AND user_id = $4`,
+		cursor, syncedAt, accountID, userID,
+	)
+}
+
+func (r *PostgresBankAccountRepo) UpdateBalance(ctx context.Context, userID, accountID uuid.UUID, balance int64, asOf time.Time) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	cmd, err := tx.Exec(ctx,
+		`UPDATE bank_accounts SET balance = $1, balance_updated_at = $2 WHERE id = $3 AND user_id = $4`,
+		balance, asOf, accountID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if err := recordBalance(ctx, tx, accountID, balance, asOf); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// BalanceAt returns the last balance recorded at or before at. An account
+// linked after at reports the first balance it was seen with.
+func (r *PostgresBankAccountRepo) BalanceAt(ctx context.Context, userID, accountID uuid.UUID, at time.Time) (int64, error) {
+	var balance int64
+	err := r.DB.QueryRow(ctx,
+		`SELECT b.balance FROM bank_account_balances b
+		 JOIN bank_accounts a ON a.id = b.account_id
+		 WHERE b.account_id = $1 AND a.user_id = $2
+		 ORDER BY b.as_of <= $3 DESC,
+				  CASE WHEN b.as_of <= $3 THEN b.as_of END DESC,
+				  b.as_of ASC
+		 LIMIT 1`,
+		accountID, userID, at,
+	).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return balance, nil
+}
+
+// recordBalance keeps the balance in the account's history within tx
+func recordBalance(ctx context.Context, tx pgx.Tx, accountID uuid.UUID, balance int64, asOf time.Time) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO bank_account_balances (account_id, as_of, balance) VALUES ($1, $2, $3)
+		 ON CONFLICT (account_id, as_of) DO UPDATE SET balance = EXCLUDED.balance`,
+		accountID, asOf, balance,
+	)
+	return err
+}
+
+// DeleteAccount unlinks the account; transactions already synced are kept
+func (r *PostgresBankAccountRepo) DeleteAccount(ctx context.Context, userID, accountID uuid.UUID) error {
+	return r.exec(ctx, `DELETE FROM bank_accounts WHERE id = $1 AND user_id = $2`, accountID, userID)
+}
+
+// exec runs a single-row statement, reporting ErrNotFound when nothing matched
+func (r *PostgresBankAccountRepo) exec(ctx context.Context, sql string, args ...any) error {
+	cmd, err := r.DB.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresBudgetRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresBudgetRepo) ListAssigned(ctx context.Context, userID uuid.UUID, to time.Time) ([]*models.MonthlyAmount, error) {
+	sql := `SELECT category_id, month, assigned
+			FROM budgets
+			WHERE user_id = $1 AND month < $2
+			AND category_id NOT IN (SELECT id FROM categories WHERE user_id = $1 AND deleted_at IS NOT NULL)
+			ORDER BY month ASC`
+	return r.queryAmounts(ctx, sql, userID, to)
+}
+
+func (r *PostgresBudgetRepo) ListActivity(ctx context.Context, userID uuid.UUID, to time.Time) ([]*models.MonthlyAmount, error) {
+	sql := `SELECT category_id, date_trunc('month', date)::date AS month, SUM(amount)
+			FROM transactions
+			WHERE user_id = $1 AND date < $2 AND deleted_at IS NULL
+			GROUP BY category_id, month
+			ORDER BY month ASC`
+	return r.queryAmounts(ctx, sql, userID, to)
+}
+
+func (r *PostgresBudgetRepo) SetAssigned(ctx context.Context, userID, categoryID uuid.UUID, month time.Time, amount int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if err := lockBudget(ctx, tx, userID); err != nil {
+		return err
+	}
+	sql := `INSERT INTO budgets (user_id, category_id, month, assigned)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, category_id, month)
+			DO UPDATE SET assigned = EXCLUDED.assigned, updated_at = NOW()`
+	if err := upsertBudget(ctx, tx, sql, userID, categoryID, month, amount); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresBudgetRepo) LockBudget(ctx context.Context, userID uuid.UUID) error {
+	return lockBudget(ctx, r.DB, userID)
+}
+
+// lockBudget takes a transaction-level advisory lock on the user's budget.
+// Row locks would not cover the budget rows a move is about to insert.
+func lockBudget(ctx context.Context, db DBTX, userID uuid.UUID) error {
+	_, err := db.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('budget:' || $1::text, 0))`, userID)
+	return err
+}
+
+func (r *PostgresBudgetRepo) MoveAssigned(ctx context.Context, userID uuid.UUID, month time.Time, from, to *uuid.UUID, amount int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if err := lockBudget(ctx, tx, userID); err != nil {
+		return err
+	}
+	if from != nil {
+		if err := addAssigned(ctx, tx, userID, *from, month, -amount); err != nil {
+			return err
+		}
+	}
+	if to != nil {
+		if err := addAssigned(ctx, tx, userID, *to, month, amount); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func addAssigned(ctx context.Context, tx pgx.Tx, userID, categoryID uuid.UUID, month time.Time, delta int64) error {
+	sql := `INSERT INTO budgets (user_id, category_id, month, assigned)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, category_id, month)
+			DO UPDATE SET assigned = budgets.assigned + EXCLUDED.assigned, updated_at = NOW()`
+	return upsertBudget(ctx, tx, sql, userID, categoryID, month, delta)
+}
+
+// budgetSnapshot is the state of a budget row kept in the audit log
+type budgetSnapshot struct {
+	ID         uuid.UUID `json:"id"`
+	CategoryId uuid.UUID `json:"category_id"`
+	Month      string    `json:"month"`
+	Assigned   int64     `json:"assigned"`
+}
+
+// upsertBudget runs one of the budget upserts above and records the change in the audit log
+func upsertBudget(ctx context.Context, tx pgx.Tx, sql string, userID, categoryID uuid.UUID, month time.Time, amount int64) error {
+	var before *budgetSnapshot
+	old := budgetSnapshot{CategoryId: categoryID, Month: month.Format("2006-01")}
+	err := tx.QueryRow(ctx,
+		`SELECT id, assigned FROM budgets WHERE user_id = $1 AND category_id = $2 AND month = $3 FOR UPDATE`,
+		userID, categoryID, month,
+	).Scan(&old.ID, &old.Assigned)
+	switch {
+	case err == nil:
+		before = &old
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	after := budgetSnapshot{CategoryId: categoryID, Month: old.Month}
+	if err := tx.QueryRow(ctx, sql+`
+			RETURNING id, assigned`,
+		userID, categoryID, month, amount,
+	).Scan(&after.ID, &after.Assigned); err != nil {
+		return err
+	}
+
+	action := models.AuditActionUpdate
+	if before == nil {
+		action = models.AuditActionCreate
+	}
+	return recordAudit(ctx, tx, userID, action, models.AuditEntityBudget, after.ID, before, &after)
+}
+
+func (r *PostgresBudgetRepo) queryAmounts(ctx context.Context, sql string, args ...any) ([]*models.MonthlyAmount, error) {
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amounts []*models.MonthlyAmount
+
+	for rows.Next() {
+		a := &models.MonthlyAmount{}
+		if err := rows.Scan(&a.CategoryId, &a.Month, &a.Amount); err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return amounts, nil
+}
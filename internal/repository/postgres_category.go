@@ -2,28 +2,59 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 	"github.com/olmits/budget-tracker-backend/internal/models"
 )
 
 type PostgresCategoryRepo struct {
-	DB *pgxpool.Pool
+	DB DBTX
 }
 
 func (r *PostgresCategoryRepo) CreateCategory(ctx context.Context, c *models.Category) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
 	sql := `INSERT INTO categories (user_id, name, type)
 			VALUES ($1, $2, $3)
-			RETURNING id, created_at`
-	return r.DB.QueryRow(ctx, sql,
+			RETURNING id, created_at, version`
+	if err := tx.QueryRow(ctx, sql,
 		c.UserId, c.Name, c.Type,
-	).Scan(&c.ID, &c.CreatedAt)
+	).Scan(&c.ID, &c.CreatedAt, &c.Version); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, c.UserId, models.EventCategoryCreated, c); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, c.UserId, models.AuditActionCreate, models.AuditEntityCategory, c.ID, nil, c); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresCategoryRepo) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error) {
+	sql := `SELECT id, user_id, name, type, created_at, version FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	c := &models.Category{}
+	err := r.DB.QueryRow(ctx, sql, categoryID, userID).Scan(&c.ID, &c.UserId, &c.Name, &c.Type, &c.CreatedAt, &c.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return c, nil
 }
 
 func (r *PostgresCategoryRepo) ListCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error) {
 	// 1. Define the SQL
-	sql := `SELECT id, name, type FROM categories WHERE user_id = $1 ORDER BY name ASC`
+	sql := `SELECT id, name, type, version FROM categories WHERE user_id = $1 AND deleted_at IS NULL ORDER BY name ASC`
 
 	// 2. Execute Query
 	rows, err := r.DB.Query(ctx, sql, userID)
@@ -41,6 +72,7 @@ func (r *PostgresCategoryRepo) ListCategories(ctx context.Context, userID uuid.U
 			&c.ID,
 			&c.Name,
 			&c.Type,
+			&c.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -55,3 +87,118 @@ func (r *PostgresCategoryRepo) ListCategories(ctx context.Context, userID uuid.U
 
 	return categories, nil
 }
+
+func (r *PostgresCategoryRepo) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, versions []int, reassignTo *uuid.UUID) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// 1. Lock the category
+	before := &models.Category{}
+	err = tx.QueryRow(ctx,
+		`SELECT id, user_id, name, type, created_at, version FROM categories
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		 FOR UPDATE`,
+		categoryID, userID,
+	).Scan(&before.ID, &before.UserId, &before.Name, &before.Type, &before.CreatedAt, &before.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := checkVersion(versions, before.Version); err != nil {
+		return err
+	}
+
+	// 2. Move its transactions and goals, each transaction change is an update of its own
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM transactions WHERE category_id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		categoryID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	transactionIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return err
+	}
+	var goals int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM goals WHERE category_id = $1 AND user_id = $2`,
+		categoryID, userID,
+	).Scan(&goals); err != nil {
+		return err
+	}
+	if reassignTo == nil && (len(transactionIDs) > 0 || goals > 0) {
+		return ErrInUse
+	}
+	if reassignTo != nil {
+		var exists bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND id <> $3)`,
+			*reassignTo, userID, categoryID,
+		).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+	}
+	for _, id := range transactionIDs {
+		t, err := lockTransaction(ctx, tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE transactions SET category_id = $1, version = version + 1 WHERE id = $2`, *reassignTo, id); err != nil {
+			return err
+		}
+		after, err := auditTransaction(ctx, tx, userID, models.AuditActionUpdate, id, t)
+		if err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, userID, models.EventTransactionUpdated, after); err != nil {
+			return err
+		}
+	}
+	if goals > 0 {
+		if _, err := tx.Exec(ctx,
+			`UPDATE goals SET category_id = $1 WHERE category_id = $2 AND user_id = $3`,
+			*reassignTo, categoryID, userID,
+		); err != nil {
+			return err
+		}
+	}
+
+	// 3. Rules must not keep filing transactions into the trashed category:
+	// they follow its transactions, or stop when there is nowhere to go
+	if reassignTo != nil {
+		_, err = tx.Exec(ctx,
+			`UPDATE rules SET set_category_id = $1 WHERE set_category_id = $2 AND user_id = $3`,
+			*reassignTo, categoryID, userID,
+		)
+	} else {
+		_, err = tx.Exec(ctx,
+			`UPDATE rules SET enabled = false WHERE set_category_id = $1 AND user_id = $2`,
+			categoryID, userID,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 4. Move the category to the trash
+	if _, err := tx.Exec(ctx, `UPDATE categories SET deleted_at = NOW(), version = version + 1 WHERE id = $1`, categoryID); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, userID, models.EventCategoryDeleted, map[string]uuid.UUID{"id": categoryID}); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, userID, models.AuditActionDelete, models.AuditEntityCategory, categoryID, before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresDuplicateRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresDuplicateRepo) SaveCandidate(ctx context.Context, c *models.DuplicateCandidate) error {
+	sql := `INSERT INTO duplicate_candidates (user_id, transaction_id, duplicate_of_id, score)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (transaction_id, duplicate_of_id) DO UPDATE SET score = EXCLUDED.score
+			RETURNING id, status, created_at`
+	return r.DB.QueryRow(ctx, sql,
+		c.UserId, c.TransactionId, c.DuplicateOfId, c.Score,
+	).Scan(&c.ID, &c.Status, &c.CreatedAt)
+}
+
+func (r *PostgresDuplicateRepo) ListCandidates(ctx context.Context, userID uuid.UUID, status string) ([]*models.DuplicateCandidate, error) {
+	sql := `SELECT
+					d.id, d.transaction_id, d.duplicate_of_id, d.score, d.status, d.created_at,
+					t1.amount, t1.description, t1.date, t1.category_id, t1.created_at,
+					t2.amount, t2.description, t2.date, t2.category_id, t2.created_at
+			FROM duplicate_candidates d
+			INNER JOIN transactions t1 ON t1.id = d.transaction_id
+			INNER JOIN transactions t2 ON t2.id = d.duplicate_of_id
+			WHERE d.user_id = $1 AND d.status = $2
+			AND t1.deleted_at IS NULL AND t2.deleted_at IS NULL
+			ORDER BY d.score DESC, d.created_at DESC`
+
+	rows, err := r.DB.Query(ctx, sql, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*models.DuplicateCandidate
+
+	for rows.Next() {
+		c := &models.DuplicateCandidate{
+			UserId:      userID,
+			Transaction: &models.Transaction{UserId: userID},
+			DuplicateOf: &models.Transaction{UserId: userID},
+		}
+		if err := rows.Scan(
+			&c.ID, &c.TransactionId, &c.DuplicateOfId, &c.Score, &c.Status, &c.CreatedAt,
+			&c.Transaction.Amount, &c.Transaction.Description, &c.Transaction.Date, &c.Transaction.CategoryId, &c.Transaction.CreatedAt,
+			&c.DuplicateOf.Amount, &c.DuplicateOf.Description, &c.DuplicateOf.Date, &c.DuplicateOf.CategoryId, &c.DuplicateOf.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		c.Transaction.ID = c.TransactionId
+		c.DuplicateOf.ID = c.DuplicateOfId
+		candidates = append(candidates, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+func (r *PostgresDuplicateRepo) DismissCandidate(ctx context.Context, userID, candidateID uuid.UUID) error {
+	sql := `UPDATE duplicate_candidates SET status = $1, resolved_at = NOW()
+			WHERE id = $2 AND user_id = $3 AND status = $4`
+
+	cmd, err := r.DB.Exec(ctx, sql, models.DuplicateStatusDismissed, candidateID, userID, models.DuplicateStatusPending)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresDuplicateRepo) MergeCandidate(ctx context.Context, userID, candidateID uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// 1. Lock the pending pair
+	var duplicateID, keptID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`SELECT transaction_id, duplicate_of_id FROM duplicate_candidates
+		 WHERE id = $1 AND user_id = $2 AND status = $3
+		 FOR UPDATE`,
+		candidateID, userID, models.DuplicateStatusPending,
+	).Scan(&duplicateID, &keptID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	deleted, err := lockTransaction(ctx, tx, userID, duplicateID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	kept, err := lockTransaction(ctx, tx, userID, keptID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// 2. Carry over tags and receipts so nothing is lost
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO transaction_tags (transaction_id, tag_id)
+		 SELECT $1, tag_id FROM transaction_tags WHERE transaction_id = $2
+		 ON CONFLICT DO NOTHING`,
+		keptID, duplicateID,
+	); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE transactions SET version = version + 1 WHERE id = $1`, keptID); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE attachments SET transaction_id = $1 WHERE transaction_id = $2`,
+		keptID, duplicateID,
+	); err != nil {
+		return uuid.Nil, err
+	}
+
+	// 3. Move the duplicate to the trash. Its candidate rows are hidden with it
+	// and come back if it is restored.
+	if err := trashTransaction(ctx, tx, deleted); err != nil {
+		return uuid.Nil, err
+	}
+	if err := recordEvent(ctx, tx, userID, models.EventTransactionDeleted, map[string]uuid.UUID{
+		"id":          duplicateID,
+		"merged_into": keptID,
+	}); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := auditTransaction(ctx, tx, userID, models.AuditActionUpdate, keptID, kept); err != nil {
+		return uuid.Nil, err
+	}
+
+	return keptID, tx.Commit(ctx)
+}
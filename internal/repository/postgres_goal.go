@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresGoalRepo struct {
+	DB DBTX
+}
+
+const goalColumns = `id, user_id, name, target_amount, start_date, target_date,
+					category_id, bank_account_id, created_at`
+
+func scanGoal(row pgx.Row) (*models.Goal, error) {
+	g := &models.Goal{}
+	err := row.Scan(
+		&g.ID, &g.UserId, &g.Name, &g.TargetAmount, &g.StartDate, &g.TargetDate,
+		&g.CategoryId, &g.BankAccountId, &g.CreatedAt,
+	)
+	return g, err
+}
+
+func (r *PostgresGoalRepo) CreateGoal(ctx context.Context, g *models.Goal) error {
+	sql := `INSERT INTO goals (user_id, name, target_amount, start_date, target_date, category_id, bank_account_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at`
+	return r.DB.QueryRow(ctx, sql,
+		g.UserId, g.Name, g.TargetAmount, g.StartDate, g.TargetDate, g.CategoryId, g.BankAccountId,
+	).Scan(&g.ID, &g.CreatedAt)
+}
+
+func (r *PostgresGoalRepo) ListGoals(ctx context.Context, userID uuid.UUID) ([]*models.Goal, error) {
+	sql := `SELECT ` + goalColumns + `
+			FROM goals
+			WHERE user_id = $1
+			ORDER BY target_date ASC NULLS LAST, created_at ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []*models.Goal
+
+	for rows.Next() {
+		g, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+func (r *PostgresGoalRepo) GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*models.Goal, error) {
+	sql := `SELECT ` + goalColumns + ` FROM goals WHERE id = $1 AND user_id = $2`
+
+	g, err := scanGoal(r.DB.QueryRow(ctx, sql, goalID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *PostgresGoalRepo) UpdateGoal(ctx context.Context, g *models.Goal) error {
+	sql := `UPDATE goals SET
+					name = $1, target_amount = $2, start_date = $3, target_date = $4,
+					category_id = $5, bank_account_id = $6
+			WHERE id = $7 AND user_id = $8
+			RETURNING created_at`
+
+	err := r.DB.QueryRow(ctx, sql,
+		g.Name, g.TargetAmount, g.StartDate, g.TargetDate, g.CategoryId, g.BankAccountId,
+		g.ID, g.UserId,
+	).Scan(&g.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *PostgresGoalRepo) DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM goals WHERE id = $1 AND user_id = $2`, goalID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresGoalRepo) SumCategory(ctx context.Context, userID, categoryID uuid.UUID, from time.Time) (int64, error) {
+	var total int64
+	err := r.DB.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM transactions
+		 WHERE user_id = $1 AND category_id = $2 AND date >= $3 AND deleted_at IS NULL`,
+		userID, categoryID, from,
+	).Scan(&total)
+	return total, err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresIdempotencyRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresIdempotencyRepo) ReserveKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, lockedUntil, expiresAt time.Time) (*models.IdempotencyKey, error) {
+	// An expired key, or one whose request died before storing a response, is
+	// taken over as if it was new
+	sql := `INSERT INTO idempotency_keys (user_id, key, fingerprint, locked_until, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, response_headers = NULL,
+				response_body = NULL, created_at = NOW(),
+				locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= NOW()
+				OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= NOW())`
+	cmd, err := r.DB.Exec(ctx, sql, userID, key, fingerprint, lockedUntil, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	k := &models.IdempotencyKey{UserId: userID, Key: key}
+	sql = `SELECT fingerprint, status_code, response_headers, response_body, created_at, locked_until, expires_at
+			FROM idempotency_keys
+			WHERE user_id = $1 AND key = $2`
+	err = r.DB.QueryRow(ctx, sql, userID, key).Scan(
+		&k.Fingerprint, &k.StatusCode, &k.Headers, &k.Body, &k.CreatedAt, &k.LockedUntil, &k.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released between both statements, the client can simply retry
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *PostgresIdempotencyRepo) SaveResponse(ctx context.Context, userID uuid.UUID, key string, statusCode int, headers map[string]string, body []byte) error {
+	sql := `UPDATE idempotency_keys
+			SET status_code = $3, response_headers = $4, response_body = $5, locked_until = NULL
+			WHERE user_id = $1 AND key = $2 AND status_code IS NULL`
+	cmd, err := r.DB.Exec(ctx, sql, userID, key, statusCode, headers, body)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresIdempotencyRepo) ReleaseKey(ctx context.Context, userID uuid.UUID, key string) error {
+	sql := `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status_code IS NULL`
+	_, err := r.DB.Exec(ctx, sql, userID, key)
+	return err
+}
+
+func (r *PostgresIdempotencyRepo) DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
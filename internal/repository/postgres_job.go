@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresJobRepo struct {
+	DB DBTX
+}
+
+const jobColumns = `id, user_id, type, payload, status, attempts, max_attempts, run_at, last_error, created_at, finished_at`
+
+func scanJob(row pgx.Row) (*models.Job, error) {
+	j := &models.Job{}
+	err := row.Scan(
+		&j.ID, &j.UserId, &j.Type, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts,
+		&j.RunAt, &j.LastError, &j.CreatedAt, &j.FinishedAt,
+	)
+	return j, err
+}
+
+func (r *PostgresJobRepo) EnqueueJob(ctx context.Context, j *models.Job) error {
+	sql := `INSERT INTO jobs (user_id, type, payload, max_attempts, run_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, status, attempts, created_at`
+	return r.DB.QueryRow(ctx, sql,
+		j.UserId, j.Type, j.Payload, j.MaxAttempts, j.RunAt,
+	).Scan(&j.ID, &j.Status, &j.Attempts, &j.CreatedAt)
+}
+
+func (r *PostgresJobRepo) ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, types []string, limit int) ([]*models.Job, error) {
+	sql := `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_until = $2
+			WHERE id IN (
+				SELECT id FROM jobs
+				WHERE type = ANY($3)
+					AND ((status = 'queued' AND run_at <= $1) OR (status = 'running' AND locked_until <= $1))
+				ORDER BY run_at ASC
+				LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + jobColumns
+	return r.queryJobs(ctx, sql, now, now.Add(lease), types, limit)
+}
+
+func (r *PostgresJobRepo) SaveJobResult(ctx context.Context, j *models.Job) error {
+	// Only the attempt that still holds the claim may store its result
+	sql := `UPDATE jobs
+			SET status = $1, run_at = $2, last_error = $3, finished_at = $4, locked_until = NULL
+			WHERE id = $5 AND status = 'running' AND attempts = $6`
+	cmd, err := r.DB.Exec(ctx, sql, j.Status, j.RunAt, j.LastError, j.FinishedAt, j.ID, j.Attempts)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *PostgresJobRepo) GetJob(ctx context.Context, userID, jobID uuid.UUID) (*models.Job, error) {
+	sql := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1 AND user_id = $2`
+
+	j, err := scanJob(r.DB.QueryRow(ctx, sql, jobID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return j, nil
+}
+
+func (r *PostgresJobRepo) ListJobs(ctx context.Context, userID uuid.UUID, status string, limit int) ([]*models.Job, error) {
+	sql := `SELECT ` + jobColumns + `
+			FROM jobs
+			WHERE user_id = $1 AND ($2 = '' OR status = $2)
+			ORDER BY created_at DESC
+			LIMIT $3`
+	return r.queryJobs(ctx, sql, userID, status, limit)
+}
+
+func (r *PostgresJobRepo) RetryJob(ctx context.Context, userID, jobID uuid.UUID) (*models.Job, error) {
+	sql := `UPDATE jobs
+			SET status = 'queued', attempts = 0, run_at = NOW(), last_error = NULL, finished_at = NULL
+			WHERE id = $1 AND user_id = $2 AND status = 'dead'
+			RETURNING ` + jobColumns
+
+	j, err := scanJob(r.DB.QueryRow(ctx, sql, jobID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return j, nil
+}
+
+func (r *PostgresJobRepo) queryJobs(ctx context.Context, sql string, args ...any) ([]*models.Job, error) {
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*models.Job{}
+
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
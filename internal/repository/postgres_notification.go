@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresNotificationRepo struct {
+	DB DBTX
+}
+
+const notificationColumns = `id, user_id, kind, title, body, data, read_at, created_at`
+
+func scanNotification(row pgx.Row) (*models.Notification, error) {
+	n := &models.Notification{}
+	err := row.Scan(&n.ID, &n.UserId, &n.Kind, &n.Title, &n.Body, &n.Data, &n.ReadAt, &n.CreatedAt)
+	return n, err
+}
+
+func (r *PostgresNotificationRepo) CreateNotification(ctx context.Context, n *models.Notification) error {
+	if n.Data == nil {
+		n.Data = map[string]any{}
+	}
+	sql := `INSERT INTO notifications (user_id, kind, title, body, data, dedupe_key)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+			ON CONFLICT (user_id, dedupe_key) WHERE dedupe_key IS NOT NULL DO NOTHING
+			RETURNING id, created_at`
+	err := r.DB.QueryRow(ctx, sql,
+		n.UserId, n.Kind, n.Title, n.Body, n.Data, n.Key,
+	).Scan(&n.ID, &n.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAlreadyQueued
+	}
+	return err
+}
+
+func (r *PostgresNotificationRepo) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	sql := `SELECT ` + notificationColumns + `
+			FROM notifications
+			WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+			ORDER BY created_at DESC
+			LIMIT $3`
+
+	rows, err := r.DB.Query(ctx, sql, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *PostgresNotificationRepo) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.DB.QueryRow(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID,
+	).Scan(&count)
+	return count, err
+}
+
+// MarkRead is idempotent, reading a notification twice keeps the first read_at
+func (r *PostgresNotificationRepo) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`,
+		notificationID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresNotificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	cmd, err := r.DB.Exec(ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
+
+func (r *PostgresNotificationRepo) ListPreferences(ctx context.Context, userID uuid.UUID) ([]*models.NotificationPreference, error) {
+	sql := `SELECT channel, enabled, target, kinds
+			FROM notification_preferences
+			WHERE user_id = $1
+			ORDER BY channel ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := []*models.NotificationPreference{}
+
+	for rows.Next() {
+		p := &models.NotificationPreference{}
+		if err := rows.Scan(&p.Channel, &p.Enabled, &p.Target, &p.Kinds); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (r *PostgresNotificationRepo) SavePreferences(ctx context.Context, userID uuid.UUID, prefs []*models.NotificationPreference) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if _, err := tx.Exec(ctx, `DELETE FROM notification_preferences WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, p := range prefs {
+		kinds := p.Kinds
+		if kinds == nil {
+			kinds = []string{}
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO notification_preferences (user_id, channel, enabled, target, kinds)
+			 VALUES ($1, $2, $3, $4, $5)`,
+			userID, p.Channel, p.Enabled, p.Target, kinds,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+// OutboxChannel is notified when a transaction that recorded events commits
+const OutboxChannel = "outbox_events"
+
+// recordEvent stores a domain event in the outbox within the caller's
+// transaction, so the event is published if and only if the change commits
+func recordEvent(ctx context.Context, tx pgx.Tx, userID uuid.UUID, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO outbox_events (user_id, type, payload) VALUES ($1, $2, $3)`,
+		userID, eventType, payload,
+	); err != nil {
+		return err
+	}
+	// Delivered on commit only, wakes the relays without waiting for their next poll
+	_, err = tx.Exec(ctx, `SELECT pg_notify($1, '')`, OutboxChannel)
+	return err
+}
+
+type PostgresOutboxRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresOutboxRepo) ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+	sql := `UPDATE outbox_events SET attempts = attempts + 1, locked_until = $2
+			WHERE seq IN (
+				SELECT seq FROM outbox_events
+				WHERE locked_until IS NULL OR locked_until <= $1
+				ORDER BY seq ASC
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING seq, event_id, user_id, type, payload, created_at, attempts`
+
+	rows, err := r.DB.Query(ctx, sql, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+
+	for rows.Next() {
+		var payload json.RawMessage
+		e := &models.OutboxEvent{Event: &models.Event{}}
+		if err := rows.Scan(
+			&e.Seq, &e.Event.ID, &e.UserId, &e.Event.Type, &payload, &e.Event.CreatedAt, &e.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		e.Event.Data = payload
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// UPDATE ... RETURNING does not keep the subquery order
+	slices.SortFunc(events, func(a, b *models.OutboxEvent) int { return cmp.Compare(a.Seq, b.Seq) })
+	return events, nil
+}
+
+func (r *PostgresOutboxRepo) DeleteEvent(ctx context.Context, seq int64) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM outbox_events WHERE seq = $1`, seq)
+	return err
+}
+
+func (r *PostgresOutboxRepo) ReleaseEvent(ctx context.Context, seq int64, retryAt time.Time, lastError string) error {
+	_, err := r.DB.Exec(ctx,
+		`UPDATE outbox_events SET locked_until = $1, last_error = $2 WHERE seq = $3`,
+		retryAt, lastError, seq,
+	)
+	return err
+}
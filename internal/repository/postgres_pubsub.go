@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresPubSub uses LISTEN/NOTIFY. Postgres rejects payloads over 8000 bytes.
+type PostgresPubSub struct {
+	DB *pgxpool.Pool
+}
+
+func (r *PostgresPubSub) Notify(ctx context.Context, channel, payload string) error {
+	_, err := r.DB.Exec(ctx, `SELECT pg_notify($1, $2)`, channel, payload)
+	return err
+}
+
+func (r *PostgresPubSub) Listen(ctx context.Context, channel string, handle func(payload string)) error {
+	conn, err := r.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection stays subscribed, so it is closed instead of going back to the pool
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.Background())
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		n, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(n.Payload)
+	}
+}
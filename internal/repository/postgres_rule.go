@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresRuleRepo struct {
+	DB DBTX
+}
+
+const ruleColumns = `id, user_id, name, priority, enabled,
+					description_pattern, match_type, min_amount, max_amount, weekdays,
+					set_category_id, set_description, add_tag_ids, created_at`
+
+func (r *PostgresRuleRepo) CreateRule(ctx context.Context, rule *models.Rule) error {
+	sql := `INSERT INTO rules (user_id, name, priority, enabled,
+					description_pattern, match_type, min_amount, max_amount, weekdays,
+					set_category_id, set_description, add_tag_ids)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id, created_at`
+	return r.DB.QueryRow(ctx, sql,
+		rule.UserId, rule.Name, rule.Priority, rule.Enabled,
+		rule.DescriptionPattern, rule.MatchType, rule.MinAmount, rule.MaxAmount, nonNilInts(rule.Weekdays),
+		rule.SetCategoryId, rule.SetDescription, nonNilUUIDs(rule.AddTagIds),
+	).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+func (r *PostgresRuleRepo) ListRules(ctx context.Context, userID uuid.UUID) ([]*models.Rule, error) {
+	sql := `SELECT ` + ruleColumns + `
+			FROM rules
+			WHERE user_id = $1
+			ORDER BY priority DESC, created_at ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.Rule
+
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *PostgresRuleRepo) GetRule(ctx context.Context, userID, ruleID uuid.UUID) (*models.Rule, error) {
+	sql := `SELECT ` + ruleColumns + ` FROM rules WHERE id = $1 AND user_id = $2`
+
+	rule, err := scanRule(r.DB.QueryRow(ctx, sql, ruleID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *PostgresRuleRepo) UpdateRule(ctx context.Context, rule *models.Rule) error {
+	sql := `UPDATE rules SET
+					name = $1, priority = $2, enabled = $3,
+					description_pattern = $4, match_type = $5, min_amount = $6, max_amount = $7, weekdays = $8,
+					set_category_id = $9, set_description = $10, add_tag_ids = $11
+			WHERE id = $12 AND user_id = $13
+			RETURNING created_at`
+
+	err := r.DB.QueryRow(ctx, sql,
+		rule.Name, rule.Priority, rule.Enabled,
+		rule.DescriptionPattern, rule.MatchType, rule.MinAmount, rule.MaxAmount, nonNilInts(rule.Weekdays),
+		rule.SetCategoryId, rule.SetDescription, nonNilUUIDs(rule.AddTagIds),
+		rule.ID, rule.UserId,
+	).Scan(&rule.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *PostgresRuleRepo) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanRule(row pgx.Row) (*models.Rule, error) {
+	rule := &models.Rule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserId,
+		&rule.Name,
+		&rule.Priority,
+		&rule.Enabled,
+		&rule.DescriptionPattern,
+		&rule.MatchType,
+		&rule.MinAmount,
+		&rule.MaxAmount,
+		&rule.Weekdays,
+		&rule.SetCategoryId,
+		&rule.SetDescription,
+		&rule.AddTagIds,
+		&rule.CreatedAt,
+	)
+	return rule, err
+}
+
+// The array columns are NOT NULL, so never send a nil slice
+func nonNilInts(v []int) []int {
+	if v == nil {
+		return []int{}
+	}
+	return v
+}
+
+func nonNilUUIDs(v []uuid.UUID) []uuid.UUID {
+	if v == nil {
+		return []uuid.UUID{}
+	}
+	return v
+}
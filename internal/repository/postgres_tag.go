@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresTagRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresTagRepo) CreateTag(ctx context.Context, tag *models.Tag) error {
+	sql := `INSERT INTO tags (user_id, name)
+			VALUES ($1, $2)
+			RETURNING id, created_at`
+	return r.DB.QueryRow(ctx, sql, tag.UserId, tag.Name).Scan(&tag.ID, &tag.CreatedAt)
+}
+
+func (r *PostgresTagRepo) ListTags(ctx context.Context, userID uuid.UUID) ([]*models.Tag, error) {
+	sql := `SELECT id, name, created_at FROM tags WHERE user_id = $1 ORDER BY name ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*models.Tag
+
+	for rows.Next() {
+		tag := &models.Tag{UserId: userID}
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *PostgresTagRepo) GetTag(ctx context.Context, userID, tagID uuid.UUID) (*models.Tag, error) {
+	sql := `SELECT id, user_id, name, created_at FROM tags WHERE id = $1 AND user_id = $2`
+
+	tag := &models.Tag{}
+	err := r.DB.QueryRow(ctx, sql, tagID, userID).Scan(&tag.ID, &tag.UserId, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *PostgresTagRepo) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	// The tagged transactions show the new name, they change with it
+	sql := `WITH touched AS (
+				UPDATE transactions SET version = version + 1
+				WHERE id IN (SELECT transaction_id FROM transaction_tags WHERE tag_id = $2)
+					AND user_id = $3
+			)
+			UPDATE tags SET name = $1
+			WHERE id = $2 AND user_id = $3
+			RETURNING created_at`
+
+	err := r.DB.QueryRow(ctx, sql, tag.Name, tag.ID, tag.UserId).Scan(&tag.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *PostgresTagRepo) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	// transaction_tags rows are removed by ON DELETE CASCADE, the tagged
+	// transactions lose the tag and change with it
+	sql := `WITH touched AS (
+				UPDATE transactions SET version = version + 1
+				WHERE id IN (SELECT transaction_id FROM transaction_tags WHERE tag_id = $1)
+					AND user_id = $2
+			)
+			DELETE FROM tags WHERE id = $1 AND user_id = $2`
+	cmd, err := r.DB.Exec(ctx, sql, tagID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresTagRepo) SetTransactionTags(ctx context.Context, userID, transactionID uuid.UUID, versions []int, tagIDs []uuid.UUID) (int, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// 1. Make sure the transaction belongs to the user
+	before, err := lockTransaction(ctx, tx, userID, transactionID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkVersion(versions, before.Version); err != nil {
+		return 0, err
+	}
+
+	// 2. Replace the links
+	if _, err := tx.Exec(ctx, `DELETE FROM transaction_tags WHERE transaction_id = $1`, transactionID); err != nil {
+		return 0, err
+	}
+	var version int
+	err = tx.QueryRow(ctx, `UPDATE transactions SET version = version + 1 WHERE id = $1 RETURNING version`, transactionID).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	if err := linkTags(ctx, tx, userID, transactionID, tagIDs); err != nil {
+		return 0, err
+	}
+	if _, err := auditTransaction(ctx, tx, userID, models.AuditActionUpdate, transactionID, before); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit(ctx)
+}
+
+func (r *PostgresTagRepo) GetTotalsByTag(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.TagTotal, error) {
+	// LEFT JOINs keep tags without transactions in the period (with zero totals)
+	sql := `SELECT
+					tg.id,
+					tg.name,
+					COALESCE(SUM(CASE WHEN c.type = 'income' THEN t.amount ELSE 0 END), 0)::bigint as income,
+					COALESCE(SUM(CASE WHEN c.type = 'expense' THEN t.amount ELSE 0 END), 0)::bigint as expense,
+					COUNT(t.id) as count
+			FROM tags tg
+			LEFT JOIN transaction_tags tt ON tt.tag_id = tg.id
+			LEFT JOIN transactions t ON t.id = tt.transaction_id AND t.date >= $2 AND t.date < $3 AND t.deleted_at IS NULL
+			LEFT JOIN categories c ON t.category_id = c.id
+			WHERE tg.user_id = $1
+			GROUP BY tg.id, tg.name
+			ORDER BY tg.name ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*models.TagTotal
+
+	for rows.Next() {
+		tt := &models.TagTotal{}
+		if err := rows.Scan(&tt.TagID, &tt.TagName, &tt.Income, &tt.Expense, &tt.Count); err != nil {
+			return nil, err
+		}
+		totals = append(totals, tt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// linkTags attaches tags to a transaction. Tag IDs that don't belong
+// to the user are silently ignored.
+func linkTags(ctx context.Context, tx pgx.Tx, userID, transactionID uuid.UUID, tagIDs []uuid.UUID) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	sql := `INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT $1, id FROM tags WHERE user_id = $2 AND id = ANY($3)
+			ON CONFLICT DO NOTHING`
+	_, err := tx.Exec(ctx, sql, transactionID, userID, tagIDs)
+	return err
+}
@@ -2,28 +2,107 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 	"github.com/olmits/budget-tracker-backend/internal/models"
 )
 
 type PostgresTransactionRepo struct {
-	DB *pgxpool.Pool
+	DB DBTX
 }
 
 func (r *PostgresTransactionRepo) CreateTransaction(ctx context.Context, t *models.Transaction) error {
-	sql := `INSERT INTO transactions (user_id, amount, description, date, category_id)
-			VALUES ($1, $2, $3, $4, $5)
-			RETURNING id, created_at`
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	sql := `INSERT INTO transactions (user_id, amount, description, date, category_id, external_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, version`
+
+	if err := tx.QueryRow(ctx, sql,
+		t.UserId, t.Amount, t.Description, t.Date, t.CategoryId, t.ExternalID,
+	).Scan(&t.ID, &t.CreatedAt, &t.Version); err != nil {
+		return err
+	}
+
+	if err := linkTags(ctx, tx, t.UserId, t.ID, tagIDs(t.Tags)); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, t.UserId, models.EventTransactionCreated, t); err != nil {
+		return err
+	}
+	if _, err := auditTransaction(ctx, tx, t.UserId, models.AuditActionCreate, t.ID, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresTransactionRepo) GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	sql := `SELECT
+					t.id,
+					t.amount,
+					t.description,
+					t.date,
+					t.created_at,
+					t.category_id,
+					c.name as category_name,
+					c.type as type,
+					t.external_id,
+					t.version
+			FROM transactions t
+			INNER JOIN categories c ON t.category_id = c.id
+			WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL`
+
+	t := &models.Transaction{UserId: userID, Tags: []*models.Tag{}}
+	err := r.DB.QueryRow(ctx, sql, transactionID, userID).Scan(
+		&t.ID,
+		&t.Amount,
+		&t.Description,
+		&t.Date,
+		&t.CreatedAt,
+		&t.CategoryId,
+		&t.CategoryName,
+		&t.Type,
+		&t.ExternalID,
+		&t.Version,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := r.loadTags(ctx, []*models.Transaction{t}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
 
-	return r.DB.QueryRow(ctx, sql,
-		t.UserId, t.Amount, t.Description, t.Date, t.CategoryId,
-	).Scan(&t.ID, &t.CreatedAt)
+func (r *PostgresTransactionRepo) GetTransactionByExternalID(ctx context.Context, userID uuid.UUID, externalID string) (*models.Transaction, error) {
+	var transactionID uuid.UUID
+	err := r.DB.QueryRow(ctx,
+		`SELECT id FROM transactions WHERE user_id = $1 AND external_id = $2 AND deleted_at IS NULL`,
+		userID, externalID,
+	).Scan(&transactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return r.GetTransaction(ctx, userID, transactionID)
 }
 
-func (r *PostgresTransactionRepo) ListTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error) {
+func (r *PostgresTransactionRepo) ListTransactions(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error) {
 	// 1. Define the SQL
 	// We use LEFT JOIN to fetch the Category Name if it exists
 	sql := `SELECT
@@ -34,13 +113,42 @@ func (r *PostgresTransactionRepo) ListTransactions(ctx context.Context, userID u
 					t.created_at,
 					t.category_id,
 					c.name as category_name,
-            		c.type as type
+					c.type as type,
+					t.external_id,
+					t.version
 			FROM transactions t
 			INNER JOIN categories c ON t.category_id = c.id
-			WHERE t.user_id = $1
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL`
+	args := []any{userID}
+
+	// Only keep transactions carrying every requested tag
+	if len(filter.TagIDs) > 0 {
+		args = append(args, filter.TagIDs)
+		sql += fmt.Sprintf(`
+			AND (SELECT COUNT(*) FROM transaction_tags tt
+				 WHERE tt.transaction_id = t.id AND tt.tag_id = ANY($%d)) = %d`,
+			len(args), len(filter.TagIDs))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		sql += fmt.Sprintf(`
+			AND t.date >= $%d`, len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		sql += fmt.Sprintf(`
+			AND t.date < $%d`, len(args))
+	}
+	if filter.Amount != nil {
+		args = append(args, *filter.Amount)
+		sql += fmt.Sprintf(`
+			AND t.amount = $%d`, len(args))
+	}
+	sql += `
 			ORDER BY t.date DESC`
+
 	// 2. Execute Query
-	rows, err := r.DB.Query(ctx, sql, userID)
+	rows, err := r.DB.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,11 +170,14 @@ func (r *PostgresTransactionRepo) ListTransactions(ctx context.Context, userID u
 			&t.CategoryId,
 			&t.CategoryName,
 			&t.Type,
+			&t.ExternalID,
+			&t.Version,
 		); err != nil {
 			return nil, err
 		}
 
 		t.UserId = userID
+		t.Tags = []*models.Tag{}
 		transactions = append(transactions, t)
 	}
 
@@ -74,15 +185,133 @@ func (r *PostgresTransactionRepo) ListTransactions(ctx context.Context, userID u
 		return nil, err
 	}
 
+	// 4. Attach tags in a single extra round trip
+	if err := r.loadTags(ctx, transactions); err != nil {
+		return nil, err
+	}
+
 	return transactions, nil
 }
 
+func (r *PostgresTransactionRepo) UpdateTransaction(ctx context.Context, t *models.Transaction) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	before, err := lockTransaction(ctx, tx, t.UserId, t.ID)
+	if err != nil {
+		return err
+	}
+
+	sql := `UPDATE transactions
+			SET amount = $1, description = $2, date = $3, category_id = $4, version = version + 1
+			WHERE id = $5 AND user_id = $6 AND deleted_at IS NULL
+			RETURNING version`
+
+	err = tx.QueryRow(ctx, sql, t.Amount, t.Description, t.Date, t.CategoryId, t.ID, t.UserId).Scan(&t.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	// Replace the tag links with the current set
+	if _, err := tx.Exec(ctx, `DELETE FROM transaction_tags WHERE transaction_id = $1`, t.ID); err != nil {
+		return err
+	}
+	if err := linkTags(ctx, tx, t.UserId, t.ID, tagIDs(t.Tags)); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, t.UserId, models.EventTransactionUpdated, t); err != nil {
+		return err
+	}
+	if _, err := auditTransaction(ctx, tx, t.UserId, models.AuditActionUpdate, t.ID, before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresTransactionRepo) DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID, versions []int) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	before, err := lockTransaction(ctx, tx, userID, transactionID)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(versions, before.Version); err != nil {
+		return err
+	}
+	if err := trashTransaction(ctx, tx, before); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, userID, models.EventTransactionDeleted, map[string]uuid.UUID{"id": transactionID}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// loadTags fills the Tags field of the given transactions
+func (r *PostgresTransactionRepo) loadTags(ctx context.Context, transactions []*models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*models.Transaction, len(transactions))
+	ids := make([]uuid.UUID, 0, len(transactions))
+	for _, t := range transactions {
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+
+	sql := `SELECT tt.transaction_id, tg.id, tg.user_id, tg.name, tg.created_at
+			FROM transaction_tags tt
+			INNER JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tt.transaction_id = ANY($1)
+			ORDER BY tg.name ASC`
+
+	rows, err := r.DB.Query(ctx, sql, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID uuid.UUID
+		tag := &models.Tag{}
+		if err := rows.Scan(&transactionID, &tag.ID, &tag.UserId, &tag.Name, &tag.CreatedAt); err != nil {
+			return err
+		}
+		if t, ok := byID[transactionID]; ok {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+
+	return rows.Err()
+}
+
+func tagIDs(tags []*models.Tag) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	return ids
+}
+
 func (r *PostgresTransactionRepo) GetSummaryByType(ctx context.Context, userID uuid.UUID) (map[string]int64, error) {
 	sql := `SELECT
 					c.type, COALESCE(SUM(t.amount), 0)
 			FROM transactions t
 			JOIN categories c ON t.category_id = c.id
-			WHERE t.user_id = $1
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL
 			GROUP BY c.type
 	`
 
@@ -116,7 +345,7 @@ func (r *PostgresTransactionRepo) GetPeriodicStats(ctx context.Context, userID u
                 	COALESCE(SUM(CASE WHEN c.type = 'expense' THEN amount ELSE 0 END), 0)::bigint as expense
 			FROM transactions t
 			LEFT JOIN categories c ON t.category_id = c.id
-			WHERE t.user_id = $1
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL
 			GROUP by period
 			ORDER BY period ASC`
 
@@ -148,3 +377,47 @@ func (r *PostgresTransactionRepo) GetPeriodicStats(ctx context.Context, userID u
 
 	return statsByPeriods, nil
 }
+
+func (r *PostgresTransactionRepo) GetCategoryStats(ctx context.Context, userID uuid.UUID, from time.Time) ([]*models.CategoryStat, error) {
+	sql := `SELECT
+					TO_CHAR(DATE_TRUNC('month', t.date), 'YYYY-MM-DD') as period,
+					c.id,
+					c.name,
+					c.type,
+					COALESCE(SUM(t.amount), 0)::bigint as amount
+			FROM transactions t
+			INNER JOIN categories c ON t.category_id = c.id
+			WHERE t.user_id = $1 AND t.date >= $2 AND t.deleted_at IS NULL
+			GROUP BY period, c.id, c.name, c.type
+			ORDER BY period ASC, c.name ASC`
+
+	rows, err := r.DB.Query(ctx, sql, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*models.CategoryStat
+
+	for rows.Next() {
+		s := &models.CategoryStat{}
+
+		if err := rows.Scan(
+			&s.Period,
+			&s.CategoryId,
+			&s.CategoryName,
+			&s.Type,
+			&s.Amount,
+		); err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
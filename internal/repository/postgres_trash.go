@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+// trashTransaction moves a transaction locked by lockTransaction to the trash
+// and records it in the audit log
+func trashTransaction(ctx context.Context, tx pgx.Tx, t *models.Transaction) error {
+	if _, err := tx.Exec(ctx,
+		`UPDATE transactions SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND user_id = $2`,
+		t.ID, t.UserId,
+	); err != nil {
+		return err
+	}
+	_, err := auditTransaction(ctx, tx, t.UserId, models.AuditActionDelete, t.ID, t)
+	return err
+}
+
+// restoreCategory takes a category out of the trash within tx. A live
+// category with the same name fails the unique index (23505).
+func restoreCategory(ctx context.Context, tx pgx.Tx, userID, categoryID uuid.UUID) (*models.Category, error) {
+	c := &models.Category{}
+	err := tx.QueryRow(ctx,
+		`UPDATE categories SET deleted_at = NULL, version = version + 1
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+		 RETURNING id, user_id, name, type, created_at, version`,
+		categoryID, userID,
+	).Scan(&c.ID, &c.UserId, &c.Name, &c.Type, &c.CreatedAt, &c.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, userID, models.EventCategoryRestored, c); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, userID, models.AuditActionRestore, models.AuditEntityCategory, c.ID, nil, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type PostgresTrashRepo struct {
+	DB DBTX
+}
+
+func (r *PostgresTrashRepo) ListDeletedTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error) {
+	sql := `SELECT
+					t.id,
+					t.amount,
+					t.description,
+					t.date,
+					t.created_at,
+					t.category_id,
+					c.name as category_name,
+					c.type as type,
+					t.external_id,
+					t.deleted_at
+			FROM transactions t
+			INNER JOIN categories c ON t.category_id = c.id
+			WHERE t.user_id = $1 AND t.deleted_at IS NOT NULL
+			ORDER BY t.deleted_at DESC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*models.Transaction{}
+
+	for rows.Next() {
+		t := &models.Transaction{UserId: userID, Tags: []*models.Tag{}}
+		if err := rows.Scan(
+			&t.ID,
+			&t.Amount,
+			&t.Description,
+			&t.Date,
+			&t.CreatedAt,
+			&t.CategoryId,
+			&t.CategoryName,
+			&t.Type,
+			&t.ExternalID,
+			&t.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := (&PostgresTransactionRepo{DB: r.DB}).loadTags(ctx, transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *PostgresTrashRepo) ListDeletedCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error) {
+	sql := `SELECT id, name, type, created_at, deleted_at
+			FROM categories
+			WHERE user_id = $1 AND deleted_at IS NOT NULL
+			ORDER BY deleted_at DESC`
+
+	rows, err := r.DB.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*models.Category{}
+
+	for rows.Next() {
+		c := &models.Category{UserId: userID}
+		if err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.CreatedAt, &c.DeletedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// RestoreTransaction fails the unique index (23505) when the transaction's
+// external ID was imported again while it sat in the trash.
+func (r *PostgresTrashRepo) RestoreTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// 1. Lock the deleted transaction
+	var categoryID uuid.UUID
+	var categoryDeleted bool
+	err = tx.QueryRow(ctx,
+		`SELECT t.category_id, c.deleted_at IS NOT NULL
+		 FROM transactions t
+		 INNER JOIN categories c ON t.category_id = c.id
+		 WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NOT NULL
+		 FOR UPDATE OF t`,
+		transactionID, userID,
+	).Scan(&categoryID, &categoryDeleted)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	// 2. A transaction cannot live in a deleted category, bring it back too
+	if categoryDeleted {
+		if _, err := restoreCategory(ctx, tx, userID, categoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Restore
+	if _, err := tx.Exec(ctx,
+		`UPDATE transactions SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND user_id = $2`,
+		transactionID, userID,
+	); err != nil {
+		return nil, err
+	}
+	t, err := auditTransaction(ctx, tx, userID, models.AuditActionRestore, transactionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordEvent(ctx, tx, userID, models.EventTransactionRestored, t); err != nil {
+		return nil, err
+	}
+
+	return t, tx.Commit(ctx)
+}
+
+func (r *PostgresTrashRepo) RestoreCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	c, err := restoreCategory(ctx, tx, userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, tx.Commit(ctx)
+}
+
+func (r *PostgresTrashRepo) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, []string, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// 1. Receipts go with their transaction, the caller removes the blobs
+	rows, err := tx.Query(ctx,
+		`DELETE FROM attachments
+		 WHERE transaction_id IN (SELECT id FROM transactions WHERE deleted_at < $1)
+		 RETURNING storage_key`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	storageKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 2. Transactions, cascading to their tag links and duplicate candidates
+	cmd, err := tx.Exec(ctx, `DELETE FROM transactions WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		return 0, nil, err
+	}
+	purged := cmd.RowsAffected()
+
+	// 3. Categories, once no transaction (deleted later) refers to them anymore
+	cmd, err = tx.Exec(ctx,
+		`DELETE FROM categories c
+		 WHERE c.deleted_at < $1
+		 AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.category_id = c.id)`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	purged += cmd.RowsAffected()
+
+	return purged, storageKeys, tx.Commit(ctx)
+}
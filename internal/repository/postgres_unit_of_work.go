@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is what the Postgres repositories run their queries on: the pool, or
+// the pgx.Tx of a unit of work. Begin on a pgx.Tx starts a savepoint, so the
+// repository methods that need their own transaction nest inside the unit.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type PostgresUnitOfWork struct {
+	DB *pgxpool.Pool
+}
+
+func (u *PostgresUnitOfWork) Do(ctx context.Context, fn func(repos *Repositories) error) error {
+	return pgx.BeginFunc(ctx, u.DB, func(tx pgx.Tx) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+// newRepositories binds every repository to db
+func newRepositories(db DBTX) *Repositories {
+	return &Repositories{
+		Transactions:  &PostgresTransactionRepo{DB: db},
+		Categories:    &PostgresCategoryRepo{DB: db},
+		Users:         &PostgresUserRepo{DB: db},
+		Tags:          &PostgresTagRepo{DB: db},
+		Attachments:   &PostgresAttachmentRepo{DB: db},
+		Rules:         &PostgresRuleRepo{DB: db},
+		Duplicates:    &PostgresDuplicateRepo{DB: db},
+		BankAccounts:  &PostgresBankAccountRepo{DB: db},
+		Goals:         &PostgresGoalRepo{DB: db},
+		Budgets:       &PostgresBudgetRepo{DB: db},
+		Notifications: &PostgresNotificationRepo{DB: db},
+		Webhooks:      &PostgresWebhookRepo{DB: db},
+		Jobs:          &PostgresJobRepo{DB: db},
+		Outbox:        &PostgresOutboxRepo{DB: db},
+		Trash:         &PostgresTrashRepo{DB: db},
+		Audit:         &PostgresAuditRepo{DB: db},
+		Idempotency:   &PostgresIdempotencyRepo{DB: db},
+	}
+}
@@ -4,13 +4,13 @@ import (
 	"context"
 	"errors"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/olmits/budget-tracker-backend/internal/models"
 )
 
 type PostgresUserRepo struct {
-	DB *pgxpool.Pool
+	DB DBTX
 }
 
 func (r *PostgresUserRepo) CreateUser(ctx context.Context, user *models.User) error {
@@ -32,3 +32,27 @@ func (r *PostgresUserRepo) GetUserByEmail(ctx context.Context, email string) (*m
 	}
 	return user, nil
 }
+
+func (r *PostgresUserRepo) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.DB.Query(ctx, `SELECT id FROM users ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+type PostgresWebhookRepo struct {
+	DB DBTX
+}
+
+const webhookEndpointColumns = `id, user_id, url, secret, events, enabled, created_at`
+
+func scanWebhookEndpoint(row pgx.Row) (*models.WebhookEndpoint, error) {
+	e := &models.WebhookEndpoint{}
+	err := row.Scan(&e.ID, &e.UserId, &e.URL, &e.Secret, &e.Events, &e.Enabled, &e.CreatedAt)
+	return e, err
+}
+
+const webhookDeliveryColumns = `id, endpoint_id, user_id, event_id, event, payload, status, attempts,
+						next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+	err := row.Scan(
+		&d.ID, &d.EndpointId, &d.UserId, &d.EventId, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	)
+	return d, err
+}
+
+func (r *PostgresWebhookRepo) CreateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	sql := `INSERT INTO webhook_endpoints (user_id, url, secret, events, enabled)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`
+	return r.DB.QueryRow(ctx, sql,
+		e.UserId, e.URL, e.Secret, e.Events, e.Enabled,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (r *PostgresWebhookRepo) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*models.WebhookEndpoint, error) {
+	sql := `SELECT ` + webhookEndpointColumns + `
+			FROM webhook_endpoints
+			WHERE user_id = $1
+			ORDER BY created_at ASC`
+	return r.queryEndpoints(ctx, sql, userID)
+}
+
+func (r *PostgresWebhookRepo) GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*models.WebhookEndpoint, error) {
+	sql := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1 AND user_id = $2`
+
+	e, err := scanWebhookEndpoint(r.DB.QueryRow(ctx, sql, endpointID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *PostgresWebhookRepo) UpdateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	sql := `UPDATE webhook_endpoints SET url = $1, events = $2, enabled = $3
+			WHERE id = $4 AND user_id = $5
+			RETURNING secret, created_at`
+
+	err := r.DB.QueryRow(ctx, sql,
+		e.URL, e.Events, e.Enabled, e.ID, e.UserId,
+	).Scan(&e.Secret, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *PostgresWebhookRepo) DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND user_id = $2`, endpointID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWebhookRepo) ListSubscribers(ctx context.Context, userID uuid.UUID, event string) ([]*models.WebhookEndpoint, error) {
+	sql := `SELECT ` + webhookEndpointColumns + `
+			FROM webhook_endpoints
+			WHERE user_id = $1 AND enabled AND (events = '{}' OR $2 = ANY(events))`
+	return r.queryEndpoints(ctx, sql, userID, event)
+}
+
+func (r *PostgresWebhookRepo) EnqueueDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	sql := `INSERT INTO webhook_deliveries (endpoint_id, user_id, event_id, event, payload, next_attempt_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (endpoint_id, event_id) DO NOTHING
+			RETURNING id, status, attempts, created_at`
+	err := r.DB.QueryRow(ctx, sql,
+		d.EndpointId, d.UserId, d.EventId, d.Event, d.Payload, d.NextAttemptAt,
+	).Scan(&d.ID, &d.Status, &d.Attempts, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAlreadyQueued
+	}
+	return err
+}
+
+func (r *PostgresWebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error) {
+	sql := `UPDATE webhook_deliveries SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= $1
+				ORDER BY next_attempt_at ASC
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + webhookDeliveryColumns
+	return r.queryDeliveries(ctx, sql, now, now.Add(lease), limit)
+}
+
+func (r *PostgresWebhookRepo) SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	sql := `UPDATE webhook_deliveries SET
+					status = $1, attempts = $2, next_attempt_at = $3,
+					last_status_code = $4, last_error = $5, delivered_at = $6
+			WHERE id = $7`
+	cmd, err := r.DB.Exec(ctx, sql,
+		d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWebhookRepo) ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	sql := `SELECT ` + webhookDeliveryColumns + `
+			FROM webhook_deliveries
+			WHERE endpoint_id = $1 AND user_id = $2
+			ORDER BY created_at DESC
+			LIMIT $3`
+	return r.queryDeliveries(ctx, sql, endpointID, userID, limit)
+}
+
+func (r *PostgresWebhookRepo) queryEndpoints(ctx context.Context, sql string, args ...any) ([]*models.WebhookEndpoint, error) {
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []*models.WebhookEndpoint{}
+
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *PostgresWebhookRepo) queryDeliveries(ctx context.Context, sql string, args ...any) ([]*models.WebhookDelivery, error) {
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// AlertService raises the notifications. As a sink of the outbox it checks
+// every created or changed expense for a budget overrun and unusual spending;
+// Run reminds users of the bills due tomorrow. The events and scans may repeat,
+// each alert carries a key so the user gets it once.
+type AlertService struct {
+	Notifications *NotificationService
+	TxRepo        repository.TransactionRepository
+	Users         repository.UserRepository // For the bill reminders
+	Envelopes     *EnvelopeService
+	Anomalies     *AnomalyService
+	Subscriptions *SubscriptionService
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (s *AlertService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// Publish checks the transaction of transaction.created, .updated and .restored events
+func (s *AlertService) Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error {
+	switch event.Type {
+	case models.EventTransactionCreated, models.EventTransactionUpdated, models.EventTransactionRestored:
+	default:
+		return nil
+	}
+
+	// Data is the transaction as it was written, check it as it is now
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	var ref struct {
+		ID uuid.UUID `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		return err
+	}
+	t, err := s.TxRepo.GetTransaction(ctx, userID, ref.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil // Deleted meanwhile
+		}
+		return err
+	}
+	if t.Type != "expense" || t.CategoryId == nil {
+		return nil
+	}
+
+	return errors.Join(s.checkBudget(ctx, t), s.checkAnomaly(ctx, t))
+}
+
+// checkBudget raises a budget overrun once per envelope and month, when the
+// envelope had money and the expense took it below zero
+func (s *AlertService) checkBudget(ctx context.Context, t *models.Transaction) error {
+	month, err := s.Envelopes.GetMonth(ctx, t.UserId, t.Date)
+	if err != nil {
+		return err
+	}
+	for _, e := range month.Envelopes {
+		if e.CategoryId != *t.CategoryId || e.Available >= 0 || e.Carryover+e.Assigned <= 0 {
+			continue
+		}
+		return s.Notifications.Notify(ctx, &models.Notification{
+			UserId: t.UserId,
+			Kind:   models.NotificationKindBudgetOverrun,
+			Title:  fmt.Sprintf("%s is over budget", e.CategoryName),
+			Body:   fmt.Sprintf("%s of %s spent in %s, %s more than the envelope had.", formatAmount(e.Activity), formatAmount(e.Carryover+e.Assigned), month.Month, formatAmount(-e.Available)),
+			Data: map[string]any{
+				"category_id":    e.CategoryId,
+				"month":          month.Month,
+				"available":      e.Available,
+				"transaction_id": t.ID,
+			},
+			Key: fmt.Sprintf("%s:%s:%s", models.NotificationKindBudgetOverrun, e.CategoryId, month.Month),
+		})
+	}
+	return nil
+}
+
+func (s *AlertService) checkAnomaly(ctx context.Context, t *models.Transaction) error {
+	a, err := s.Anomalies.CheckTransaction(ctx, t, DefaultAnomalyZ)
+	if err != nil || a == nil {
+		return err
+	}
+	return s.Notifications.Notify(ctx, &models.Notification{
+		UserId: t.UserId,
+		Kind:   models.NotificationKindAnomaly,
+		Title:  fmt.Sprintf("Unusual spending in %s", a.CategoryName),
+		Body:   fmt.Sprintf("%s: %s, usually %s.", a.Description, formatAmount(a.Amount), formatAmount(a.Baseline)),
+		Data: map[string]any{
+			"category_id":    a.CategoryId,
+			"transaction_id": t.ID,
+			"amount":         a.Amount,
+			"baseline":       a.Baseline,
+			"z_score":        a.ZScore,
+		},
+		Key: fmt.Sprintf("%s:%s", models.NotificationKindAnomaly, t.ID),
+	})
+}
+
+// RemindBills notifies every user of the recurring expenses expected tomorrow
+func (s *AlertService) RemindBills(ctx context.Context) error {
+	userIDs, err := s.Users.ListUserIDs(ctx)
+	if err != nil {
+		return err
+	}
+	tomorrow := dayOf(s.now()).AddDate(0, 0, 1)
+
+	var errs []error
+	for _, userID := range userIDs {
+		subscriptions, err := s.Subscriptions.ListSubscriptions(ctx, userID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", userID, err))
+			continue
+		}
+		for _, sub := range subscriptions {
+			if !dayOf(sub.NextDate).Equal(tomorrow) {
+				continue
+			}
+			due := tomorrow.Format("2006-01-02")
+			err := s.Notifications.Notify(ctx, &models.Notification{
+				UserId: userID,
+				Kind:   models.NotificationKindBillReminder,
+				Title:  fmt.Sprintf("%s due tomorrow", sub.Description),
+				Body:   fmt.Sprintf("%s expected on %s.", formatAmount(sub.CurrentAmount), due),
+				Data: map[string]any{
+					"category_id": sub.CategoryId,
+					"amount":      sub.CurrentAmount,
+					"due_date":    due,
+				},
+				Key: fmt.Sprintf("%s:%s:%s:%s", models.NotificationKindBillReminder, sub.CategoryId, due, sub.Description),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", userID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run sends the bill reminders every interval until ctx is done
+func (s *AlertService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.RemindBills(ctx); err != nil {
+			log.Printf("alerts: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatAmount writes cents as a decimal amount, e.g. 1234 as "12.34"
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memHistoryRepo serves the transactions of one user
+type memHistoryRepo struct {
+	repository.TransactionRepository
+	rows []*models.Transaction
+}
+
+func (r *memHistoryRepo) GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	for _, t := range r.rows {
+		if t.ID == transactionID {
+			return t, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memHistoryRepo) ListTransactions(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error) {
+	var out []*models.Transaction
+	for _, t := range r.rows {
+		if !t.Date.Before(filter.From) && t.Date.Before(filter.To) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+type memUserRepo struct {
+	repository.UserRepository
+	ids []uuid.UUID
+}
+
+func (r *memUserRepo) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	return r.ids, nil
+}
+
+func newAlertService(now time.Time, userID uuid.UUID, rows []*models.Transaction, budgets *memBudgetRepo, categories []*models.Category) (*AlertService, *memNotificationRepo) {
+	for _, t := range rows {
+		t.UserId = userID
+	}
+	txRepo := &memHistoryRepo{rows: rows}
+	notifications := &memNotificationRepo{}
+	clock := func() time.Time { return now }
+	return &AlertService{
+		Notifications: &NotificationService{Repo: notifications},
+		TxRepo:        txRepo,
+		Users:         &memUserRepo{ids: []uuid.UUID{userID}},
+		Envelopes:     &EnvelopeService{Repo: budgets, CategoryRepo: &memCategoryRepo{rows: categories}},
+		Anomalies:     &AnomalyService{TxRepo: txRepo, Now: clock},
+		Subscriptions: &SubscriptionService{TxRepo: txRepo, Now: clock},
+		Now:           clock,
+	}, notifications
+}
+
+func TestAlertServicePublish(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.July, 2, 10, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	groceries := &models.Category{ID: uuid.New(), Name: "Groceries", Type: "expense"}
+	salary := &models.Category{ID: uuid.New(), Name: "Salary", Type: "income"}
+
+	var rows []*models.Transaction
+	for i, day := range []time.Time{
+		time.Date(2026, time.May, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.May, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.June, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.June, 18, 0, 0, 0, 0, time.UTC),
+	} {
+		rows = append(rows, historyTx(groceries, "Supermarket", 4800+int64(i)*100, day))
+	}
+	big := historyTx(groceries, "Supermarket", 40000, time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC))
+	payday := historyTx(salary, "ACME Payroll", 300000, time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC))
+	rows = append(rows, big, payday)
+
+	july := MonthStart(now)
+	budgets := &memBudgetRepo{
+		assigned: []*models.MonthlyAmount{{CategoryId: groceries.ID, Month: july, Amount: 20000}},
+		activity: []*models.MonthlyAmount{{CategoryId: groceries.ID, Month: july, Amount: 40000}},
+	}
+	s, notifications := newAlertService(now, userID, rows, budgets, []*models.Category{groceries, salary})
+
+	event := func(eventType string, t *models.Transaction) *models.Event {
+		return &models.Event{ID: uuid.New(), Type: eventType, Data: json.RawMessage(`{"id":"` + t.ID.String() + `"}`)}
+	}
+
+	// Income and other events raise nothing
+	require.NoError(t, s.Publish(ctx, userID, event(models.EventTransactionCreated, payday)))
+	require.NoError(t, s.Publish(ctx, userID, event(models.EventTransactionDeleted, big)))
+	assert.Empty(t, notifications.rows)
+
+	require.NoError(t, s.Publish(ctx, userID, event(models.EventTransactionCreated, big)))
+	require.Len(t, notifications.rows, 2)
+	overrun, anomaly := notifications.rows[0], notifications.rows[1]
+	assert.Equal(t, models.NotificationKindBudgetOverrun, overrun.Kind)
+	assert.Equal(t, "Groceries is over budget", overrun.Title)
+	assert.Equal(t, int64(-20000), overrun.Data["available"])
+	assert.Equal(t, models.NotificationKindAnomaly, anomaly.Kind)
+	assert.Equal(t, big.ID, anomaly.Data["transaction_id"])
+
+	// Replayed or later events for the same overrun and expense are not raised again
+	require.NoError(t, s.Publish(ctx, userID, event(models.EventTransactionUpdated, big)))
+	assert.Len(t, notifications.rows, 2)
+
+	// Within budget, nothing to report
+	budgets.assigned[0].Amount = 50000
+	notifications.rows = nil
+	big.Amount = 5000
+	require.NoError(t, s.Publish(ctx, userID, event(models.EventTransactionUpdated, big)))
+	assert.Empty(t, notifications.rows)
+}
+
+func TestAlertServiceRemindBills(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	media := &models.Category{ID: uuid.New(), Name: "Media", Type: "expense"}
+
+	var rows []*models.Transaction
+	for m := time.January; m <= time.June; m++ {
+		rows = append(rows, historyTx(media, "NETFLIX.COM", 1199, time.Date(2026, m, 3, 0, 0, 0, 0, time.UTC)))
+	}
+
+	now := time.Date(2026, time.July, 1, 8, 0, 0, 0, time.UTC)
+	s, notifications := newAlertService(now, userID, rows, &memBudgetRepo{}, []*models.Category{media})
+
+	// Two days ahead: too early
+	require.NoError(t, s.RemindBills(ctx))
+	assert.Empty(t, notifications.rows)
+
+	s.Now = func() time.Time { return now.AddDate(0, 0, 1) }
+	s.Subscriptions.Now = s.Now
+	require.NoError(t, s.RemindBills(ctx))
+	require.NoError(t, s.RemindBills(ctx))
+	require.Len(t, notifications.rows, 1)
+	assert.Equal(t, models.NotificationKindBillReminder, notifications.rows[0].Kind)
+	assert.Equal(t, "NETFLIX.COM due tomorrow", notifications.rows[0].Title)
+	assert.Equal(t, "11.99 expected on 2026-07-03.", notifications.rows[0].Body)
+}
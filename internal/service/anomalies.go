@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	// DefaultAnomalyZ flags spending 2.5 standard deviations above the mean
+	DefaultAnomalyZ = 2.5
+
+	anomalyBaselineMonths          = 6   // Rolling window of the monthly baseline
+	anomalyMinBaselineMonths       = 3   // Months of history needed before judging a month
+	anomalyFeedMonths              = 6   // Months checked for anomalies, the current one included
+	anomalyBaselineDays            = 180 // Rolling window of the per-transaction baseline
+	anomalyMinBaselineTx           = 5   // Earlier transactions needed before judging one
+	anomalyMinStdDevShare          = 0.1 // Floor of the deviation, as a share of the mean
+	anomalyMinStdDev         int64 = 100 // Floor of the deviation in cents
+)
+
+// AnomalyService flags unusual spending per expense category
+type AnomalyService struct {
+	TxRepo repository.TransactionRepository
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+// ListAnomalies checks the expense transactions of the last "days" days and
+// the last months against their category's rolling baseline, newest first
+func (s *AnomalyService) ListAnomalies(ctx context.Context, userID uuid.UUID, z float64, days int) ([]*models.Anomaly, error) {
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	today := dayOf(now)
+
+	firstMonth := MonthStart(today).AddDate(0, -(anomalyFeedMonths + anomalyBaselineMonths - 1), 0)
+	stats, err := s.TxRepo.GetCategoryStats(ctx, userID, firstMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	since := today.AddDate(0, 0, -days+1)
+	history, err := s.TxRepo.ListTransactions(ctx, userID, models.TransactionFilter{
+		From: since.AddDate(0, 0, -anomalyBaselineDays),
+		To:   today.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := append(MonthlyAnomalies(stats, today, z), TransactionAnomalies(history, since, z)...)
+	slices.SortStableFunc(anomalies, func(a, b *models.Anomaly) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Kind, b.Kind)
+	})
+	return anomalies, nil
+}
+
+// CheckTransaction scores one expense against the preceding 180 days of its
+// category. It returns nil when the expense is not unusual.
+func (s *AnomalyService) CheckTransaction(ctx context.Context, t *models.Transaction, z float64) (*models.Anomaly, error) {
+	if t.Type != "expense" || t.CategoryId == nil {
+		return nil, nil
+	}
+	day := dayOf(t.Date)
+	history, err := s.TxRepo.ListTransactions(ctx, t.UserId, models.TransactionFilter{
+		From: day.AddDate(0, 0, -anomalyBaselineDays),
+		To:   day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range TransactionAnomalies(history, day, z) {
+		if *a.TransactionId == t.ID {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// MonthlyAnomalies compares the expense total of each of the last months with the
+// previous months of the same category. Months without spending count as zero
+// once the category has been used. The current month is partial, it can only
+// be flagged once it has already gone over.
+func MonthlyAnomalies(stats []*models.CategoryStat, now time.Time, z float64) []*models.Anomaly {
+	current := MonthStart(now)
+
+	type series struct {
+		name   string
+		first  time.Time
+		totals map[time.Time]int64
+	}
+	categories := make(map[uuid.UUID]*series)
+	for _, st := range stats {
+		month, err := time.Parse("2006-01-02", st.Period)
+		if err != nil || st.Type != "expense" {
+			continue
+		}
+		s, ok := categories[st.CategoryId]
+		if !ok {
+			s = &series{name: st.CategoryName, first: month, totals: make(map[time.Time]int64)}
+			categories[st.CategoryId] = s
+		}
+		if month.Before(s.first) {
+			s.first = month
+		}
+		s.totals[month] += st.Amount
+	}
+
+	anomalies := []*models.Anomaly{}
+	for id, s := range categories {
+		for i := anomalyFeedMonths - 1; i >= 0; i-- {
+			month := current.AddDate(0, -i, 0)
+
+			var baseline []float64
+			for j := anomalyBaselineMonths; j >= 1; j-- {
+				prev := month.AddDate(0, -j, 0)
+				if !prev.Before(s.first) {
+					baseline = append(baseline, float64(s.totals[prev]))
+				}
+			}
+			if len(baseline) < anomalyMinBaselineMonths {
+				continue
+			}
+
+			amount := s.totals[month]
+			if a := scoreAnomaly(amount, baseline, z); a != nil {
+				a.Kind = models.AnomalyKindMonth
+				a.Date = month
+				a.CategoryId = id
+				a.CategoryName = s.name
+				anomalies = append(anomalies, a)
+			}
+		}
+	}
+	return anomalies
+}
+
+// TransactionAnomalies compares each expense dated from "since" with the
+// transactions of its category in the preceding 180 days
+func TransactionAnomalies(history []*models.Transaction, since time.Time, z float64) []*models.Anomaly {
+	byCategory := make(map[uuid.UUID][]*models.Transaction)
+	for _, t := range history {
+		if t.Type == "expense" && t.CategoryId != nil {
+			byCategory[*t.CategoryId] = append(byCategory[*t.CategoryId], t)
+		}
+	}
+
+	anomalies := []*models.Anomaly{}
+	for _, txs := range byCategory {
+		slices.SortFunc(txs, func(a, b *models.Transaction) int { return a.Date.Compare(b.Date) })
+
+		for i, t := range txs {
+			day := dayOf(t.Date)
+			if day.Before(since) {
+				continue
+			}
+
+			windowStart := day.AddDate(0, 0, -anomalyBaselineDays)
+			var baseline []float64
+			for _, prev := range txs[:i] {
+				if !dayOf(prev.Date).Before(windowStart) {
+					baseline = append(baseline, float64(prev.Amount))
+				}
+			}
+			if len(baseline) < anomalyMinBaselineTx {
+				continue
+			}
+
+			if a := scoreAnomaly(t.Amount, baseline, z); a != nil {
+				id := t.ID
+				a.Kind = models.AnomalyKindTransaction
+				a.Date = day
+				a.CategoryId = *t.CategoryId
+				a.CategoryName = t.CategoryName
+				a.TransactionId = &id
+				a.Description = t.Description
+				anomalies = append(anomalies, a)
+			}
+		}
+	}
+	return anomalies
+}
+
+// scoreAnomaly returns an anomaly when amount is at least z deviations above
+// the baseline mean. The deviation has a floor so that a perfectly steady
+// baseline does not turn every small difference into an alert.
+func scoreAnomaly(amount int64, baseline []float64, z float64) *models.Anomaly {
+	var sum float64
+	for _, v := range baseline {
+		sum += v
+	}
+	mean := sum / float64(len(baseline))
+
+	var variance float64
+	for _, v := range baseline {
+		variance += (v - mean) * (v - mean)
+	}
+	stddev := math.Max(math.Sqrt(variance/float64(len(baseline))), math.Max(mean*anomalyMinStdDevShare, float64(anomalyMinStdDev)))
+
+	score := (float64(amount) - mean) / stddev
+	if score < z {
+		return nil
+	}
+	return &models.Anomaly{
+		Amount:   amount,
+		Baseline: int64(math.Round(mean)),
+		StdDev:   int64(math.Round(stddev)),
+		ZScore:   math.Round(score*100) / 100,
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyAnomalies(t *testing.T) {
+	now := time.Date(2026, time.June, 20, 0, 0, 0, 0, time.UTC)
+	dining := uuid.New()
+	travel := uuid.New()
+	stat := func(period string, category uuid.UUID, name string, amount int64) *models.CategoryStat {
+		return &models.CategoryStat{Period: period, CategoryId: category, CategoryName: name, Type: "expense", Amount: amount}
+	}
+
+	stats := []*models.CategoryStat{
+		stat("2025-12-01", dining, "Dining", 20000),
+		stat("2026-01-01", dining, "Dining", 22000),
+		stat("2026-02-01", dining, "Dining", 18000),
+		stat("2026-03-01", dining, "Dining", 21000),
+		stat("2026-04-01", dining, "Dining", 19000),
+		stat("2026-05-01", dining, "Dining", 45000), // Way over
+		stat("2026-06-01", dining, "Dining", 20000),
+		// Too little history to judge
+		stat("2026-04-01", travel, "Travel", 1000),
+		stat("2026-06-01", travel, "Travel", 90000),
+		{Period: "2026-05-01", CategoryId: uuid.New(), Type: "income", Amount: 900000},
+	}
+
+	anomalies := MonthlyAnomalies(stats, now, DefaultAnomalyZ)
+	require.Len(t, anomalies, 1)
+	a := anomalies[0]
+	assert.Equal(t, models.AnomalyKindMonth, a.Kind)
+	assert.Equal(t, time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC), a.Date)
+	assert.Equal(t, "Dining", a.CategoryName)
+	assert.Equal(t, int64(45000), a.Amount)
+	assert.Equal(t, int64(20000), a.Baseline)
+	assert.Greater(t, a.ZScore, DefaultAnomalyZ)
+
+	// A lower threshold flags more
+	assert.Len(t, MonthlyAnomalies(stats[:7], now, 0.5), 2)
+}
+
+func TestTransactionAnomalies(t *testing.T) {
+	groceries := &models.Category{ID: uuid.New(), Name: "Groceries", Type: "expense"}
+	since := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	var history []*models.Transaction
+	for i, amount := range []int64{5200, 4800, 5100, 4900, 5000, 5300} {
+		history = append(history, historyTx(groceries, "Supermarket", amount, time.Date(2026, time.May, 1+4*i, 0, 0, 0, 0, time.UTC)))
+	}
+	normal := historyTx(groceries, "Supermarket", 5500, time.Date(2026, time.June, 3, 0, 0, 0, 0, time.UTC))
+	big := historyTx(groceries, "Wine & Cheese Shop", 18000, time.Date(2026, time.June, 5, 0, 0, 0, 0, time.UTC))
+	history = append(history, normal, big)
+
+	anomalies := TransactionAnomalies(history, since, DefaultAnomalyZ)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, models.AnomalyKindTransaction, anomalies[0].Kind)
+	assert.Equal(t, big.ID, *anomalies[0].TransactionId)
+	assert.Equal(t, "Wine & Cheese Shop", anomalies[0].Description)
+}
+
+func TestAnomalyServiceFeed(t *testing.T) {
+	now := time.Date(2026, time.June, 20, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	groceries := &models.Category{ID: uuid.New(), Name: "Groceries", Type: "expense"}
+
+	var history []*models.Transaction
+	for i := 0; i < 6; i++ {
+		history = append(history, historyTx(groceries, "Supermarket", 5000, time.Date(2026, time.May, 1+5*i, 0, 0, 0, 0, time.UTC)))
+	}
+	history = append(history, historyTx(groceries, "Supermarket", 30000, time.Date(2026, time.June, 18, 0, 0, 0, 0, time.UTC)))
+
+	var stats []*models.CategoryStat
+	for m := time.January; m <= time.May; m++ {
+		stats = append(stats, &models.CategoryStat{
+			Period: time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), CategoryId: groceries.ID,
+			CategoryName: groceries.Name, Type: "expense", Amount: 30000,
+		})
+	}
+	stats = append(stats, &models.CategoryStat{Period: "2026-06-01", CategoryId: groceries.ID, CategoryName: groceries.Name, Type: "expense", Amount: 60000})
+
+	repo := new(MockRepo)
+	repo.On("GetCategoryStats", mock.Anything, userID, time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)).Return(stats, nil)
+	repo.On("ListTransactions", mock.Anything, userID, mock.Anything).Return(history, nil)
+
+	s := &AnomalyService{TxRepo: repo, Now: func() time.Time { return now }}
+	anomalies, err := s.ListAnomalies(context.Background(), userID, DefaultAnomalyZ, 30)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2)
+	assert.Equal(t, models.AnomalyKindTransaction, anomalies[0].Kind) // June 18th
+	assert.Equal(t, models.AnomalyKindMonth, anomalies[1].Kind)       // June 1st
+	repo.AssertExpectations(t)
+}
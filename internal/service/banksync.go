@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/importer"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/banking"
+)
+
+// Stops a misbehaving provider from paging forever
+const maxSyncPages = 1000
+
+// ErrUnknownProvider is returned for provider names that are not configured
+var ErrUnknownProvider = errors.New("unknown bank provider")
+
+// BankSyncService links accounts through bank providers and pulls their
+// transactions into the import pipeline. Each account keeps the provider's
+// cursor, so a sync only fetches what changed since the previous one.
+type BankSyncService struct {
+	Repo      repository.BankAccountRepository
+	Import    *ImportService
+	Providers map[string]banking.BankProvider // Key: provider name
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (s *BankSyncService) provider(name string) (banking.BankProvider, error) {
+	p, ok := s.Providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	return p, nil
+}
+
+func (s *BankSyncService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// LinkAccounts stores the accounts a link token gives access to. Linking the
+// same account again refreshes it and keeps its sync position.
+func (s *BankSyncService) LinkAccounts(ctx context.Context, userID uuid.UUID, providerName, token string) ([]*models.BankAccount, error) {
+	p, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := p.LinkAccount(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	accounts := make([]*models.BankAccount, 0, len(linked))
+	for _, l := range linked {
+		a := &models.BankAccount{
+			UserId:           userID,
+			Provider:         p.Name(),
+			ExternalID:       l.ID,
+			Name:             l.Name,
+			Mask:             l.Mask,
+			Currency:         l.Currency,
+			Balance:          l.Balance,
+			BalanceUpdatedAt: &now,
+		}
+		if err := s.Repo.UpsertAccount(ctx, a); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+// Sync fetches every page after the stored cursor and upserts the booked
+// transactions by external ID ("<provider>:<transaction ID>"). Pending ones
+// are left out until booked. The cursor is saved after each page, so an
+// interrupted sync resumes where it stopped.
+func (s *BankSyncService) Sync(ctx context.Context, userID, accountID uuid.UUID) (*models.BankAccount, *models.ImportReport, error) {
+	account, err := s.Repo.GetAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := s.provider(account.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := &models.ImportReport{Format: account.Provider, Errors: []*models.ImportError{}}
+	cursor := account.Cursor
+
+	for pages := 0; ; pages++ {
+		if pages == maxSyncPages {
+			return nil, nil, fmt.Errorf("provider %s returned more than %d pages", p.Name(), maxSyncPages)
+		}
+
+		page, err := p.FetchTransactions(ctx, account.ExternalID, cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		report, err := s.Import.Upsert(ctx, userID, syncBatch(p.Name(), page))
+		if err != nil {
+			return nil, nil, err
+		}
+		mergeReport(total, report)
+
+		cursor = page.NextCursor
+		syncedAt := s.now()
+		if err := s.Repo.UpdateCursor(ctx, userID, account.ID, cursor, syncedAt); err != nil {
+			return nil, nil, err
+		}
+		account.Cursor, account.LastSyncedAt = cursor, &syncedAt
+
+		if !page.HasMore {
+			break
+		}
+	}
+
+	return account, total, nil
+}
+
+// RefreshBalances updates the balance of every linked account, one provider call per provider
+func (s *BankSyncService) RefreshBalances(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error) {
+	accounts, err := s.Repo.ListAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byProvider := make(map[string][]*models.BankAccount)
+	for _, a := range accounts {
+		byProvider[a.Provider] = append(byProvider[a.Provider], a)
+	}
+
+	for name, group := range byProvider {
+		p, err := s.provider(name)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, 0, len(group))
+		byExternalID := make(map[string]*models.BankAccount, len(group))
+		for _, a := range group {
+			ids = append(ids, a.ExternalID)
+			byExternalID[a.ExternalID] = a
+		}
+
+		balances, err := p.RefreshBalances(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range balances {
+			a, ok := byExternalID[b.AccountID]
+			if !ok {
+				continue
+			}
+			if err := s.Repo.UpdateBalance(ctx, userID, a.ID, b.Current, b.AsOf); err != nil {
+				return nil, err
+			}
+			asOf := b.AsOf
+			a.Balance, a.BalanceUpdatedAt = b.Current, &asOf
+		}
+	}
+
+	return accounts, nil
+}
+
+// syncBatch turns a provider page into import records
+func syncBatch(provider string, page *banking.TransactionPage) *importer.Batch {
+	batch := &importer.Batch{Format: provider}
+	for _, t := range page.Transactions {
+		if t.Pending {
+			continue
+		}
+		batch.Records = append(batch.Records, &importer.Record{
+			ExternalID:  provider + ":" + t.ID,
+			Date:        t.Date,
+			Amount:      t.Amount,
+			Description: t.Description,
+			Category:    t.Category,
+		})
+	}
+	return batch
+}
+
+func mergeReport(total, page *models.ImportReport) {
+	offset := total.Total
+	total.Total += page.Total
+	total.Created += page.Created
+	total.Updated += page.Updated
+	total.Skipped += page.Skipped
+	total.Duplicates += page.Duplicates
+	total.Failed += page.Failed
+	total.CategoriesCreated = append(total.CategoriesCreated, page.CategoriesCreated...)
+
+	// Entries are numbered across the whole sync
+	for _, e := range page.Errors {
+		e.Entry += offset
+		total.Errors = append(total.Errors, e)
+	}
+}
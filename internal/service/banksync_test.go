@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/banking"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memTxRepo keeps transactions in memory for pipeline tests
+type memTxRepo struct {
+	repository.TransactionRepository // Unused methods panic
+	rows                             map[uuid.UUID]*models.Transaction
+}
+
+func (r *memTxRepo) CreateTransaction(ctx context.Context, t *models.Transaction) error {
+	t.ID = uuid.New()
+	copied := *t
+	r.rows[t.ID] = &copied
+	return nil
+}
+
+func (r *memTxRepo) GetTransactionByExternalID(ctx context.Context, userID uuid.UUID, externalID string) (*models.Transaction, error) {
+	for _, t := range r.rows {
+		if t.UserId == userID && t.ExternalID != nil && *t.ExternalID == externalID {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memTxRepo) UpdateTransaction(ctx context.Context, t *models.Transaction) error {
+	copied := *t
+	r.rows[t.ID] = &copied
+	return nil
+}
+
+type memCategoryRepo struct {
+	repository.CategoryRepository
+	rows []*models.Category
+}
+
+func (r *memCategoryRepo) CreateCategory(ctx context.Context, c *models.Category) error {
+	c.ID = uuid.New()
+	r.rows = append(r.rows, c)
+	return nil
+}
+
+func (r *memCategoryRepo) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error) {
+	for _, c := range r.rows {
+		if c.ID == categoryID {
+			return c, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memCategoryRepo) ListCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error) {
+	return r.rows, nil
+}
+
+func (r *memCategoryRepo) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, versions []int, reassignTo *uuid.UUID) error {
+	return nil // Not used in these tests
+}
+
+type memBankAccountRepo struct {
+	repository.BankAccountRepository
+	rows    map[uuid.UUID]*models.BankAccount
+	history []bankBalance
+}
+
+type bankBalance struct {
+	accountID uuid.UUID
+	asOf      time.Time
+	balance   int64
+}
+
+func (r *memBankAccountRepo) UpsertAccount(ctx context.Context, a *models.BankAccount) error {
+	for _, existing := range r.rows {
+		if existing.Provider == a.Provider && existing.ExternalID == a.ExternalID {
+			a.ID, a.Cursor = existing.ID, existing.Cursor
+		}
+	}
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	copied := *a
+	r.rows[a.ID] = &copied
+	if a.BalanceUpdatedAt != nil {
+		r.history = append(r.history, bankBalance{a.ID, *a.BalanceUpdatedAt, a.Balance})
+	}
+	return nil
+}
+
+func (r *memBankAccountRepo) ListAccounts(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error) {
+	var accounts []*models.BankAccount
+	for _, a := range r.rows {
+		copied := *a
+		accounts = append(accounts, &copied)
+	}
+	return accounts, nil
+}
+
+func (r *memBankAccountRepo) GetAccount(ctx context.Context, userID, accountID uuid.UUID) (*models.BankAccount, error) {
+	a, ok := r.rows[accountID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (r *memBankAccountRepo) UpdateCursor(ctx context.Context, userID, accountID uuid.UUID, cursor string, syncedAt time.Time) error {
+	r.rows[accountID].Cursor = cursor
+	r.rows[accountID].LastSyncedAt = &syncedAt
+	return nil
+}
+
+func (r *memBankAccountRepo) UpdateBalance(ctx context.Context, userID, accountID uuid.UUID, balance int64, asOf time.Time) error {
+	r.rows[accountID].Balance = balance
+	r.history = append(r.history, bankBalance{accountID, asOf, balance})
+	return nil
+}
+
+func (r *memBankAccountRepo) BalanceAt(ctx context.Context, userID, accountID uuid.UUID, at time.Time) (int64, error) {
+	var before, after *bankBalance
+	for i, b := range r.history {
+		switch {
+		case b.accountID != accountID:
+		case !b.asOf.After(at):
+			if before == nil || b.asOf.After(before.asOf) {
+				before = &r.history[i]
+			}
+		case after == nil || b.asOf.Before(after.asOf):
+			after = &r.history[i]
+		}
+	}
+	if before != nil {
+		return before.balance, nil
+	}
+	if after != nil {
+		return after.balance, nil
+	}
+	return 0, repository.ErrNotFound
+}
+
+func TestBankSyncService(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	fake := &banking.FakeProvider{
+		Start:    time.Date(2026, time.February, 25, 0, 0, 0, 0, time.UTC),
+		Now:      func() time.Time { return now },
+		PageSize: 4,
+	}
+	txRepo := &memTxRepo{rows: map[uuid.UUID]*models.Transaction{}}
+	bankRepo := &memBankAccountRepo{rows: map[uuid.UUID]*models.BankAccount{}}
+	s := &BankSyncService{
+		Repo:      bankRepo,
+		Import:    &ImportService{TxRepo: txRepo, CategoryRepo: &memCategoryRepo{}},
+		Providers: map[string]banking.BankProvider{"fake": fake},
+		Now:       func() time.Time { return now },
+	}
+
+	accounts, err := s.LinkAccounts(ctx, userID, "fake", "fake-alice")
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	checking := accounts[0]
+
+	// First sync: 9 days of card payments plus the salary, today is still pending
+	account, report, err := s.Sync(ctx, userID, checking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 9, report.Created)
+	assert.Equal(t, 0, report.Failed)
+	assert.Len(t, txRepo.rows, 9)
+	assert.Contains(t, report.CategoriesCreated, "Salary")
+	assert.Equal(t, "8", account.Cursor)
+
+	// Nothing new the same day
+	_, report, err = s.Sync(ctx, userID, checking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Created)
+
+	// A day later: yesterday's payment got booked, the bank corrected an older one
+	page, err := fake.FetchTransactions(ctx, "fake-alice:checking", "")
+	require.NoError(t, err)
+	corrected := page.Transactions[0]
+	fake.Amend(corrected.ID, corrected.Amount-100)
+	now = now.AddDate(0, 0, 1)
+
+	_, report, err = s.Sync(ctx, userID, checking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Len(t, txRepo.rows, 10)
+
+	// Corrections only arrive with a full re-sync
+	bankRepo.rows[checking.ID].Cursor = ""
+	_, report, err = s.Sync(ctx, userID, checking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	updated, err := txRepo.GetTransactionByExternalID(ctx, userID, "fake:"+corrected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, -(corrected.Amount - 100), updated.Amount)
+
+	// Balances
+	refreshed, err := s.RefreshBalances(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, refreshed, 2)
+
+	_, err = s.LinkAccounts(ctx, userID, "plaid", "x")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
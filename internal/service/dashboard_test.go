@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/olmits/budget-tracker-backend/internal/models"
@@ -24,14 +25,47 @@ func (m *MockRepo) GetSummaryByType(ctx context.Context, userID uuid.UUID) (map[
 }
 
 func (m *MockRepo) CreateTransaction(ctx context.Context, t *models.Transaction) error {
-	return nil // Not used in this test
+	args := m.Called(ctx, t)
+	return args.Error(0)
 }
-func (m *MockRepo) ListTransactions(ctx context.Context, userID uuid.UUID) ([]*models.Transaction, error) {
-	return nil, nil // Not used in this test
+func (m *MockRepo) GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+func (m *MockRepo) GetTransactionByExternalID(ctx context.Context, userID uuid.UUID, externalID string) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+func (m *MockRepo) ListTransactions(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]*models.Transaction, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+func (m *MockRepo) UpdateTransaction(ctx context.Context, t *models.Transaction) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+func (m *MockRepo) DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID, versions []int) error {
+	return nil // Not used in these tests
 }
 func (m *MockRepo) GetPeriodicStats(ctx context.Context, userID uuid.UUID) ([]*models.PeriodicStat, error) {
 	return nil, nil // Not used in this test
 }
+func (m *MockRepo) GetCategoryStats(ctx context.Context, userID uuid.UUID, from time.Time) ([]*models.CategoryStat, error) {
+	args := m.Called(ctx, userID, from)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CategoryStat), args.Error(1)
+}
 
 func TestGetUserDashboard(t *testing.T) {
 	t.Run("Calculates Balance Correctly", func(t *testing.T) {
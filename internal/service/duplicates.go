@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	DefaultDuplicateDateTolerance = 3 * 24 * time.Hour
+	DefaultDuplicateMinSimilarity = 0.6
+)
+
+// DuplicateDetector flags transactions that look like an already stored one:
+// same amount, dates within a tolerance and similar descriptions.
+type DuplicateDetector struct {
+	TxRepo repository.TransactionRepository
+	Repo   repository.DuplicateRepository
+
+	DateTolerance time.Duration // Defaults to DefaultDuplicateDateTolerance
+	MinSimilarity float64       // 0..1, defaults to DefaultDuplicateMinSimilarity
+}
+
+// FindDuplicates returns the stored transactions that t probably duplicates, with a score.
+// t itself (when already stored) is never reported.
+func (d *DuplicateDetector) FindDuplicates(ctx context.Context, t *models.Transaction) ([]*models.DuplicateCandidate, error) {
+	tolerance := d.DateTolerance
+	if tolerance <= 0 {
+		tolerance = DefaultDuplicateDateTolerance
+	}
+	minSimilarity := d.MinSimilarity
+	if minSimilarity <= 0 {
+		minSimilarity = DefaultDuplicateMinSimilarity
+	}
+
+	amount := t.Amount
+	existing, err := d.TxRepo.ListTransactions(ctx, t.UserId, models.TransactionFilter{
+		From:   t.Date.Add(-tolerance),
+		To:     t.Date.Add(tolerance + time.Nanosecond), // "To" is exclusive
+		Amount: &amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*models.DuplicateCandidate
+	for _, other := range existing {
+		if other.ID == t.ID {
+			continue
+		}
+		similarity := DescriptionSimilarity(t.Description, other.Description)
+		if similarity < minSimilarity {
+			continue
+		}
+
+		// Same day counts fully, the edge of the window only half
+		daysApart := math.Abs(t.Date.Sub(other.Date).Hours()) / 24
+		toleranceDays := tolerance.Hours() / 24
+		score := similarity * (1 - 0.5*daysApart/toleranceDays)
+
+		candidates = append(candidates, &models.DuplicateCandidate{
+			UserId:        t.UserId,
+			TransactionId: t.ID,
+			DuplicateOfId: other.ID,
+			Score:         math.Round(score*1000) / 1000,
+			Status:        models.DuplicateStatusPending,
+			DuplicateOf:   other,
+		})
+	}
+	return candidates, nil
+}
+
+// Flag stores the candidates for a freshly created transaction so they show up
+// in the review queue. Pairs the user already dismissed are not returned again.
+func (d *DuplicateDetector) Flag(ctx context.Context, t *models.Transaction) ([]*models.DuplicateCandidate, error) {
+	candidates, err := d.FindDuplicates(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*models.DuplicateCandidate
+	for _, c := range candidates {
+		if err := d.Repo.SaveCandidate(ctx, c); err != nil {
+			return nil, err
+		}
+		if c.Status == models.DuplicateStatusPending {
+			pending = append(pending, c)
+		}
+	}
+	return pending, nil
+}
+
+// DescriptionSimilarity is the Dice coefficient of the character bigrams of both
+// normalized descriptions (0 = nothing in common, 1 = identical). It tolerates
+// the small variations banks add, e.g. "AMAZON MKTPLACE" vs "Amazon Marketplace".
+func DescriptionSimilarity(a, b string) float64 {
+	a, b = normalizeDescription(a), normalizeDescription(b)
+	if a == b {
+		return 1
+	}
+	if len([]rune(a)) < 2 || len([]rune(b)) < 2 {
+		return 0
+	}
+
+	bigramsA := bigrams(a)
+	bigramsB := bigrams(b)
+
+	var common, totalA, totalB int
+	for g, n := range bigramsA {
+		totalA += n
+		common += min(n, bigramsB[g])
+	}
+	for _, n := range bigramsB {
+		totalB += n
+	}
+	return 2 * float64(common) / float64(totalA+totalB)
+}
+
+// normalizeDescription lowercases and keeps only letters, digits and single spaces
+func normalizeDescription(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space && b.Len() > 0 {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func bigrams(s string) map[string]int {
+	runes := []rune(s)
+	result := make(map[string]int, len(runes))
+	for i := 0; i < len(runes)-1; i++ {
+		result[string(runes[i:i+2])]++
+	}
+	return result
+}
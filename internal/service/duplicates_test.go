@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDuplicateRepo struct {
+	mock.Mock
+}
+
+func (m *MockDuplicateRepo) SaveCandidate(ctx context.Context, c *models.DuplicateCandidate) error {
+	args := m.Called(ctx, c)
+	if status, ok := args.Get(0).(string); ok {
+		c.Status = status
+	}
+	return args.Error(1)
+}
+func (m *MockDuplicateRepo) ListCandidates(ctx context.Context, userID uuid.UUID, status string) ([]*models.DuplicateCandidate, error) {
+	return nil, nil // Not used in this test
+}
+func (m *MockDuplicateRepo) DismissCandidate(ctx context.Context, userID, candidateID uuid.UUID) error {
+	return nil // Not used in this test
+}
+func (m *MockDuplicateRepo) MergeCandidate(ctx context.Context, userID, candidateID uuid.UUID) (uuid.UUID, error) {
+	return uuid.Nil, nil // Not used in this test
+}
+
+func TestDescriptionSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, DescriptionSimilarity("REWE  Markt!", "rewe markt"))
+	assert.Greater(t, DescriptionSimilarity("AMAZON MKTPLACE", "Amazon Marketplace"), 0.6)
+	assert.Less(t, DescriptionSimilarity("Netflix", "Rent"), 0.3)
+}
+
+func TestDuplicateDetector(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	newTx := &models.Transaction{ID: uuid.New(), UserId: userID, Amount: 2599, Description: "AMAZON MKTPLACE DE", Date: day}
+	sameDay := &models.Transaction{ID: uuid.New(), Amount: 2599, Description: "Amazon Marketplace DE", Date: day}
+	twoDaysLater := &models.Transaction{ID: uuid.New(), Amount: 2599, Description: "AMAZON MKTPLACE DE", Date: day.AddDate(0, 0, 2)}
+	unrelated := &models.Transaction{ID: uuid.New(), Amount: 2599, Description: "Gym membership", Date: day}
+
+	t.Run("Finds Similar Rows Within Tolerance", func(t *testing.T) {
+		txRepo := new(MockRepo)
+		txRepo.On("ListTransactions", mock.Anything, userID, mock.MatchedBy(func(f models.TransactionFilter) bool {
+			return *f.Amount == 2599 && f.From.Equal(day.AddDate(0, 0, -3)) && f.To.After(day.AddDate(0, 0, 3))
+		})).Return([]*models.Transaction{newTx, sameDay, twoDaysLater, unrelated}, nil)
+
+		d := &DuplicateDetector{TxRepo: txRepo}
+		candidates, err := d.FindDuplicates(context.Background(), newTx)
+
+		assert.NoError(t, err)
+		assert.Len(t, candidates, 2)
+		assert.Equal(t, sameDay.ID, candidates[0].DuplicateOfId)
+		// Further apart in time scores lower even with an identical description
+		assert.Less(t, candidates[1].Score, 1.0)
+		txRepo.AssertExpectations(t)
+	})
+
+	t.Run("Flag Skips Dismissed Pairs", func(t *testing.T) {
+		txRepo := new(MockRepo)
+		txRepo.On("ListTransactions", mock.Anything, userID, mock.Anything).Return([]*models.Transaction{sameDay, twoDaysLater}, nil)
+		dupRepo := new(MockDuplicateRepo)
+		dupRepo.On("SaveCandidate", mock.Anything, mock.MatchedBy(func(c *models.DuplicateCandidate) bool {
+			return c.DuplicateOfId == sameDay.ID
+		})).Return(models.DuplicateStatusDismissed, nil)
+		dupRepo.On("SaveCandidate", mock.Anything, mock.Anything).Return(models.DuplicateStatusPending, nil)
+
+		d := &DuplicateDetector{TxRepo: txRepo, Repo: dupRepo}
+		pending, err := d.Flag(context.Background(), newTx)
+
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, twoDaysLater.ID, pending[0].DuplicateOfId)
+	})
+}
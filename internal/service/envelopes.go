@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+var (
+	// ErrNotAnEnvelope is returned when money is assigned to a category that is not an expense category
+	ErrNotAnEnvelope = errors.New("only expense categories have envelopes")
+	// ErrInsufficientFunds is returned when a move takes more than the source has available
+	ErrInsufficientFunds = errors.New("not enough money available to move")
+)
+
+// EnvelopeService runs envelope budgeting. Unspent money (or overspending)
+// of an envelope rolls into the next month, and income feeds a "ready to
+// assign" pool that money is assigned from.
+type EnvelopeService struct {
+	Repo         repository.BudgetRepository
+	CategoryRepo repository.CategoryRepository
+	Units        repository.UnitOfWork // Optional, checks the balance and moves money atomically
+}
+
+// MonthStart returns the first day of the month of t
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// GetMonth computes the budget of the month from the full history up to its end
+func (s *EnvelopeService) GetMonth(ctx context.Context, userID uuid.UUID, month time.Time) (*models.BudgetMonth, error) {
+	return getBudgetMonth(ctx, s.Repo, s.CategoryRepo, userID, month)
+}
+
+func getBudgetMonth(ctx context.Context, budgets repository.BudgetRepository, categoryRepo repository.CategoryRepository, userID uuid.UUID, month time.Time) (*models.BudgetMonth, error) {
+	month = MonthStart(month)
+	end := month.AddDate(0, 1, 0)
+
+	categories, err := categoryRepo.ListCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := budgets.ListAssigned(ctx, userID, end)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := budgets.ListActivity(ctx, userID, end)
+	if err != nil {
+		return nil, err
+	}
+	return ComputeBudgetMonth(month, categories, assigned, activity), nil
+}
+
+// Assign sets the amount assigned to an envelope for the month
+func (s *EnvelopeService) Assign(ctx context.Context, userID, categoryID uuid.UUID, month time.Time, amount int64) (*models.BudgetMonth, error) {
+	if err := s.checkEnvelope(ctx, userID, categoryID); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.SetAssigned(ctx, userID, categoryID, MonthStart(month), amount); err != nil {
+		return nil, err
+	}
+	return s.GetMonth(ctx, userID, month)
+}
+
+// Move takes amount from one envelope and adds it to another. A nil side is
+// the "ready to assign" pool. The source must have the money available.
+// The caller checks that amount is positive and that the sides differ.
+// With Units, the user's budget stays locked from the balance check to the
+// move, so concurrent moves can't both spend the same money.
+func (s *EnvelopeService) Move(ctx context.Context, userID uuid.UUID, month time.Time, from, to *uuid.UUID, amount int64) (*models.BudgetMonth, error) {
+	for _, id := range []*uuid.UUID{from, to} {
+		if id == nil {
+			continue
+		}
+		if err := s.checkEnvelope(ctx, userID, *id); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.atomic(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Budgets.LockBudget(ctx, userID); err != nil {
+			return err
+		}
+		current, err := getBudgetMonth(ctx, repos.Budgets, repos.Categories, userID, month)
+		if err != nil {
+			return err
+		}
+		available := current.ReadyToAssign
+		if from != nil {
+			available = 0
+			for _, e := range current.Envelopes {
+				if e.CategoryId == *from {
+					available = e.Available
+				}
+			}
+		}
+		if amount > available {
+			return ErrInsufficientFunds
+		}
+		return repos.Budgets.MoveAssigned(ctx, userID, MonthStart(month), from, to, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMonth(ctx, userID, month)
+}
+
+// atomic runs fn in a unit of work, or on the service's own repositories
+// (every call committed on its own) when it has none
+func (s *EnvelopeService) atomic(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	if s.Units == nil {
+		return fn(&repository.Repositories{Budgets: s.Repo, Categories: s.CategoryRepo})
+	}
+	return s.Units.Do(ctx, fn)
+}
+
+func (s *EnvelopeService) checkEnvelope(ctx context.Context, userID, categoryID uuid.UUID) error {
+	category, err := s.CategoryRepo.GetCategory(ctx, userID, categoryID)
+	if err != nil {
+		return err
+	}
+	if category.Type != "expense" {
+		return ErrNotAnEnvelope
+	}
+	return nil
+}
+
+// ComputeBudgetMonth builds the budget of month from the assignments and
+// transaction totals of that month and every month before it. Rolling over
+// is additive, so the carryover is simply the sum of what was assigned minus
+// what was spent in earlier months.
+func ComputeBudgetMonth(month time.Time, categories []*models.Category, assigned, activity []*models.MonthlyAmount) *models.BudgetMonth {
+	month = MonthStart(month)
+	b := &models.BudgetMonth{Month: month.Format("2006-01"), Envelopes: []*models.Envelope{}}
+
+	envelopes := make(map[uuid.UUID]*models.Envelope)
+	income := make(map[uuid.UUID]bool)
+	for _, c := range categories {
+		switch c.Type {
+		case "expense":
+			e := &models.Envelope{CategoryId: c.ID, CategoryName: c.Name}
+			envelopes[c.ID] = e
+			b.Envelopes = append(b.Envelopes, e) // Categories come sorted by name
+		case "income":
+			income[c.ID] = true
+		}
+	}
+
+	for _, a := range assigned {
+		e, ok := envelopes[a.CategoryId]
+		if !ok || a.Month.After(month) {
+			continue
+		}
+		b.ReadyToAssign -= a.Amount
+		if a.Month.Equal(month) {
+			e.Assigned += a.Amount
+			b.Assigned += a.Amount
+		} else {
+			e.Carryover += a.Amount
+		}
+	}
+
+	for _, a := range activity {
+		if a.Month.After(month) {
+			continue
+		}
+		if income[a.CategoryId] {
+			b.ReadyToAssign += a.Amount
+			if a.Month.Equal(month) {
+				b.Income += a.Amount
+			}
+			continue
+		}
+		e, ok := envelopes[a.CategoryId]
+		if !ok {
+			continue
+		}
+		if a.Month.Equal(month) {
+			e.Activity += a.Amount
+		} else {
+			e.Carryover -= a.Amount
+		}
+	}
+
+	for _, e := range b.Envelopes {
+		e.Available = e.Carryover + e.Assigned - e.Activity
+	}
+	return b
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memBudgetRepo struct {
+	assigned []*models.MonthlyAmount
+	activity []*models.MonthlyAmount
+	locks    int
+}
+
+func (r *memBudgetRepo) ListAssigned(ctx context.Context, userID uuid.UUID, to time.Time) ([]*models.MonthlyAmount, error) {
+	return r.assigned, nil
+}
+
+func (r *memBudgetRepo) ListActivity(ctx context.Context, userID uuid.UUID, to time.Time) ([]*models.MonthlyAmount, error) {
+	return r.activity, nil
+}
+
+func (r *memBudgetRepo) SetAssigned(ctx context.Context, userID, categoryID uuid.UUID, month time.Time, amount int64) error {
+	for _, a := range r.assigned {
+		if a.CategoryId == categoryID && a.Month.Equal(month) {
+			a.Amount = amount
+			return nil
+		}
+	}
+	r.assigned = append(r.assigned, &models.MonthlyAmount{CategoryId: categoryID, Month: month, Amount: amount})
+	return nil
+}
+
+func (r *memBudgetRepo) LockBudget(ctx context.Context, userID uuid.UUID) error {
+	r.locks++
+	return nil
+}
+
+func (r *memBudgetRepo) MoveAssigned(ctx context.Context, userID uuid.UUID, month time.Time, from, to *uuid.UUID, amount int64) error {
+	for _, side := range []struct {
+		id    *uuid.UUID
+		delta int64
+	}{{from, -amount}, {to, amount}} {
+		if side.id == nil {
+			continue
+		}
+		current := int64(0)
+		for _, a := range r.assigned {
+			if a.CategoryId == *side.id && a.Month.Equal(month) {
+				current = a.Amount
+			}
+		}
+		_ = r.SetAssigned(ctx, userID, *side.id, month, current+side.delta)
+	}
+	return nil
+}
+
+func monthOf(m time.Month) time.Time {
+	return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestComputeBudgetMonth(t *testing.T) {
+	salary := &models.Category{ID: uuid.New(), Name: "Salary", Type: "income"}
+	groceries := &models.Category{ID: uuid.New(), Name: "Groceries", Type: "expense"}
+	rent := &models.Category{ID: uuid.New(), Name: "Rent", Type: "expense"}
+	categories := []*models.Category{groceries, rent, salary}
+
+	assigned := []*models.MonthlyAmount{
+		{CategoryId: groceries.ID, Month: monthOf(time.January), Amount: 40000},
+		{CategoryId: rent.ID, Month: monthOf(time.January), Amount: 100000},
+		{CategoryId: groceries.ID, Month: monthOf(time.February), Amount: 40000},
+		{CategoryId: rent.ID, Month: monthOf(time.February), Amount: 100000},
+		{CategoryId: groceries.ID, Month: monthOf(time.March), Amount: 99999}, // Later than the requested month
+	}
+	activity := []*models.MonthlyAmount{
+		{CategoryId: salary.ID, Month: monthOf(time.January), Amount: 250000},
+		{CategoryId: groceries.ID, Month: monthOf(time.January), Amount: 30000}, // 10000 left
+		{CategoryId: rent.ID, Month: monthOf(time.January), Amount: 110000},     // Overspent by 10000
+		{CategoryId: salary.ID, Month: monthOf(time.February), Amount: 250000},
+		{CategoryId: groceries.ID, Month: monthOf(time.February), Amount: 45000},
+	}
+
+	b := ComputeBudgetMonth(monthOf(time.February), categories, assigned, activity)
+	assert.Equal(t, "2026-02", b.Month)
+	assert.Equal(t, int64(250000), b.Income)
+	assert.Equal(t, int64(140000), b.Assigned)
+	assert.Equal(t, int64(500000-280000), b.ReadyToAssign)
+
+	require.Len(t, b.Envelopes, 2)
+	g, r := b.Envelopes[0], b.Envelopes[1]
+	assert.Equal(t, "Groceries", g.CategoryName)
+	assert.Equal(t, int64(10000), g.Carryover)
+	assert.Equal(t, int64(45000), g.Activity)
+	assert.Equal(t, int64(5000), g.Available)
+	assert.Equal(t, int64(-10000), r.Carryover)
+	assert.Equal(t, int64(90000), r.Available)
+
+	// Nothing assigned yet: only the rollover is available
+	b = ComputeBudgetMonth(monthOf(time.April).Add(36*time.Hour), categories, assigned[:4], activity)
+	assert.Equal(t, "2026-04", b.Month)
+	assert.Equal(t, int64(0), b.Envelopes[0].Assigned)
+	assert.Equal(t, int64(5000), b.Envelopes[0].Available)
+}
+
+func TestEnvelopeServiceMove(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	salary := &models.Category{ID: uuid.New(), Name: "Salary", Type: "income"}
+	groceries := &models.Category{ID: uuid.New(), Name: "Groceries", Type: "expense"}
+	fun := &models.Category{ID: uuid.New(), Name: "Fun", Type: "expense"}
+
+	repo := &memBudgetRepo{activity: []*models.MonthlyAmount{
+		{CategoryId: salary.ID, Month: monthOf(time.May), Amount: 100000},
+	}}
+	categoryRepo := &memCategoryRepo{rows: []*models.Category{fun, groceries, salary}}
+	units := &fakeUnitOfWork{repos: &repository.Repositories{Budgets: repo, Categories: categoryRepo}}
+	s := &EnvelopeService{
+		Repo:         repo,
+		CategoryRepo: categoryRepo,
+		Units:        units,
+	}
+
+	b, err := s.Assign(ctx, userID, groceries.ID, monthOf(time.May), 60000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40000), b.ReadyToAssign)
+
+	// Ready to assign -> Fun
+	b, err = s.Move(ctx, userID, monthOf(time.May), nil, &fun.ID, 30000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), b.ReadyToAssign)
+	assert.Equal(t, int64(30000), b.Envelopes[0].Available)
+
+	// Groceries -> Fun
+	b, err = s.Move(ctx, userID, monthOf(time.May), &groceries.ID, &fun.ID, 20000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50000), b.Envelopes[0].Available)
+	assert.Equal(t, int64(40000), b.Envelopes[1].Available)
+
+	_, err = s.Move(ctx, userID, monthOf(time.May), nil, &fun.ID, 10001)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.Equal(t, 3, repo.locks, "every balance check ran under the lock")
+	assert.Equal(t, 1, units.rollbacks)
+
+	_, err = s.Move(ctx, userID, monthOf(time.May), &groceries.ID, &salary.ID, 1)
+	assert.ErrorIs(t, err, ErrNotAnEnvelope)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+// EventPublisher is a sink for the events of the outbox (see OutboxRelay).
+// An event may be published more than once; its ID stays the same.
+type EventPublisher interface {
+	Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error
+}
+
+// EventPublishers publishes every event to each of its publishers
+type EventPublishers []EventPublisher
+
+func (ps EventPublishers) Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error {
+	var errs []error
+	for _, p := range ps {
+		if err := p.Publish(ctx, userID, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogPublisher writes a line per event to the standard logger
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error {
+	log.Printf("event %s %s for user %s", event.Type, event.ID, userID)
+	return nil
+}
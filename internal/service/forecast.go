@@ -0,0 +1,193 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	// MaxForecastDays bounds the projection, recurring patterns say little beyond a year
+	MaxForecastDays = 365
+
+	forecastHistoryDays = 400 // Long enough for a yearly item to show up twice
+	variableWindowDays  = 90  // Recent history the variable spending is averaged over
+
+	// The Low..High bands cover this share of outcomes, assuming daily
+	// variable spending is independent and roughly normal
+	forecastConfidence = 0.8
+	forecastZ          = 1.2816
+)
+
+// ForecastService projects the user's balance from recurring items and variable spending
+type ForecastService struct {
+	TxRepo repository.TransactionRepository
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+// Forecast projects the balance for the next days, starting from the net of all transactions
+func (s *ForecastService) Forecast(ctx context.Context, userID uuid.UUID, days int, threshold int64) (*models.Forecast, error) {
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	today := dayOf(now)
+
+	sums, err := s.TxRepo.GetSummaryByType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.TxRepo.ListTransactions(ctx, userID, models.TransactionFilter{
+		From: today.AddDate(0, 0, -forecastHistoryDays),
+		To:   today.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildForecast(now, sums["income"]-sums["expense"], history, days, threshold), nil
+}
+
+// BuildForecast projects balance over the given number of days after now.
+// Recurring items land on their expected dates. Everything else that was
+// spent is treated as variable: its daily average over the recent history is
+// subtracted every day, and the bands widen with the spread of that spending.
+// Irregular income is left out, the forecast would rather be pessimistic.
+func BuildForecast(now time.Time, balance int64, history []*models.Transaction, days int, threshold int64) *models.Forecast {
+	today := dayOf(now)
+	f := &models.Forecast{
+		StartBalance:  balance,
+		Threshold:     threshold,
+		Confidence:    forecastConfidence,
+		Days:          make([]*models.ForecastDay, 0, days),
+		Crossings:     []*models.ThresholdCrossing{},
+		Recurring:     []*models.RecurringItem{},
+		VariableSpend: []*models.VariableSpend{},
+	}
+
+	matches := detectRecurring(history, today)
+	matched := make(map[uuid.UUID]bool)
+	for _, m := range matches {
+		f.Recurring = append(f.Recurring, m.item)
+		for _, t := range m.transactions {
+			matched[t.ID] = true
+		}
+	}
+
+	mean, stddev := variableSpending(f, history, matched, today)
+
+	// Net amount of the recurring items per forecast day
+	end := today.AddDate(0, 0, days)
+	scheduled := make(map[time.Time]int64)
+	for _, m := range matches {
+		for d := m.item.NextDate; !d.After(end); d = m.recurrence.after(d) {
+			if m.item.Type == "income" {
+				scheduled[d] += m.item.Amount
+			} else {
+				scheduled[d] -= m.item.Amount
+			}
+		}
+	}
+
+	below := balance < threshold
+	var recurringTotal, variableTotal int64
+	for i := 1; i <= days; i++ {
+		d := today.AddDate(0, 0, i)
+
+		variable := int64(math.Round(mean*float64(i))) - variableTotal
+		variableTotal += variable
+		recurringTotal += scheduled[d]
+
+		expected := balance + recurringTotal - variableTotal
+		band := int64(math.Round(forecastZ * stddev * math.Sqrt(float64(i))))
+		day := &models.ForecastDay{
+			Date:      d.Format("2006-01-02"),
+			Recurring: scheduled[d],
+			Variable:  variable,
+			Balance:   expected,
+			Low:       expected - band,
+			High:      expected + band,
+		}
+		f.Days = append(f.Days, day)
+
+		if (expected < threshold) != below {
+			below = !below
+			direction := "above"
+			if below {
+				direction = "below"
+			}
+			f.Crossings = append(f.Crossings, &models.ThresholdCrossing{
+				Date:      day.Date,
+				Direction: direction,
+				Balance:   expected,
+			})
+		}
+	}
+
+	return f
+}
+
+// variableSpending fills f.VariableSpend and returns the mean and standard
+// deviation of the daily total of expenses that are not recurring items
+func variableSpending(f *models.Forecast, history []*models.Transaction, recurring map[uuid.UUID]bool, today time.Time) (float64, float64) {
+	start := today.AddDate(0, 0, -variableWindowDays+1)
+
+	// A short history is averaged over the days it covers
+	earliest := today
+	for _, t := range history {
+		if d := dayOf(t.Date); d.Before(earliest) {
+			earliest = d
+		}
+	}
+	if earliest.After(start) {
+		start = earliest
+	}
+	window := int(today.Sub(start).Hours()/24) + 1
+
+	daily := make([]float64, window)
+	perCategory := make(map[uuid.UUID]*models.VariableSpend)
+	totals := make(map[uuid.UUID]int64)
+
+	for _, t := range history {
+		d := dayOf(t.Date)
+		if t.Type != "expense" || recurring[t.ID] || t.CategoryId == nil || d.Before(start) || d.After(today) {
+			continue
+		}
+		daily[int(d.Sub(start).Hours()/24)] += float64(t.Amount)
+		if _, ok := perCategory[*t.CategoryId]; !ok {
+			perCategory[*t.CategoryId] = &models.VariableSpend{CategoryId: *t.CategoryId, CategoryName: t.CategoryName}
+		}
+		totals[*t.CategoryId] += t.Amount
+	}
+
+	for id, v := range perCategory {
+		v.DailyAverage = int64(math.Round(float64(totals[id]) / float64(window)))
+		f.VariableSpend = append(f.VariableSpend, v)
+	}
+	slices.SortFunc(f.VariableSpend, func(a, b *models.VariableSpend) int {
+		if c := cmp.Compare(b.DailyAverage, a.DailyAverage); c != 0 {
+			return c
+		}
+		return strings.Compare(a.CategoryName, b.CategoryName)
+	})
+
+	var sum float64
+	for _, v := range daily {
+		sum += v
+	}
+	mean := sum / float64(window)
+
+	var variance float64
+	for _, v := range daily {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(window))
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildForecast(t *testing.T) {
+	now := time.Date(2026, time.June, 10, 18, 0, 0, 0, time.UTC)
+	salary := &models.Category{ID: uuid.New(), Name: "Salary", Type: "income"}
+	housing := &models.Category{ID: uuid.New(), Name: "Housing", Type: "expense"}
+	groceries := &models.Category{ID: uuid.New(), Name: "Groceries", Type: "expense"}
+
+	var history []*models.Transaction
+	for m := time.February; m <= time.May; m++ {
+		history = append(history, historyTx(salary, "ACME Payroll", 300000, time.Date(2026, m, 25, 0, 0, 0, 0, time.UTC)))
+	}
+	for m := time.March; m <= time.June; m++ {
+		history = append(history, historyTx(housing, "Rent", 120000, time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC)))
+	}
+	for d := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC); !d.After(now); d = d.AddDate(0, 0, 1) {
+		history = append(history, historyTx(groceries, "Corner Shop", 1000, d))
+	}
+
+	f := BuildForecast(now, 10000, history, 30, 0)
+	require.Len(t, f.Days, 30)
+	require.Len(t, f.Recurring, 2)
+	require.Len(t, f.VariableSpend, 1)
+	assert.Equal(t, int64(1000), f.VariableSpend[0].DailyAverage)
+
+	first := f.Days[0]
+	assert.Equal(t, "2026-06-11", first.Date)
+	assert.Equal(t, int64(9000), first.Balance)
+	assert.Equal(t, first.Balance, first.Low) // Spending never varied
+	assert.Equal(t, first.Balance, first.High)
+
+	payday := f.Days[14]
+	assert.Equal(t, "2026-06-25", payday.Date)
+	assert.Equal(t, int64(300000), payday.Recurring)
+	assert.Equal(t, int64(10000-15000+300000), payday.Balance)
+
+	assert.Equal(t, int64(10000-30000+300000-120000), f.Days[29].Balance)
+
+	// Goes negative before payday, back above on payday
+	require.Len(t, f.Crossings, 2)
+	assert.Equal(t, &models.ThresholdCrossing{Date: "2026-06-21", Direction: "below", Balance: -1000}, f.Crossings[0])
+	assert.Equal(t, "2026-06-25", f.Crossings[1].Date)
+	assert.Equal(t, "above", f.Crossings[1].Direction)
+
+	// A one-off splurge raises the average and widens the bands over time
+	history = append(history, historyTx(groceries, "Big Party", 45000, now.AddDate(0, 0, -3)))
+	f = BuildForecast(now, 10000, history, 30, 0)
+	assert.Equal(t, int64(1500), f.VariableSpend[0].DailyAverage)
+	assert.Less(t, f.Days[0].Low, f.Days[0].Balance)
+	assert.Greater(t, f.Days[0].High, f.Days[0].Balance)
+	assert.Greater(t, f.Days[29].High-f.Days[29].Low, f.Days[0].High-f.Days[0].Low)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// Average month length of the Gregorian calendar
+const daysPerMonth = 365.2425 / 12
+
+// GoalService computes the progress of savings goals
+type GoalService struct {
+	Repo     repository.GoalRepository
+	Accounts repository.BankAccountRepository
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+// ValidateGoal checks the fields a client can set
+func ValidateGoal(g *models.Goal) error {
+	if strings.TrimSpace(g.Name) == "" {
+		return errors.New("name is required")
+	}
+	if g.TargetAmount <= 0 {
+		return errors.New("target_amount must be positive")
+	}
+	if (g.CategoryId == nil) == (g.BankAccountId == nil) {
+		return errors.New("link the goal to either a category_id or a bank_account_id")
+	}
+	if g.TargetDate != nil && !g.TargetDate.After(g.StartDate) {
+		return errors.New("target_date must be after start_date")
+	}
+	return nil
+}
+
+// ListGoals returns the user's goals with their progress
+func (s *GoalService) ListGoals(ctx context.Context, userID uuid.UUID) ([]*models.Goal, error) {
+	goals, err := s.Repo.ListGoals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range goals {
+		if err := s.AttachProgress(ctx, g); err != nil {
+			return nil, err
+		}
+	}
+	return goals, nil
+}
+
+// GetGoal returns one goal with its progress
+func (s *GoalService) GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*models.Goal, error) {
+	g, err := s.Repo.GetGoal(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return g, s.AttachProgress(ctx, g)
+}
+
+// AttachProgress sums the contributions of the goal and fills g.Progress
+func (s *GoalService) AttachProgress(ctx context.Context, g *models.Goal) error {
+	var saved int64
+	switch {
+	case g.CategoryId != nil:
+		total, err := s.Repo.SumCategory(ctx, g.UserId, *g.CategoryId, g.StartDate)
+		if err != nil {
+			return err
+		}
+		saved = total
+	case g.BankAccountId != nil:
+		account, err := s.Accounts.GetAccount(ctx, g.UserId, *g.BankAccountId)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if account != nil {
+			// Only what the balance grew by since the start counts
+			start, err := s.Accounts.BalanceAt(ctx, g.UserId, account.ID, g.StartDate)
+			if errors.Is(err, repository.ErrNotFound) {
+				start = account.Balance
+			} else if err != nil {
+				return err
+			}
+			saved = account.Balance - start
+		}
+	}
+	// Neither: the linked category or account was deleted, nothing counts any more
+
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	g.Progress = ComputeGoalProgress(g, saved, now)
+	return nil
+}
+
+// ComputeGoalProgress compares what was saved with a steady pace from the
+// start date to the target date. The goal is on track when the saved amount
+// is at least what that pace would have reached by now.
+func ComputeGoalProgress(g *models.Goal, saved int64, now time.Time) *models.GoalProgress {
+	today := dayOf(now)
+	start := dayOf(g.StartDate)
+
+	p := &models.GoalProgress{
+		Saved:     saved,
+		Remaining: max(g.TargetAmount-saved, 0),
+		Percent:   math.Round(math.Min(math.Max(float64(saved)/float64(g.TargetAmount), 0), 1)*1000) / 10,
+		Completed: saved >= g.TargetAmount,
+	}
+
+	monthsSoFar := math.Max(monthsBetween(start, today), 1)
+	p.AverageMonthly = int64(math.Round(float64(saved) / monthsSoFar))
+
+	if g.TargetDate == nil {
+		return p
+	}
+	target := dayOf(*g.TargetDate)
+
+	monthsLeft := max(int(math.Ceil(monthsBetween(today, target))), 0)
+	required := p.Remaining
+	if monthsLeft > 0 {
+		required = int64(math.Ceil(float64(p.Remaining) / float64(monthsLeft)))
+	}
+
+	elapsed := math.Min(math.Max(today.Sub(start).Hours()/target.Sub(start).Hours(), 0), 1)
+	expected := int64(math.Round(float64(g.TargetAmount) * elapsed))
+	onTrack := p.Completed || saved >= expected
+
+	p.MonthsLeft = &monthsLeft
+	p.RequiredMonthly = &required
+	p.ExpectedByNow = &expected
+	p.OnTrack = &onTrack
+	return p
+}
+
+func monthsBetween(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / daysPerMonth
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
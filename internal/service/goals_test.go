@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeGoalProgress(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	target := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	midYear := time.Date(2026, time.July, 3, 15, 0, 0, 0, time.UTC) // Just over half the year has passed
+	goal := &models.Goal{TargetAmount: 120000, StartDate: start, TargetDate: &target}
+
+	// Ahead of the linear pace
+	p := ComputeGoalProgress(goal, 70000, midYear)
+	assert.Equal(t, int64(50000), p.Remaining)
+	assert.Equal(t, 58.3, p.Percent)
+	assert.False(t, p.Completed)
+	require.NotNil(t, p.OnTrack)
+	assert.True(t, *p.OnTrack)
+	assert.Equal(t, int64(60164), *p.ExpectedByNow)
+	assert.Equal(t, 6, *p.MonthsLeft)
+	assert.Equal(t, int64(8334), *p.RequiredMonthly)
+
+	// Behind
+	p = ComputeGoalProgress(goal, 30000, midYear)
+	assert.False(t, *p.OnTrack)
+	assert.Equal(t, int64(15000), *p.RequiredMonthly)
+
+	// Completed
+	p = ComputeGoalProgress(goal, 130000, midYear)
+	assert.True(t, p.Completed)
+	assert.Equal(t, int64(0), p.Remaining)
+	assert.Equal(t, 100.0, p.Percent)
+	assert.True(t, *p.OnTrack)
+
+	// Past due: the whole remainder is required now
+	p = ComputeGoalProgress(goal, 100000, target.AddDate(0, 1, 0))
+	assert.Equal(t, 0, *p.MonthsLeft)
+	assert.Equal(t, int64(20000), *p.RequiredMonthly)
+	assert.False(t, *p.OnTrack)
+
+	// Without a target date only the pace so far is known
+	open := &models.Goal{TargetAmount: 120000, StartDate: start}
+	p = ComputeGoalProgress(open, 30000, start.AddDate(0, 3, 0))
+	assert.Nil(t, p.OnTrack)
+	assert.Nil(t, p.RequiredMonthly)
+	assert.InDelta(t, 10000, p.AverageMonthly, 200)
+}
+
+func TestValidateGoal(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	categoryID, accountID := uuid.New(), uuid.New()
+	before := start.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		goal    models.Goal
+		wantErr bool
+	}{
+		{"category goal", models.Goal{Name: "Trip", TargetAmount: 1, StartDate: start, CategoryId: &categoryID}, false},
+		{"account goal", models.Goal{Name: "Trip", TargetAmount: 1, StartDate: start, BankAccountId: &accountID}, false},
+		{"blank name", models.Goal{Name: " ", TargetAmount: 1, StartDate: start, CategoryId: &categoryID}, true},
+		{"no target", models.Goal{Name: "Trip", StartDate: start, CategoryId: &categoryID}, true},
+		{"unlinked", models.Goal{Name: "Trip", TargetAmount: 1, StartDate: start}, true},
+		{"linked twice", models.Goal{Name: "Trip", TargetAmount: 1, StartDate: start, CategoryId: &categoryID, BankAccountId: &accountID}, true},
+		{"target before start", models.Goal{Name: "Trip", TargetAmount: 1, StartDate: start, TargetDate: &before, CategoryId: &categoryID}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGoal(&tt.goal)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAttachProgress_BankAccount(t *testing.T) {
+	ctx := context.Background()
+	userID, accountID := uuid.New(), uuid.New()
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	accounts := &memBankAccountRepo{rows: map[uuid.UUID]*models.BankAccount{
+		accountID: {ID: accountID, UserId: userID},
+	}}
+	s := &GoalService{Accounts: accounts, Now: func() time.Time { return now }}
+	require.NoError(t, accounts.UpdateBalance(ctx, userID, accountID, 400000, start.AddDate(0, -1, 0)))
+	require.NoError(t, accounts.UpdateBalance(ctx, userID, accountID, 450000, start.AddDate(0, 0, -2)))
+	require.NoError(t, accounts.UpdateBalance(ctx, userID, accountID, 480000, start.AddDate(0, 1, 0)))
+
+	// The balance the account already had at the start does not count
+	goal := &models.Goal{UserId: userID, TargetAmount: 100000, StartDate: start, BankAccountId: &accountID}
+	require.NoError(t, s.AttachProgress(ctx, goal))
+	assert.Equal(t, int64(30000), goal.Progress.Saved)
+
+	// Linked after the start: growth since the first balance seen
+	goal.StartDate = start.AddDate(0, -3, 0)
+	require.NoError(t, s.AttachProgress(ctx, goal))
+	assert.Equal(t, int64(80000), goal.Progress.Saved)
+}
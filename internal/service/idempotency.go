@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// IdempotencyService removes the stored responses of expired Idempotency-Keys.
+// Expired keys are ignored by the middleware already, this only bounds the table.
+type IdempotencyService struct {
+	Repo repository.IdempotencyRepository
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (s *IdempotencyService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// PurgeExpired deletes the expired keys and returns how many it deleted
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.Repo.DeleteExpiredKeys(ctx, s.now())
+}
+
+// Run purges expired keys every interval until ctx is done
+func (s *IdempotencyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.PurgeExpired(ctx); err != nil {
+			log.Printf("idempotency: %v", err)
+		} else if n > 0 {
+			log.Printf("idempotency: deleted %d expired keys", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/olmits/budget-tracker-backend/internal/importer"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	// Fallback categories for imported rows nothing else could categorize
+	UncategorizedExpense = "Uncategorized"
+	UncategorizedIncome  = "Uncategorized Income"
+
+	// Learned suggestions below this confidence are ignored on import
+	importSuggestionThreshold = 0.6
+)
+
+// ImportService stores parsed statement records as transactions. It is the
+// single pipeline every import format goes through: skip rows already
+// imported, run rules, pick a category, create, then flag duplicates.
+type ImportService struct {
+	TxRepo       repository.TransactionRepository
+	CategoryRepo repository.CategoryRepository
+	Rules        *RuleService          // Optional
+	Suggester    *CategorySuggester    // Optional
+	Duplicates   *DuplicateDetector    // Optional
+	Units        repository.UnitOfWork // Optional, stores a record and the category created for it atomically
+}
+
+// importRun holds the per-import state (user categories and compiled rules)
+type importRun struct {
+	s          *ImportService
+	userID     uuid.UUID
+	rules      *RuleSet
+	categories map[uuid.UUID]*models.Category
+	byName     map[string]*models.Category // Key: categoryKey(name)
+	created    []*models.Category          // Categories this run added
+	update     bool                        // Update already imported records instead of skipping them
+}
+
+// Import stores every record of the batch and reports what happened to each one.
+// Records already imported (same external ID) are skipped.
+func (s *ImportService) Import(ctx context.Context, userID uuid.UUID, batch *importer.Batch) (*models.ImportReport, error) {
+	return s.run(ctx, userID, batch, false)
+}
+
+// Upsert is Import for sources that may correct what they sent before (bank
+// sync): a record already imported updates the amount and date of its
+// transaction. Category, description and tags stay as the user left them.
+func (s *ImportService) Upsert(ctx context.Context, userID uuid.UUID, batch *importer.Batch) (*models.ImportReport, error) {
+	return s.run(ctx, userID, batch, true)
+}
+
+// Outcome of a single record
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importFlagged               // Created and flagged as a likely duplicate
+	importUpdated
+	importSkipped
+)
+
+func (s *ImportService) run(ctx context.Context, userID uuid.UUID, batch *importer.Batch, update bool) (*models.ImportReport, error) {
+	run, err := s.newRun(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	run.update = update
+
+	report := &models.ImportReport{
+		Format:  batch.Format,
+		Total:   len(batch.Records) + len(batch.Errors) + batch.Skipped,
+		Skipped: batch.Skipped,
+		Failed:  len(batch.Errors),
+		Errors:  append([]*models.ImportError{}, batch.Errors...),
+	}
+
+	for i, record := range batch.Records {
+		outcome, err := run.importRecord(ctx, record)
+		if err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, &models.ImportError{
+				Entry:     i + 1,
+				Reference: record.ExternalID,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		switch outcome {
+		case importCreated:
+			report.Created++
+		case importFlagged:
+			report.Created++
+			report.Duplicates++
+		case importUpdated:
+			report.Updated++
+		case importSkipped:
+			report.Skipped++
+		}
+	}
+
+	report.CategoriesCreated = []string{}
+	for _, c := range run.created {
+		report.CategoriesCreated = append(report.CategoriesCreated, c.Name)
+	}
+	return report, nil
+}
+
+func (s *ImportService) newRun(ctx context.Context, userID uuid.UUID) (*importRun, error) {
+	run := &importRun{
+		s:          s,
+		userID:     userID,
+		categories: make(map[uuid.UUID]*models.Category),
+		byName:     make(map[string]*models.Category),
+	}
+
+	categories, err := s.CategoryRepo.ListCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		run.addCategory(c)
+	}
+
+	if s.Rules != nil {
+		run.rules, err = s.Rules.Load(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+func (run *importRun) addCategory(c *models.Category) {
+	run.categories[c.ID] = c
+	run.byName[categoryKey(c.Name)] = c
+}
+
+// importRecord stores one record, or updates/skips it when it was imported before
+func (run *importRun) importRecord(ctx context.Context, record *importer.Record) (importOutcome, error) {
+	if record.Amount == 0 {
+		return 0, errors.New("amount is zero")
+	}
+
+	// 1. Idempotency: the same external ID is only imported once, and our own
+	// exports are not imported back over the transactions they came from
+	if record.TransactionID != nil {
+		_, err := run.s.TxRepo.GetTransaction(ctx, run.userID, *record.TransactionID)
+		if err == nil {
+			return importSkipped, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return 0, err
+		}
+	}
+	if record.ExternalID != "" {
+		existing, err := run.s.TxRepo.GetTransactionByExternalID(ctx, run.userID, record.ExternalID)
+		if err == nil {
+			if run.update {
+				return run.updateRecord(ctx, existing, record)
+			}
+			return importSkipped, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return 0, err
+		}
+	}
+
+	t := &models.Transaction{
+		UserId:      run.userID,
+		Amount:      record.Amount,
+		Description: record.Description,
+		Date:        record.Date,
+		Tags:        []*models.Tag{},
+	}
+	if t.Amount < 0 {
+		t.Amount = -t.Amount
+	}
+	if record.ExternalID != "" {
+		externalID := record.ExternalID
+		t.ExternalID = &externalID
+	}
+
+	// 2 and 3 run in one unit of work: a category created for the record is
+	// rolled back with it when the transaction cannot be stored
+	var outcome importOutcome
+	created := len(run.created)
+	err := run.s.atomic(ctx, func(repos *repository.Repositories) error {
+		var err error
+		outcome, err = run.createRecord(ctx, repos, t, record)
+		return err
+	})
+	if err != nil {
+		if run.s.Units != nil {
+			run.forget(run.created[created:])
+			run.created = run.created[:created]
+		}
+		return 0, err
+	}
+	if outcome == importSkipped {
+		return importSkipped, nil
+	}
+
+	if run.s.Suggester != nil {
+		run.s.Suggester.Observe(t)
+	}
+
+	// 4. The row is stored, a detection failure only means no flag
+	if run.s.Duplicates != nil {
+		if candidates, err := run.s.Duplicates.Flag(ctx, t); err == nil && len(candidates) > 0 {
+			return importFlagged, nil
+		}
+	}
+
+	return importCreated, nil
+}
+
+// createRecord picks the category of a new transaction and stores it
+func (run *importRun) createRecord(ctx context.Context, repos *repository.Repositories, t *models.Transaction, record *importer.Record) (importOutcome, error) {
+	// 2. Pick a category: source name, then rules, then the source name again (creating
+	// the category the user does not have yet), then learned suggestions, then the fallback
+	categoryType := record.CategoryType
+	if categoryType == "" {
+		categoryType = "expense"
+		if record.Amount > 0 {
+			categoryType = "income"
+		}
+	}
+	if record.Category != "" {
+		if c, ok := run.byName[categoryKey(record.Category)]; ok {
+			t.CategoryId = &c.ID
+		}
+	}
+	run.rules.Apply(t)
+	if t.CategoryId == nil && record.Category != "" {
+		c, err := run.ensureCategory(ctx, repos.Categories, record.Category, categoryType)
+		if err != nil {
+			return 0, err
+		}
+		t.CategoryId = &c.ID
+	}
+	if t.CategoryId == nil && run.s.Suggester != nil {
+		if id := run.suggest(ctx, t.Description, categoryType); id != nil {
+			t.CategoryId = id
+		}
+	}
+	if t.CategoryId == nil {
+		c, err := run.fallbackCategory(ctx, repos.Categories, categoryType)
+		if err != nil {
+			return 0, err
+		}
+		t.CategoryId = &c.ID
+	}
+	if c, ok := run.categories[*t.CategoryId]; ok {
+		t.CategoryName, t.Type = c.Name, c.Type
+	}
+
+	// 3. Store
+	if err := repos.Transactions.CreateTransaction(ctx, t); err != nil {
+		// Imported concurrently by another request
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return importSkipped, nil
+		}
+		return 0, err
+	}
+	return importCreated, nil
+}
+
+// updateRecord applies the source's corrections to an already imported transaction
+func (run *importRun) updateRecord(ctx context.Context, t *models.Transaction, record *importer.Record) (importOutcome, error) {
+	amount := record.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	if t.Amount == amount && t.Date.Equal(record.Date) {
+		return importSkipped, nil
+	}
+
+	t.Amount, t.Date = amount, record.Date
+	if err := run.s.TxRepo.UpdateTransaction(ctx, t); err != nil {
+		return 0, err
+	}
+	return importUpdated, nil
+}
+
+// suggest returns the most likely learned category of the right type, if confident enough
+func (run *importRun) suggest(ctx context.Context, description, categoryType string) *uuid.UUID {
+	suggestions, err := run.s.Suggester.Suggest(ctx, run.userID, description, 0)
+	if err != nil {
+		return nil
+	}
+	for _, s := range suggestions {
+		if s.Confidence < importSuggestionThreshold {
+			break
+		}
+		if c, ok := run.categories[s.CategoryID]; ok && c.Type == categoryType {
+			return &c.ID
+		}
+	}
+	return nil
+}
+
+// fallbackCategory returns (creating it when needed) the "Uncategorized" category of the given type
+func (run *importRun) fallbackCategory(ctx context.Context, repo repository.CategoryRepository, categoryType string) (*models.Category, error) {
+	name := UncategorizedExpense
+	if categoryType == "income" {
+		name = UncategorizedIncome
+	}
+	return run.ensureCategory(ctx, repo, name, categoryType)
+}
+
+// ensureCategory finds a category by name (see categoryKey) or creates it
+func (run *importRun) ensureCategory(ctx context.Context, repo repository.CategoryRepository, name, categoryType string) (*models.Category, error) {
+	if c, ok := run.byName[categoryKey(name)]; ok {
+		return c, nil
+	}
+
+	c := &models.Category{UserId: run.userID, Name: name, Type: categoryType}
+	if err := repo.CreateCategory(ctx, c); err != nil {
+		return nil, fmt.Errorf("create category %q: %w", name, err)
+	}
+	run.addCategory(c)
+	run.created = append(run.created, c)
+	return c, nil
+}
+
+// forget drops categories whose creation was rolled back
+func (run *importRun) forget(categories []*models.Category) {
+	for _, c := range categories {
+		delete(run.categories, c.ID)
+		delete(run.byName, categoryKey(c.Name))
+	}
+}
+
+// atomic runs fn in a unit of work, or on the service's own repositories
+// (every call committed on its own) when it has none
+func (s *ImportService) atomic(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	if s.Units == nil {
+		return fn(&repository.Repositories{Transactions: s.TxRepo, Categories: s.CategoryRepo})
+	}
+	return s.Units.Do(ctx, fn)
+}
+
+// categoryKey compares category names case-insensitively and ignoring
+// punctuation, so "Dining out" matches the "Dining-out" of a journal account.
+func categoryKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
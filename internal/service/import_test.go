@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/importer"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCategoryRepo struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepo) CreateCategory(ctx context.Context, c *models.Category) error {
+	args := m.Called(ctx, c)
+	c.ID = uuid.New()
+	return args.Error(0)
+}
+func (m *MockCategoryRepo) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Category, error) {
+	return nil, nil // Not used in this test
+}
+func (m *MockCategoryRepo) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, versions []int, reassignTo *uuid.UUID) error {
+	return nil // Not used in this test
+}
+func (m *MockCategoryRepo) ListCategories(ctx context.Context, userID uuid.UUID) ([]*models.Category, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Category), args.Error(1)
+}
+
+func TestImportService(t *testing.T) {
+	userID := uuid.New()
+	groceries := &models.Category{ID: uuid.New(), UserId: userID, Name: "Groceries", Type: "expense"}
+	day := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	batch := &importer.Batch{
+		Format: "ofx",
+		Records: []*importer.Record{
+			{ExternalID: "ofx:1", Date: day, Amount: -4250, Description: "LIDL Berlin"},
+			{ExternalID: "ofx:2", Date: day, Amount: 250000, Description: "ACME Payroll"},
+			{ExternalID: "ofx:3", Date: day, Amount: -999, Description: "Already there"},
+		},
+		Errors: []*models.ImportError{{Entry: 4, Reference: "X", Message: "invalid amount"}},
+	}
+
+	txRepo := new(MockRepo)
+	txRepo.On("GetTransactionByExternalID", mock.Anything, userID, "ofx:3").Return(&models.Transaction{}, nil)
+	txRepo.On("GetTransactionByExternalID", mock.Anything, userID, mock.Anything).Return(nil, repository.ErrNotFound)
+	txRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(nil)
+
+	catRepo := new(MockCategoryRepo)
+	catRepo.On("ListCategories", mock.Anything, userID).Return([]*models.Category{groceries}, nil)
+	catRepo.On("CreateCategory", mock.Anything, mock.MatchedBy(func(c *models.Category) bool {
+		return c.Name == UncategorizedIncome && c.Type == "income"
+	})).Return(nil).Once()
+
+	ruleRepo := new(MockRuleRepo)
+	ruleRepo.On("ListRules", mock.Anything, userID).Return([]*models.Rule{
+		{ID: uuid.New(), Enabled: true, MatchType: MatchContains, DescriptionPattern: "lidl", SetCategoryId: &groceries.ID},
+	}, nil)
+
+	s := &ImportService{
+		TxRepo:       txRepo,
+		CategoryRepo: catRepo,
+		Rules:        &RuleService{Repo: ruleRepo, TxRepo: txRepo},
+	}
+
+	report, err := s.Import(context.Background(), userID, batch)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Errors, 1)
+
+	// Amounts are stored unsigned, the rule picked the category of the first row
+	created := txRepo.Calls[1].Arguments.Get(1).(*models.Transaction)
+	assert.Equal(t, int64(4250), created.Amount)
+	assert.Equal(t, groceries.ID, *created.CategoryId)
+	assert.Equal(t, "ofx:1", *created.ExternalID)
+
+	catRepo.AssertExpectations(t)
+}
+
+func TestCategoryKey(t *testing.T) {
+	assert.Equal(t, categoryKey("Dining out"), categoryKey("Dining-out"))
+	assert.Equal(t, categoryKey("Food & Drinks"), categoryKey("food-drinks"))
+	assert.NotEqual(t, categoryKey(UncategorizedExpense), categoryKey(UncategorizedIncome))
+}
+
+func TestImportServiceCreatesCategories(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+	dining := &models.Category{ID: uuid.New(), UserId: userID, Name: "Dining out", Type: "expense"}
+
+	batch := &importer.Batch{
+		Format: "ynab",
+		Records: []*importer.Record{
+			{ExternalID: "ynab:1", Date: day, Amount: -3800, Description: "Pizzeria", Category: "Dining-out", CategoryType: "expense"},
+			{ExternalID: "ynab:2", Date: day, Amount: -1599, Description: "Netflix", Category: "Streaming", CategoryType: "expense"},
+			{ExternalID: "ynab:3", Date: day, Amount: -999, Description: "Spotify", Category: "streaming", CategoryType: "expense"},
+		},
+		Skipped: 2,
+	}
+
+	txRepo := new(MockRepo)
+	txRepo.On("GetTransactionByExternalID", mock.Anything, userID, mock.Anything).Return(nil, repository.ErrNotFound)
+	txRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(nil)
+
+	catRepo := new(MockCategoryRepo)
+	catRepo.On("ListCategories", mock.Anything, userID).Return([]*models.Category{dining}, nil)
+	catRepo.On("CreateCategory", mock.Anything, mock.MatchedBy(func(c *models.Category) bool {
+		return c.Name == "Streaming" && c.Type == "expense"
+	})).Return(nil).Once()
+
+	s := &ImportService{TxRepo: txRepo, CategoryRepo: catRepo}
+	report, err := s.Import(context.Background(), userID, batch)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, []string{"Streaming"}, report.CategoriesCreated)
+
+	// Existing categories match ignoring punctuation
+	created := txRepo.Calls[1].Arguments.Get(1).(*models.Transaction)
+	assert.Equal(t, dining.ID, *created.CategoryId)
+
+	catRepo.AssertExpectations(t)
+}
+
+func TestImportServiceExportedJournal(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+	dining := &models.Category{ID: uuid.New(), UserId: userID, Name: "Dining out", Type: "expense"}
+	existing := &models.Transaction{ID: uuid.New(), UserId: userID}
+	unknown := uuid.New()
+
+	// Re-importing our own export: the first transaction is still there, the second is not
+	batch := &importer.Batch{
+		Format: "journal",
+		Records: []*importer.Record{
+			{ExternalID: "journal:" + existing.ID.String(), TransactionID: &existing.ID, Date: day, Amount: -3800, Description: "Pizzeria", Category: "Dining-out"},
+			{ExternalID: "journal:" + unknown.String(), TransactionID: &unknown, Date: day, Amount: -1200, Description: "Cafe", Category: "Dining-out"},
+		},
+	}
+
+	txRepo := new(MockRepo)
+	txRepo.On("GetTransaction", mock.Anything, userID, existing.ID).Return(existing, nil)
+	txRepo.On("GetTransaction", mock.Anything, userID, unknown).Return(nil, repository.ErrNotFound)
+	txRepo.On("GetTransactionByExternalID", mock.Anything, userID, "journal:"+unknown.String()).Return(nil, repository.ErrNotFound)
+	txRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(nil).Once()
+
+	catRepo := new(MockCategoryRepo)
+	catRepo.On("ListCategories", mock.Anything, userID).Return([]*models.Category{dining}, nil)
+
+	s := &ImportService{TxRepo: txRepo, CategoryRepo: catRepo}
+	report, err := s.Import(context.Background(), userID, batch)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Created)
+	txRepo.AssertExpectations(t)
+}
+
+// fakeUnitOfWork hands out the same repositories to every unit and counts the rollbacks
+type fakeUnitOfWork struct {
+	repos     *repository.Repositories
+	rollbacks int
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	if err := fn(u.repos); err != nil {
+		u.rollbacks++
+		return err
+	}
+	return nil
+}
+
+func TestImportServiceUnitOfWork(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	batch := &importer.Batch{
+		Format: "ynab",
+		Records: []*importer.Record{
+			{ExternalID: "ynab:1", Date: day, Amount: -12000, Description: "Train tickets", Category: "Travel", CategoryType: "expense"},
+			{ExternalID: "ynab:2", Date: day, Amount: -8900, Description: "Hotel", Category: "Travel", CategoryType: "expense"},
+		},
+	}
+
+	txRepo := new(MockRepo)
+	txRepo.On("GetTransactionByExternalID", mock.Anything, userID, mock.Anything).Return(nil, repository.ErrNotFound)
+	txRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(errors.New("connection reset")).Once()
+	txRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*models.Transaction")).Return(nil)
+
+	catRepo := new(MockCategoryRepo)
+	catRepo.On("ListCategories", mock.Anything, userID).Return([]*models.Category{}, nil)
+	catRepo.On("CreateCategory", mock.Anything, mock.MatchedBy(func(c *models.Category) bool {
+		return c.Name == "Travel"
+	})).Return(nil).Twice()
+
+	units := &fakeUnitOfWork{repos: &repository.Repositories{Transactions: txRepo, Categories: catRepo}}
+	s := &ImportService{TxRepo: txRepo, CategoryRepo: catRepo, Units: units}
+	report, err := s.Import(context.Background(), userID, batch)
+	require.NoError(t, err)
+
+	// The category of the failed row was rolled back with it, the next row creates it again
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, []string{"Travel"}, report.CategoriesCreated)
+	assert.Equal(t, 1, units.rollbacks)
+
+	created := txRepo.Calls[len(txRepo.Calls)-1].Arguments.Get(1).(*models.Transaction)
+	assert.Equal(t, int64(8900), created.Amount)
+	assert.Equal(t, catRepo.Calls[2].Arguments.Get(1).(*models.Category).ID, *created.CategoryId)
+
+	catRepo.AssertExpectations(t)
+}
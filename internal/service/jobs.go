@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// ErrJobPermanent marks a job failure that retrying cannot fix. Handlers wrap
+// it (fmt.Errorf("%w: ...", ErrJobPermanent)) to dead-letter the job right away.
+var ErrJobPermanent = errors.New("permanent failure")
+
+// JobHandler runs one attempt of a job. ctx is done when the job's lease ends.
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// JobQueue stores background jobs in Postgres and runs them with the handler
+// registered for their type. Failed attempts are retried with exponential
+// backoff; jobs out of attempts are kept as dead for inspection and retry.
+type JobQueue struct {
+	Repo repository.JobRepository
+
+	Workers      int           // Jobs run at the same time by Run. Defaults to 2
+	PollInterval time.Duration // Defaults to 5s
+	Lease        time.Duration // Time limit of one attempt. Defaults to 10m
+	MaxAttempts  int           // For jobs enqueued without their own. Defaults to 5
+	BaseDelay    time.Duration // Before the 2nd attempt, doubled after each failure. Defaults to 30s
+	MaxDelay     time.Duration // Defaults to 1h
+
+	Now func() time.Time // Defaults to time.Now
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	wake     chan struct{}
+}
+
+func (q *JobQueue) now() time.Time {
+	if q.Now != nil {
+		return q.Now()
+	}
+	return time.Now()
+}
+
+// Register sets the handler of a job type. Only registered types are claimed,
+// so processes with different handlers can share the table.
+func (q *JobQueue) Register(jobType string, handle JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.handlers == nil {
+		q.handlers = make(map[string]JobHandler)
+	}
+	q.handlers[jobType] = handle
+}
+
+// RegisterJob registers a handler receiving the decoded payload. A payload
+// that does not decode into T fails the job permanently.
+func RegisterJob[T any](q *JobQueue, jobType string, handle func(ctx context.Context, job *models.Job, payload T) error) {
+	q.Register(jobType, func(ctx context.Context, job *models.Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: invalid payload: %v", ErrJobPermanent, err)
+		}
+		return handle(ctx, job, payload)
+	})
+}
+
+// JobOptions are the optional settings of an enqueued job
+type JobOptions struct {
+	UserID      *uuid.UUID // Makes the job visible to the user, nil for system jobs
+	RunAt       time.Time  // Defaults to now
+	MaxAttempts int        // Defaults to the queue's MaxAttempts
+}
+
+// Enqueue stores a job of the given type; payload is serialized as JSON
+func (q *JobQueue) Enqueue(ctx context.Context, jobType string, payload any, opts JobOptions) (*models.Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &models.Job{
+		UserId:      opts.UserID,
+		Type:        jobType,
+		Payload:     raw,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = q.maxAttempts()
+	}
+	if j.RunAt.IsZero() {
+		j.RunAt = q.now()
+	}
+	if err := q.Repo.EnqueueJob(ctx, j); err != nil {
+		return nil, err
+	}
+
+	// Let an idle local worker pick it up without waiting for the next poll
+	if !j.RunAt.After(q.now()) {
+		select {
+		case q.wakeChan() <- struct{}{}:
+		default:
+		}
+	}
+	return j, nil
+}
+
+// ProcessNext claims one due job and runs it. It reports whether there was one.
+func (q *JobQueue) ProcessNext(ctx context.Context) (bool, error) {
+	types := q.types()
+	if len(types) == 0 {
+		return false, nil
+	}
+
+	jobs, err := q.Repo.ClaimJobs(ctx, q.now(), q.lease(), types, 1)
+	if err != nil || len(jobs) == 0 {
+		return false, err
+	}
+	return true, q.run(ctx, jobs[0])
+}
+
+// Run starts the workers and blocks until ctx is done and the jobs in progress finished
+func (q *JobQueue) Run(ctx context.Context) {
+	workers := q.Workers
+	if workers <= 0 {
+		workers = 2
+	}
+	interval := q.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, interval)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *JobQueue) work(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-q.wakeChan():
+		}
+
+		// Drain the queue, then wait for the next poll or enqueue
+		for ctx.Err() == nil {
+			// Jobs in progress finish even when shutting down
+			found, err := q.ProcessNext(context.WithoutCancel(ctx))
+			if err != nil {
+				log.Printf("jobs: %v", err)
+			}
+			if err != nil || !found {
+				break
+			}
+		}
+		timer.Reset(interval)
+	}
+}
+
+// run executes one claimed attempt and stores the outcome. Only a failure to store is returned.
+func (q *JobQueue) run(ctx context.Context, j *models.Job) error {
+	var err error
+	if j.Attempts > j.MaxAttempts {
+		// Its worker died during the last attempt
+		err = errors.New("lease expired on the last attempt")
+	} else {
+		err = q.call(ctx, j)
+	}
+
+	now := q.now()
+	j.LastError = nil
+	switch {
+	case err == nil:
+		j.Status = models.JobStatusSucceeded
+		j.FinishedAt = &now
+	case errors.Is(err, ErrJobPermanent) || j.Attempts >= j.MaxAttempts:
+		msg := err.Error()
+		j.LastError = &msg
+		j.Status = models.JobStatusDead
+		j.FinishedAt = &now
+		log.Printf("jobs: %s %s is dead after %d attempt(s): %v", j.Type, j.ID, j.Attempts, err)
+	default:
+		msg := err.Error()
+		j.LastError = &msg
+		j.Status = models.JobStatusQueued
+		j.RunAt = now.Add(q.backoff(j.Attempts))
+	}
+
+	if err := q.Repo.SaveJobResult(ctx, j); err != nil {
+		if errors.Is(err, repository.ErrLeaseLost) {
+			// Another worker claimed it meanwhile, its attempt's result counts
+			log.Printf("jobs: %s %s lost its lease on attempt %d, result discarded", j.Type, j.ID, j.Attempts)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// call runs the job's handler within its lease, turning a panic into an error
+func (q *JobQueue) call(ctx context.Context, j *models.Job) (err error) {
+	q.mu.RLock()
+	handle, ok := q.handlers[j.Type]
+	q.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: no handler for job type %q", ErrJobPermanent, j.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.lease())
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("jobs: %s %s panicked: %v\n%s", j.Type, j.ID, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handle(ctx, j)
+}
+
+func (q *JobQueue) types() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	types := make([]string, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (q *JobQueue) wakeChan() chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.wake == nil {
+		q.wake = make(chan struct{}, 1)
+	}
+	return q.wake
+}
+
+func (q *JobQueue) lease() time.Duration {
+	if q.Lease > 0 {
+		return q.Lease
+	}
+	return 10 * time.Minute
+}
+
+func (q *JobQueue) maxAttempts() int {
+	if q.MaxAttempts > 0 {
+		return q.MaxAttempts
+	}
+	return 5
+}
+
+func (q *JobQueue) backoff(attempts int) time.Duration {
+	base, maxDelay := q.BaseDelay, q.MaxDelay
+	if base <= 0 {
+		base = 30 * time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = time.Hour
+	}
+	return backoff(base, maxDelay, attempts)
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memJobRepo keeps jobs in memory, claiming them like the Postgres query does.
+// Workers get copies, results are written back to the enqueued job.
+type memJobRepo struct {
+	repository.JobRepository
+	mu          sync.Mutex
+	jobs        []*models.Job
+	lockedUntil map[uuid.UUID]time.Time
+}
+
+func (r *memJobRepo) EnqueueJob(ctx context.Context, j *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j.ID = uuid.New()
+	j.Status = models.JobStatusQueued
+	r.jobs = append(r.jobs, j)
+	return nil
+}
+
+func (r *memJobRepo) ClaimJobs(ctx context.Context, now time.Time, lease time.Duration, types []string, limit int) ([]*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lockedUntil == nil {
+		r.lockedUntil = make(map[uuid.UUID]time.Time)
+	}
+	var out []*models.Job
+	for _, j := range r.jobs {
+		due := (j.Status == models.JobStatusQueued && !j.RunAt.After(now)) ||
+			(j.Status == models.JobStatusRunning && !r.lockedUntil[j.ID].After(now))
+		if len(out) < limit && due && slices.Contains(types, j.Type) {
+			j.Status = models.JobStatusRunning
+			j.Attempts++
+			r.lockedUntil[j.ID] = now.Add(lease)
+			claimed := *j
+			out = append(out, &claimed)
+		}
+	}
+	return out, nil
+}
+
+func (r *memJobRepo) SaveJobResult(ctx context.Context, j *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.jobs {
+		if stored.ID == j.ID {
+			if stored.Status != models.JobStatusRunning || stored.Attempts != j.Attempts {
+				return repository.ErrLeaseLost
+			}
+			*stored = *j
+			delete(r.lockedUntil, j.ID)
+			return nil
+		}
+	}
+	return repository.ErrLeaseLost
+}
+
+func newJobQueue() (*JobQueue, *memJobRepo, *time.Time) {
+	repo := &memJobRepo{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	q := &JobQueue{
+		Repo:        repo,
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		Now:         func() time.Time { return now },
+	}
+	return q, repo, &now
+}
+
+type exportPayload struct {
+	Format string `json:"format"`
+}
+
+func TestJobQueue_TypedHandler(t *testing.T) {
+	ctx := context.Background()
+	q, _, _ := newJobQueue()
+
+	var got []string
+	RegisterJob(q, "export", func(ctx context.Context, job *models.Job, p exportPayload) error {
+		got = append(got, p.Format)
+		return nil
+	})
+
+	userID := uuid.New()
+	j, err := q.Enqueue(ctx, "export", exportPayload{Format: "csv"}, JobOptions{UserID: &userID})
+	require.NoError(t, err)
+	assert.Equal(t, 3, j.MaxAttempts)
+	assert.Equal(t, &userID, j.UserId)
+
+	// Types without a handler are left for another process
+	_, err = q.Enqueue(ctx, "import", map[string]string{}, JobOptions{})
+	require.NoError(t, err)
+
+	found, err := q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	assert.Equal(t, []string{"csv"}, got)
+	assert.Equal(t, models.JobStatusSucceeded, j.Status)
+	assert.Equal(t, 1, j.Attempts)
+	assert.NotNil(t, j.FinishedAt)
+	assert.Nil(t, j.LastError)
+}
+
+func TestJobQueue_RunAt(t *testing.T) {
+	ctx := context.Background()
+	q, _, now := newJobQueue()
+	q.Register("reminder", func(ctx context.Context, job *models.Job) error { return nil })
+
+	j, err := q.Enqueue(ctx, "reminder", nil, JobOptions{RunAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	found, err := q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, found, "not due yet")
+
+	*now = now.Add(time.Hour)
+	found, err = q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, models.JobStatusSucceeded, j.Status)
+}
+
+func TestJobQueue_RetriesThenDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	q, _, now := newJobQueue()
+
+	calls := 0
+	q.Register("flaky", func(ctx context.Context, job *models.Job) error {
+		calls++
+		return errors.New("connection refused")
+	})
+	j, err := q.Enqueue(ctx, "flaky", nil, JobOptions{})
+	require.NoError(t, err)
+
+	// 1st failure: back to the queue after BaseDelay, +-20%
+	_, err = q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusQueued, j.Status)
+	require.NotNil(t, j.LastError)
+	assert.Equal(t, "connection refused", *j.LastError)
+	delay := j.RunAt.Sub(*now)
+	assert.GreaterOrEqual(t, delay, 48*time.Second)
+	assert.LessOrEqual(t, delay, 72*time.Second)
+
+	found, err := q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, found, "waiting for the retry")
+
+	for range 2 {
+		*now = j.RunAt
+		_, err = q.ProcessNext(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, models.JobStatusDead, j.Status)
+	assert.NotNil(t, j.FinishedAt)
+
+	*now = now.Add(24 * time.Hour)
+	found, err = q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestJobQueue_PermanentFailures(t *testing.T) {
+	ctx := context.Background()
+	q, _, _ := newJobQueue()
+
+	q.Register("permanent", func(ctx context.Context, job *models.Job) error {
+		return fmt.Errorf("%w: account closed", ErrJobPermanent)
+	})
+	q.Register("panics", func(ctx context.Context, job *models.Job) error {
+		panic("nil map")
+	})
+	RegisterJob(q, "typed", func(ctx context.Context, job *models.Job, p exportPayload) error {
+		return nil
+	})
+
+	permanent, _ := q.Enqueue(ctx, "permanent", nil, JobOptions{})
+	panics, _ := q.Enqueue(ctx, "panics", nil, JobOptions{})
+	badPayload, _ := q.Enqueue(ctx, "typed", []int{1}, JobOptions{})
+
+	for range 3 {
+		_, err := q.ProcessNext(ctx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, models.JobStatusDead, permanent.Status)
+	assert.Equal(t, 1, permanent.Attempts)
+	assert.Equal(t, models.JobStatusQueued, panics.Status, "a panic is retried like any error")
+	assert.Contains(t, *panics.LastError, "nil map")
+	assert.Equal(t, models.JobStatusDead, badPayload.Status)
+	assert.Contains(t, *badPayload.LastError, "invalid payload")
+}
+
+func TestJobQueue_ExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	q, repo, now := newJobQueue()
+	q.Lease = time.Minute
+	calls := 0
+	q.Register("slow", func(ctx context.Context, job *models.Job) error {
+		calls++
+		return nil
+	})
+
+	j, _ := q.Enqueue(ctx, "slow", nil, JobOptions{MaxAttempts: 1})
+	// A worker claims the job and dies
+	_, err := repo.ClaimJobs(ctx, *now, q.Lease, []string{"slow"}, 1)
+	require.NoError(t, err)
+
+	found, err := q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, found, "still leased")
+
+	*now = now.Add(2 * time.Minute)
+	found, err = q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 0, calls, "out of attempts, not run again")
+	assert.Equal(t, models.JobStatusDead, j.Status)
+}
+
+func TestJobQueue_LostLease(t *testing.T) {
+	ctx := context.Background()
+	q, repo, now := newJobQueue()
+	q.Lease = time.Minute
+	var attempts []int
+	q.Register("slow", func(ctx context.Context, job *models.Job) error {
+		attempts = append(attempts, job.Attempts)
+		if job.Attempts == 1 {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+
+	j, _ := q.Enqueue(ctx, "slow", nil, JobOptions{})
+	// A worker claims the job and stalls past its lease
+	stalled, err := repo.ClaimJobs(ctx, *now, q.Lease, []string{"slow"}, 1)
+	require.NoError(t, err)
+
+	*now = now.Add(2 * time.Minute)
+	found, err := q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, models.JobStatusSucceeded, j.Status)
+
+	// The stalled worker's late failure must not overwrite the result
+	require.NoError(t, q.run(ctx, stalled[0]))
+	assert.Equal(t, []int{2, 1}, attempts)
+	assert.Equal(t, models.JobStatusSucceeded, j.Status)
+	assert.Equal(t, 2, j.Attempts)
+	assert.Nil(t, j.LastError)
+}
+
+func TestJobQueue_Run(t *testing.T) {
+	q, repo, _ := newJobQueue()
+	q.Now = nil
+	q.Workers = 3
+	q.PollInterval = time.Hour // Enqueue wakes the workers
+
+	var mu sync.Mutex
+	done := make(map[int]bool)
+	RegisterJob(q, "count", func(ctx context.Context, job *models.Job, n int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		done[n] = true
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stopped)
+	}()
+
+	for n := range 10 {
+		_, err := q.Enqueue(context.Background(), "count", n, JobOptions{})
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(done) == 10
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, j := range repo.jobs {
+		assert.Equal(t, models.JobStatusSucceeded, j.Status)
+	}
+}
+
+func TestNotificationServiceNotify_Jobs(t *testing.T) {
+	ctx := context.Background()
+	q, repo, _ := newJobQueue()
+	email := &recordingDispatcher{channel: notify.ChannelEmail}
+	s := &NotificationService{
+		Repo: &memNotificationRepo{prefs: []*models.NotificationPreference{
+			{Channel: notify.ChannelEmail, Enabled: true, Target: "alice@example.com"},
+		}},
+		Dispatchers: map[string]notify.Dispatcher{notify.ChannelEmail: email},
+		Jobs:        q,
+	}
+	s.RegisterJobs(q)
+
+	userID := uuid.New()
+	require.NoError(t, s.Notify(ctx, &models.Notification{UserId: userID, Kind: models.NotificationKindBillReminder, Title: "Rent due tomorrow"}))
+	require.Len(t, repo.jobs, 1)
+	assert.Equal(t, JobNotificationDelivery, repo.jobs[0].Type)
+	assert.Equal(t, &userID, repo.jobs[0].UserId)
+	assert.Empty(t, email.sent, "sent by a worker, not by Notify")
+
+	found, err := q.ProcessNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []string{"alice@example.com Rent due tomorrow"}, email.sent)
+	assert.Equal(t, models.JobStatusSucceeded, repo.jobs[0].Status)
+}
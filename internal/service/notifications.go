@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/notify"
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+)
+
+// Upper bound for one delivery outside the job queue
+const deliveryTimeout = time.Minute
+
+// JobNotificationDelivery is the job type of one notification delivery
+const JobNotificationDelivery = "notification.deliver"
+
+// notificationDelivery is the payload of a JobNotificationDelivery job
+type notificationDelivery struct {
+	Channel string         `json:"channel"`
+	Target  string         `json:"target"`
+	Message notify.Message `json:"message"`
+}
+
+var notificationKinds = []string{
+	models.NotificationKindBudgetOverrun,
+	models.NotificationKindBillReminder,
+	models.NotificationKindAnomaly,
+}
+
+// NotificationService stores notifications in the inbox and fans them out to
+// the channels the user enabled. Deliveries run in the background so a slow
+// webhook never holds up the request that raised the notification: as jobs
+// when Jobs is set, which survive restarts and retry failed sends, or else
+// in goroutines that try once.
+type NotificationService struct {
+	Repo        repository.NotificationRepository
+	Dispatchers map[string]notify.Dispatcher // By channel
+	Jobs        *JobQueue                    // Optional, see RegisterJobs
+	Resolver    webhook.Resolver             // Optional, checks webhook hosts. Defaults to net.DefaultResolver
+
+	wg sync.WaitGroup
+}
+
+// Notify stores n (filling its ID and CreatedAt) and starts its deliveries.
+// A notification whose key the user already got is dropped.
+func (s *NotificationService) Notify(ctx context.Context, n *models.Notification) error {
+	if err := s.Repo.CreateNotification(ctx, n); err != nil {
+		if errors.Is(err, repository.ErrAlreadyQueued) {
+			return nil
+		}
+		return err
+	}
+
+	prefs, err := s.Repo.ListPreferences(ctx, n.UserId)
+	if err != nil {
+		return err
+	}
+
+	msg := notify.Message{
+		ID:        n.ID.String(),
+		Kind:      n.Kind,
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      n.Data,
+		CreatedAt: n.CreatedAt,
+	}
+	for _, p := range prefs {
+		d, ok := s.Dispatchers[p.Channel]
+		if !ok || !p.Enabled || (len(p.Kinds) > 0 && !slices.Contains(p.Kinds, n.Kind)) {
+			continue
+		}
+
+		if s.Jobs != nil {
+			delivery := notificationDelivery{Channel: p.Channel, Target: p.Target, Message: msg}
+			if _, err := s.Jobs.Enqueue(ctx, JobNotificationDelivery, delivery, JobOptions{UserID: &n.UserId}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(d notify.Dispatcher, target string) {
+			defer s.wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+			defer cancel()
+			if err := d.Send(ctx, target, msg); err != nil {
+				log.Printf("notification %s: %s delivery failed: %v", msg.ID, d.Channel(), err)
+			}
+		}(d, p.Target)
+	}
+	return nil
+}
+
+// RegisterJobs registers the delivery job handler; the queue may be run by another process
+func (s *NotificationService) RegisterJobs(q *JobQueue) {
+	RegisterJob(q, JobNotificationDelivery, func(ctx context.Context, job *models.Job, p notificationDelivery) error {
+		d, ok := s.Dispatchers[p.Channel]
+		if !ok {
+			return fmt.Errorf("%w: channel %q is not available", ErrJobPermanent, p.Channel)
+		}
+		if err := d.Send(ctx, p.Target, p.Message); err != nil {
+			if notify.IsPermanent(err) {
+				return fmt.Errorf("%w: %w", ErrJobPermanent, err)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// Wait blocks until the background deliveries started so far are done
+func (s *NotificationService) Wait() {
+	s.wg.Wait()
+}
+
+// ValidatePreferences checks that every channel is available, listed once,
+// has a usable target and only names known kinds
+func (s *NotificationService) ValidatePreferences(ctx context.Context, prefs []*models.NotificationPreference) error {
+	seen := make(map[string]bool)
+	for _, p := range prefs {
+		if _, ok := s.Dispatchers[p.Channel]; !ok {
+			return fmt.Errorf("channel %q is not available", p.Channel)
+		}
+		if seen[p.Channel] {
+			return fmt.Errorf("channel %q is listed twice", p.Channel)
+		}
+		seen[p.Channel] = true
+
+		switch p.Channel {
+		case notify.ChannelEmail:
+			if _, err := mail.ParseAddress(p.Target); err != nil {
+				return fmt.Errorf("invalid email address %q", p.Target)
+			}
+		case notify.ChannelWebhook:
+			// Same rules as webhook endpoints: no internal addresses
+			if err := webhook.CheckURL(ctx, s.Resolver, p.Target); err != nil {
+				return err
+			}
+		}
+
+		for _, kind := range p.Kinds {
+			if !slices.Contains(notificationKinds, kind) {
+				return fmt.Errorf("unknown notification kind %q", kind)
+			}
+		}
+	}
+	return nil
+}
+
+// ListNotifications returns the inbox, newest first, with the unread count
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, int, error) {
+	notifications, err := s.Repo.ListNotifications(ctx, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.Repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memNotificationRepo struct {
+	repository.NotificationRepository
+	rows  []*models.Notification
+	prefs []*models.NotificationPreference
+}
+
+func (r *memNotificationRepo) CreateNotification(ctx context.Context, n *models.Notification) error {
+	for _, row := range r.rows {
+		if n.Key != "" && row.UserId == n.UserId && row.Key == n.Key {
+			return repository.ErrAlreadyQueued
+		}
+	}
+	n.ID = uuid.New()
+	r.rows = append(r.rows, n)
+	return nil
+}
+
+func (r *memNotificationRepo) ListPreferences(ctx context.Context, userID uuid.UUID) ([]*models.NotificationPreference, error) {
+	return r.prefs, nil
+}
+
+type recordingDispatcher struct {
+	channel string
+	mu      sync.Mutex
+	sent    []string // "target title" of each send
+}
+
+func (d *recordingDispatcher) Channel() string { return d.channel }
+
+func (d *recordingDispatcher) Send(ctx context.Context, target string, msg notify.Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sent = append(d.sent, target+" "+msg.Title)
+	return nil
+}
+
+func TestNotificationServiceNotify(t *testing.T) {
+	email := &recordingDispatcher{channel: notify.ChannelEmail}
+	webhook := &recordingDispatcher{channel: notify.ChannelWebhook}
+	repo := &memNotificationRepo{prefs: []*models.NotificationPreference{
+		{Channel: notify.ChannelEmail, Enabled: true, Target: "alice@example.com"},
+		{Channel: notify.ChannelWebhook, Enabled: true, Target: "https://example.com/hook", Kinds: []string{models.NotificationKindAnomaly}},
+		{Channel: notify.ChannelLog, Enabled: true}, // Not configured on this server
+	}}
+	s := &NotificationService{
+		Repo:        repo,
+		Dispatchers: map[string]notify.Dispatcher{notify.ChannelEmail: email, notify.ChannelWebhook: webhook},
+	}
+
+	n := &models.Notification{UserId: uuid.New(), Kind: models.NotificationKindBillReminder, Title: "Rent due tomorrow"}
+	require.NoError(t, s.Notify(context.Background(), n))
+	s.Wait()
+	assert.NotEqual(t, uuid.Nil, n.ID)
+	assert.Len(t, repo.rows, 1)
+	assert.Equal(t, []string{"alice@example.com Rent due tomorrow"}, email.sent)
+	assert.Empty(t, webhook.sent) // Only subscribed to anomalies
+
+	repo.prefs[0].Enabled = false
+	require.NoError(t, s.Notify(context.Background(), &models.Notification{Kind: models.NotificationKindAnomaly, Title: "Unusual spending"}))
+	s.Wait()
+	assert.Len(t, repo.rows, 2)
+	assert.Len(t, email.sent, 1)
+	assert.Equal(t, []string{"https://example.com/hook Unusual spending"}, webhook.sent)
+}
+
+func TestValidatePreferences(t *testing.T) {
+	s := &NotificationService{
+		Dispatchers: map[string]notify.Dispatcher{
+			notify.ChannelEmail:   &recordingDispatcher{channel: notify.ChannelEmail},
+			notify.ChannelWebhook: &recordingDispatcher{channel: notify.ChannelWebhook},
+		},
+		Resolver: fakeResolver{
+			"example.com":      {netip.MustParseAddr("93.184.215.14")},
+			"internal.example": {netip.MustParseAddr("192.168.1.10")},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		prefs   []*models.NotificationPreference
+		wantErr bool
+	}{
+		{"valid", []*models.NotificationPreference{
+			{Channel: "email", Target: "alice@example.com"},
+			{Channel: "webhook", Target: "https://example.com/hook", Kinds: []string{"anomaly"}},
+		}, false},
+		{"none", nil, false},
+		{"unavailable channel", []*models.NotificationPreference{{Channel: "log"}}, true},
+		{"listed twice", []*models.NotificationPreference{
+			{Channel: "email", Target: "alice@example.com"}, {Channel: "email", Target: "bob@example.com"},
+		}, true},
+		{"bad email", []*models.NotificationPreference{{Channel: "email", Target: "alice"}}, true},
+		{"bad url", []*models.NotificationPreference{{Channel: "webhook", Target: "example.com/hook"}}, true},
+		{"internal url", []*models.NotificationPreference{{Channel: "webhook", Target: "http://internal.example/hook"}}, true},
+		{"metadata url", []*models.NotificationPreference{{Channel: "webhook", Target: "http://169.254.169.254/latest"}}, true},
+		{"unknown kind", []*models.NotificationPreference{{Channel: "email", Target: "alice@example.com", Kinds: []string{"spam"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidatePreferences(context.Background(), tt.prefs)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	outboxBatch = 100
+	// Claimed events are hidden from other relays this long
+	outboxLease = time.Minute
+)
+
+// OutboxRelay publishes the events recorded in the outbox to the sink, at
+// least once: an event is deleted only after every sink accepted it, and a
+// failed publish is retried later with backoff, so sinks may see an event
+// again. Events are published in commit order except around retries.
+type OutboxRelay struct {
+	Repo   repository.OutboxRepository
+	Sink   EventPublisher              // Usually EventPublishers of webhooks, SSE and log
+	PubSub repository.PubSubRepository // Optional, wakes the relay when events commit instead of at the next poll
+
+	BaseDelay time.Duration // Before the 2nd attempt, doubled after each failure. Defaults to 5s
+	MaxDelay  time.Duration // Defaults to 10m
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (r *OutboxRelay) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// ProcessPending publishes a batch of pending events and returns how many were claimed
+func (r *OutboxRelay) ProcessPending(ctx context.Context) (int, error) {
+	events, err := r.Repo.ClaimEvents(ctx, r.now(), outboxLease, outboxBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range events {
+		if err := r.Sink.Publish(ctx, e.UserId, e.Event); err != nil {
+			log.Printf("outbox: publish %s %s (attempt %d): %v", e.Event.Type, e.Event.ID, e.Attempts, err)
+			retryAt := r.now().Add(r.backoff(e.Attempts))
+			if err := r.Repo.ReleaseEvent(ctx, e.Seq, retryAt, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := r.Repo.DeleteEvent(ctx, e.Seq); err != nil {
+			return 0, err
+		}
+	}
+	return len(events), nil
+}
+
+// Run publishes pending events until ctx is done, polling every interval and
+// whenever a transaction that recorded events commits
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	wake := make(chan struct{}, 1)
+	if r.PubSub != nil {
+		go listen(ctx, r.PubSub, repository.OutboxChannel, func(string) {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Drain full batches right away, wait when the outbox is empty
+		for {
+			n, err := r.ProcessPending(ctx)
+			if err != nil {
+				log.Printf("outbox: %v", err)
+			}
+			if err != nil || n < outboxBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	base, maxDelay := r.BaseDelay, r.MaxDelay
+	if base <= 0 {
+		base = 5 * time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = 10 * time.Minute
+	}
+	return backoff(base, maxDelay, attempts)
+}
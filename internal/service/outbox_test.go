@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memOutboxRepo keeps recorded events in memory, claiming them like the Postgres query does
+type memOutboxRepo struct {
+	mu          sync.Mutex
+	seq         int64
+	events      []*models.OutboxEvent
+	lockedUntil map[int64]time.Time
+	lastError   map[int64]string
+}
+
+func (r *memOutboxRepo) record(userID uuid.UUID, eventType string, data any) *models.OutboxEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payload, _ := json.Marshal(data)
+	r.seq++
+	e := &models.OutboxEvent{
+		Seq:    r.seq,
+		UserId: userID,
+		Event:  &models.Event{ID: uuid.New(), Type: eventType, CreatedAt: time.Now().UTC(), Data: json.RawMessage(payload)},
+	}
+	r.events = append(r.events, e)
+	return e
+}
+
+func (r *memOutboxRepo) ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lockedUntil == nil {
+		r.lockedUntil = make(map[int64]time.Time)
+	}
+	var out []*models.OutboxEvent
+	for _, e := range r.events {
+		if len(out) < limit && !r.lockedUntil[e.Seq].After(now) {
+			e.Attempts++
+			r.lockedUntil[e.Seq] = now.Add(lease)
+			claimed := *e
+			out = append(out, &claimed)
+		}
+	}
+	return out, nil
+}
+
+func (r *memOutboxRepo) DeleteEvent(ctx context.Context, seq int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.events {
+		if e.Seq == seq {
+			r.events = append(r.events[:i], r.events[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *memOutboxRepo) ReleaseEvent(ctx context.Context, seq int64, retryAt time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lastError == nil {
+		r.lastError = make(map[int64]string)
+	}
+	r.lockedUntil[seq] = retryAt
+	r.lastError[seq] = lastError
+	return nil
+}
+
+// recordingSink records the events it gets and fails while err is set
+type recordingSink struct {
+	mu     sync.Mutex
+	err    error
+	events []*models.Event
+}
+
+func (s *recordingSink) Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func (s *recordingSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func TestOutboxRelay_PublishesInOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := &memOutboxRepo{}
+	sink := &recordingSink{}
+	r := &OutboxRelay{Repo: repo, Sink: sink}
+
+	userID := uuid.New()
+	repo.record(userID, models.EventCategoryCreated, &models.Category{Name: "Food"})
+	repo.record(userID, models.EventTransactionCreated, &models.Transaction{Amount: 1250})
+
+	n, err := r.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, models.EventCategoryCreated, sink.events[0].Type)
+	assert.Equal(t, models.EventTransactionCreated, sink.events[1].Type)
+	assert.Contains(t, string(sink.events[0].Data.(json.RawMessage)), `"name":"Food"`)
+	assert.Empty(t, repo.events, "published events are deleted")
+
+	n, err = r.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestOutboxRelay_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	repo := &memOutboxRepo{}
+	sink := &recordingSink{err: errors.New("connection refused")}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r := &OutboxRelay{Repo: repo, Sink: sink, BaseDelay: time.Minute, Now: func() time.Time { return now }}
+
+	recorded := repo.record(uuid.New(), models.EventTransactionUpdated, &models.Transaction{Amount: 1250})
+
+	// 1st failure: kept and hidden for BaseDelay, +-20%
+	_, err := r.ProcessPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, "connection refused", repo.lastError[recorded.Seq])
+	delay := repo.lockedUntil[recorded.Seq].Sub(now)
+	assert.GreaterOrEqual(t, delay, 48*time.Second)
+	assert.LessOrEqual(t, delay, 72*time.Second)
+
+	n, err := r.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "waiting for the retry")
+
+	// 2nd failure: the delay doubles
+	now = repo.lockedUntil[recorded.Seq]
+	_, err = r.ProcessPending(ctx)
+	require.NoError(t, err)
+	delay = repo.lockedUntil[recorded.Seq].Sub(now)
+	assert.GreaterOrEqual(t, delay, 96*time.Second)
+	assert.LessOrEqual(t, delay, 144*time.Second)
+
+	// The sink recovers and gets the same event again, with the same ID
+	sink.err = nil
+	now = repo.lockedUntil[recorded.Seq]
+	_, err = r.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, repo.events)
+	require.Len(t, sink.events, 3)
+	for _, e := range sink.events {
+		assert.Equal(t, recorded.Event.ID, e.ID)
+	}
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	repo := &memOutboxRepo{}
+	sink := &recordingSink{}
+	pubsub := newMemPubSub()
+	r := &OutboxRelay{Repo: repo, Sink: sink, PubSub: pubsub}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx, time.Hour)
+		close(done)
+	}()
+	<-pubsub.listening
+
+	// A commit notifies the channel, the relay does not wait for its next poll
+	repo.record(uuid.New(), models.EventCategoryCreated, &models.Category{Name: "Food"})
+	require.NoError(t, pubsub.Notify(ctx, repository.OutboxChannel, ""))
+	require.Eventually(t, func() bool { return sink.count() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
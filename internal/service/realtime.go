@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	// RealtimeChannel is the LISTEN/NOTIFY channel shared by the API instances
+	RealtimeChannel = "realtime_events"
+
+	// Events a connection may fall behind by before it is dropped
+	realtimeBuffer = 32
+	// NOTIFY payloads are limited to 8000 bytes, larger events are sent without data
+	realtimeMaxPayload = 7900
+)
+
+// Broker fans events out to the connected clients of each user in this process
+type Broker struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan *models.Event]struct{}
+}
+
+// Subscribe returns the events of the user and a function to stop receiving them.
+// The channel is closed when the client falls too far behind; it should
+// reconnect and reload what it shows.
+func (b *Broker) Subscribe(userID uuid.UUID) (<-chan *models.Event, func()) {
+	ch := make(chan *models.Event, realtimeBuffer)
+
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[uuid.UUID]map[chan *models.Event]struct{})
+	}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan *models.Event]struct{})
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, ch)
+	}
+}
+
+// Deliver sends the event to every client of the user without blocking
+func (b *Broker) Deliver(userID uuid.UUID, event *models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[userID] {
+		select {
+		case ch <- event:
+		default:
+			b.remove(userID, ch)
+		}
+	}
+}
+
+// remove closes ch once, the caller holds the lock
+func (b *Broker) remove(userID uuid.UUID, ch chan *models.Event) {
+	if _, ok := b.subs[userID][ch]; !ok {
+		return
+	}
+	delete(b.subs[userID], ch)
+	if len(b.subs[userID]) == 0 {
+		delete(b.subs, userID)
+	}
+	close(ch)
+}
+
+// realtimeMessage is what goes over the Postgres channel. Data stays raw
+// JSON so it reaches the clients exactly as it was published.
+type realtimeMessage struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (m *realtimeMessage) event() *models.Event {
+	return &models.Event{ID: m.ID, Type: m.Type, CreatedAt: m.CreatedAt, Data: m.Data}
+}
+
+// RealtimeService pushes data changes and fresh dashboard summaries to the
+// user's connected clients. With PubSub set the events go through Postgres,
+// so clients connected to any API instance receive them.
+type RealtimeService struct {
+	Broker    *Broker                     // Optional with PubSub when this process serves no clients
+	PubSub    repository.PubSubRepository // Optional, only this instance's clients are reached without it
+	Dashboard *DashboardService           // Optional, sends a summary after each transaction change
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (s *RealtimeService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// Publish sends the event, followed by the new dashboard summary for transaction changes
+func (s *RealtimeService) Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if err := s.send(ctx, &realtimeMessage{
+		UserID:    userID,
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      data,
+	}); err != nil {
+		return err
+	}
+	if s.Dashboard == nil || !strings.HasPrefix(event.Type, "transaction.") {
+		return nil
+	}
+
+	summary, err := s.Dashboard.GetUserSummary(ctx, userID)
+	if err != nil {
+		return err
+	}
+	msg, err := s.newMessage(userID, models.EventDashboardSummary, summary)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, msg)
+}
+
+// Summary returns the dashboard summary event sent to clients when they connect
+func (s *RealtimeService) Summary(ctx context.Context, userID uuid.UUID) (*models.Event, error) {
+	summary, err := s.Dashboard.GetUserSummary(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.newMessage(userID, models.EventDashboardSummary, summary)
+	if err != nil {
+		return nil, err
+	}
+	return msg.event(), nil
+}
+
+// Run receives the events published by every instance and delivers them to
+// this instance's clients until ctx is done. It returns right away without PubSub.
+func (s *RealtimeService) Run(ctx context.Context) {
+	if s.PubSub == nil {
+		return
+	}
+	listen(ctx, s.PubSub, RealtimeChannel, func(payload string) {
+		var msg realtimeMessage
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			log.Printf("realtime: invalid message: %v", err)
+			return
+		}
+		s.Broker.Deliver(msg.UserID, msg.event())
+	})
+}
+
+// listen calls handle with the messages of the channel until ctx is done,
+// reconnecting with backoff when the connection fails
+func listen(ctx context.Context, ps repository.PubSubRepository, channel string, handle func(payload string)) {
+	delay := time.Second
+	for {
+		err := ps.Listen(ctx, channel, func(payload string) {
+			delay = time.Second
+			handle(payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("listen %s: stopped, reconnecting in %s: %v", channel, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, time.Minute)
+	}
+}
+
+func (s *RealtimeService) newMessage(userID uuid.UUID, eventType string, data any) (*realtimeMessage, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &realtimeMessage{
+		UserID:    userID,
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: s.now().UTC(),
+		Data:      raw,
+	}, nil
+}
+
+func (s *RealtimeService) send(ctx context.Context, msg *realtimeMessage) error {
+	if s.PubSub == nil {
+		s.Broker.Deliver(msg.UserID, msg.event())
+		return nil
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > realtimeMaxPayload {
+		// Clients reload the changed data themselves
+		msg.Data = nil
+		if payload, err = json.Marshal(msg); err != nil {
+			return err
+		}
+	}
+	return s.PubSub.Notify(ctx, RealtimeChannel, string(payload))
+}
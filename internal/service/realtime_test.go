@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memPubSub stands in for LISTEN/NOTIFY: every listener gets every message
+type memPubSub struct {
+	mu        sync.Mutex
+	listeners []func(string)
+	payloads  []string
+	listening chan struct{}
+}
+
+func newMemPubSub() *memPubSub {
+	return &memPubSub{listening: make(chan struct{}, 1)}
+}
+
+func (p *memPubSub) Notify(ctx context.Context, channel, payload string) error {
+	p.mu.Lock()
+	p.payloads = append(p.payloads, payload)
+	listeners := append([]func(string){}, p.listeners...)
+	p.mu.Unlock()
+	for _, handle := range listeners {
+		handle(payload)
+	}
+	return nil
+}
+
+func (p *memPubSub) Listen(ctx context.Context, channel string, handle func(string)) error {
+	p.mu.Lock()
+	p.listeners = append(p.listeners, handle)
+	p.mu.Unlock()
+	p.listening <- struct{}{}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func receive(t *testing.T, events <-chan *models.Event) *models.Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		require.NotNil(t, e)
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := &Broker{}
+	alice, bob := uuid.New(), uuid.New()
+
+	phone, unsubscribePhone := b.Subscribe(alice)
+	laptop, unsubscribeLaptop := b.Subscribe(alice)
+	other, unsubscribeOther := b.Subscribe(bob)
+	defer unsubscribeOther()
+
+	b.Deliver(alice, &models.Event{Type: models.EventCategoryCreated})
+	assert.Equal(t, models.EventCategoryCreated, receive(t, phone).Type)
+	assert.Equal(t, models.EventCategoryCreated, receive(t, laptop).Type)
+	assert.Empty(t, other, "events only reach the user's own clients")
+
+	unsubscribePhone()
+	unsubscribePhone() // Safe to call twice
+	_, ok := <-phone
+	assert.False(t, ok)
+
+	// A client that stops reading is dropped instead of blocking everyone
+	for range realtimeBuffer + 1 {
+		b.Deliver(alice, &models.Event{Type: models.EventTransactionCreated})
+	}
+	for range realtimeBuffer {
+		receive(t, laptop)
+	}
+	_, ok = <-laptop
+	assert.False(t, ok)
+	unsubscribeLaptop()
+}
+
+func TestRealtimeService_Publish(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	repo := new(MockRepo)
+	repo.On("GetSummaryByType", mock.Anything, userID).Return(map[string]int64{"income": 5000, "expense": 1250}, nil)
+
+	s := &RealtimeService{Broker: &Broker{}, Dashboard: &DashboardService{Repo: repo}}
+	events, unsubscribe := s.Broker.Subscribe(userID)
+	defer unsubscribe()
+
+	t.Run("Transaction Changes Send A Summary", func(t *testing.T) {
+		tx := &models.Transaction{ID: uuid.New(), Amount: 1250}
+		require.NoError(t, s.Publish(ctx, userID, newEvent(models.EventTransactionCreated, tx)))
+
+		e := receive(t, events)
+		assert.Equal(t, models.EventTransactionCreated, e.Type)
+		var got models.Transaction
+		require.NoError(t, json.Unmarshal(e.Data.(json.RawMessage), &got))
+		assert.Equal(t, tx.ID, got.ID)
+
+		e = receive(t, events)
+		assert.Equal(t, models.EventDashboardSummary, e.Type)
+		var summary models.DashboardSummary
+		require.NoError(t, json.Unmarshal(e.Data.(json.RawMessage), &summary))
+		assert.Equal(t, int64(3750), summary.NetBalance)
+	})
+
+	t.Run("Category Changes Do Not", func(t *testing.T) {
+		require.NoError(t, s.Publish(ctx, userID, newEvent(models.EventCategoryCreated, &models.Category{Name: "Food"})))
+		assert.Equal(t, models.EventCategoryCreated, receive(t, events).Type)
+		assert.Empty(t, events)
+	})
+}
+
+func TestRealtimeService_PubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	userID := uuid.New()
+	pubsub := newMemPubSub()
+
+	// Two instances sharing the channel, the client is connected to the second one
+	publisher := &RealtimeService{Broker: &Broker{}, PubSub: pubsub}
+	receiver := &RealtimeService{Broker: &Broker{}, PubSub: pubsub}
+	events, unsubscribe := receiver.Broker.Subscribe(userID)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		receiver.Run(ctx)
+		close(done)
+	}()
+	<-pubsub.listening
+
+	category := &models.Category{ID: uuid.New(), Name: "Food", Type: "expense"}
+	require.NoError(t, publisher.Publish(ctx, userID, newEvent(models.EventCategoryCreated, category)))
+
+	e := receive(t, events)
+	assert.Equal(t, models.EventCategoryCreated, e.Type)
+	assert.NotEqual(t, uuid.Nil, e.ID)
+	var got models.Category
+	require.NoError(t, json.Unmarshal(e.Data.(json.RawMessage), &got))
+	assert.Equal(t, *category, got)
+
+	// Too large for NOTIFY: only the change itself is announced
+	big := &models.Transaction{ID: uuid.New(), Description: strings.Repeat("x", realtimeMaxPayload)}
+	require.NoError(t, publisher.Publish(ctx, userID, newEvent(models.EventTransactionUpdated, big)))
+	e = receive(t, events)
+	assert.Equal(t, models.EventTransactionUpdated, e.Type)
+	assert.JSONEq(t, "null", string(e.Data.(json.RawMessage)))
+	assert.Less(t, len(pubsub.payloads[1]), realtimeMaxPayload)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/internal/models"
+)
+
+// recurrence is an interval recurring items are matched against
+type recurrence struct {
+	name      string
+	days      int // Nominal length
+	months    int // Calendar months to add for the next date, 0 to add days
+	tolerance int // Days an interval may differ from the nominal length
+	minCount  int // Occurrences needed before trusting the pattern
+	perYear   int
+}
+
+var recurrences = []recurrence{
+	{name: "weekly", days: 7, tolerance: 1, minCount: 3, perYear: 52},
+	{name: "biweekly", days: 14, tolerance: 2, minCount: 3, perYear: 26},
+	{name: "monthly", days: 30, months: 1, tolerance: 4, minCount: 3, perYear: 12},
+	{name: "quarterly", days: 91, months: 3, tolerance: 7, minCount: 3, perYear: 4},
+	{name: "yearly", days: 365, months: 12, tolerance: 10, minCount: 2, perYear: 1},
+}
+
+// Occurrences at one price may differ this much from its first (utility bills
+// vary), a larger difference is a price change
+const recurringAmountTolerance = 0.25
+
+func (r recurrence) after(t time.Time) time.Time {
+	if r.months > 0 {
+		return t.AddDate(0, r.months, 0)
+	}
+	return t.AddDate(0, 0, r.days)
+}
+
+// DetectRecurring finds the transactions that repeat at a regular interval:
+// same type, category and merchant (the description without numbers), every
+// gap close to one of the known intervals and a stable amount, which may step
+// to a new price now and then. Patterns whose
+// last occurrence is more than one and a half intervals ago have stopped.
+func DetectRecurring(transactions []*models.Transaction, now time.Time) []*models.RecurringItem {
+	matches := detectRecurring(transactions, now)
+	items := make([]*models.RecurringItem, 0, len(matches))
+	for _, m := range matches {
+		items = append(items, m.item)
+	}
+	return items
+}
+
+// recurringMatch is a detected item with its occurrences, oldest first
+type recurringMatch struct {
+	item         *models.RecurringItem
+	recurrence   recurrence
+	transactions []*models.Transaction
+}
+
+func detectRecurring(transactions []*models.Transaction, now time.Time) []*recurringMatch {
+	today := dayOf(now)
+
+	groups := make(map[string][]*models.Transaction)
+	for _, t := range transactions {
+		merchant := strings.Join(tokenize(t.Description), " ")
+		if merchant == "" || t.CategoryId == nil {
+			continue
+		}
+		key := t.Type + "|" + t.CategoryId.String() + "|" + merchant
+		groups[key] = append(groups[key], t)
+	}
+
+	var matches []*recurringMatch
+
+	for _, group := range groups {
+		slices.SortFunc(group, func(a, b *models.Transaction) int { return a.Date.Compare(b.Date) })
+
+		r, ok := matchRecurrence(group)
+		if !ok {
+			continue
+		}
+		amount, ok := stableAmount(group)
+		if !ok {
+			continue
+		}
+
+		last := group[len(group)-1]
+		lastDate := dayOf(last.Date)
+		if today.Sub(lastDate).Hours()/24 > float64(r.days+r.days/2) {
+			continue
+		}
+
+		next := r.after(lastDate)
+		if !next.After(today) {
+			next = today.AddDate(0, 0, 1) // Overdue, expect it any day now
+		}
+
+		item := &models.RecurringItem{
+			Description:  last.Description,
+			CategoryId:   *last.CategoryId,
+			CategoryName: last.CategoryName,
+			Type:         last.Type,
+			Amount:       amount,
+			Interval:     r.name,
+			Occurrences:  len(group),
+			LastDate:     lastDate,
+			NextDate:     next,
+		}
+		matches = append(matches, &recurringMatch{item: item, recurrence: r, transactions: group})
+	}
+
+	slices.SortFunc(matches, func(a, b *recurringMatch) int {
+		if c := a.item.NextDate.Compare(b.item.NextDate); c != 0 {
+			return c
+		}
+		return strings.Compare(a.item.Description, b.item.Description)
+	})
+	return matches
+}
+
+// matchRecurrence picks the interval closest to the median gap and checks every gap against it
+func matchRecurrence(group []*models.Transaction) (recurrence, bool) {
+	if len(group) < 2 {
+		return recurrence{}, false
+	}
+	gaps := make([]int, 0, len(group)-1)
+	for i := 1; i < len(group); i++ {
+		gaps = append(gaps, int(dayOf(group[i].Date).Sub(dayOf(group[i-1].Date)).Hours()/24))
+	}
+	median := slices.Clone(gaps)
+	slices.Sort(median)
+
+	for _, r := range recurrences {
+		if len(group) < r.minCount || abs(median[len(median)/2]-r.days) > r.tolerance {
+			continue
+		}
+		for _, gap := range gaps {
+			if abs(gap-r.days) > r.tolerance {
+				return recurrence{}, false
+			}
+		}
+		return r, true
+	}
+	return recurrence{}, false
+}
+
+// stableAmount splits the occurrences into runs at one price and returns the
+// median amount of the latest run. A run ends at an amount too far from its
+// first one. Every price but the current one must have held at least twice:
+// amounts that keep swinging are no recurring item.
+func stableAmount(group []*models.Transaction) (int64, bool) {
+	start := 0
+	for i := 1; i < len(group); i++ {
+		first := group[start].Amount
+		if float64(abs64(group[i].Amount-first)) > recurringAmountTolerance*float64(first) {
+			if i-start < 2 {
+				return 0, false
+			}
+			start = i
+		}
+	}
+
+	amounts := make([]int64, 0, len(group)-start)
+	for _, t := range group[start:] {
+		amounts = append(amounts, t.Amount)
+	}
+	slices.Sort(amounts)
+	return amounts[len(amounts)/2], true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
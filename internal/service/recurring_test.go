@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyTx builds a transaction of the given category for history based tests
+func historyTx(c *models.Category, description string, amount int64, date time.Time) *models.Transaction {
+	return &models.Transaction{
+		ID:           uuid.New(),
+		CategoryId:   &c.ID,
+		CategoryName: c.Name,
+		Type:         c.Type,
+		Description:  description,
+		Amount:       amount,
+		Date:         date,
+	}
+}
+
+func TestDetectRecurring(t *testing.T) {
+	now := time.Date(2026, time.June, 10, 9, 0, 0, 0, time.UTC)
+	housing := &models.Category{ID: uuid.New(), Name: "Housing", Type: "expense"}
+	fitness := &models.Category{ID: uuid.New(), Name: "Fitness", Type: "expense"}
+	dining := &models.Category{ID: uuid.New(), Name: "Dining", Type: "expense"}
+	utilities := &models.Category{ID: uuid.New(), Name: "Utilities", Type: "expense"}
+
+	var history []*models.Transaction
+	for m := time.February; m <= time.June; m++ {
+		// Reference numbers change every month, the day of the month moves a little
+		history = append(history, historyTx(housing, fmt.Sprintf("RENT REF %d", 1000+int(m)), 120000, time.Date(2026, m, 1+int(m)%3, 0, 0, 0, 0, time.UTC)))
+		// Amounts swing too much to be a bill
+		history = append(history, historyTx(utilities, "Power Co", int64(m)*4000, time.Date(2026, m, 15, 0, 0, 0, 0, time.UTC)))
+	}
+	for d := 0; d < 5; d++ {
+		history = append(history, historyTx(fitness, "Gym", 1500, time.Date(2026, time.May, 12+7*d, 0, 0, 0, 0, time.UTC)))
+	}
+	// Irregular gaps
+	for _, day := range []int{2, 9, 27} {
+		history = append(history, historyTx(dining, "Pizza Place", 2500, time.Date(2026, time.May, day, 0, 0, 0, 0, time.UTC)))
+	}
+	// Stopped in March
+	for m := time.January; m <= time.March; m++ {
+		history = append(history, historyTx(fitness, "Old Streaming", 999, time.Date(2026, m, 20, 0, 0, 0, 0, time.UTC)))
+	}
+
+	items := DetectRecurring(history, now)
+	require.Len(t, items, 2)
+
+	gym, rent := items[0], items[1]
+	assert.Equal(t, "weekly", gym.Interval)
+	assert.Equal(t, time.Date(2026, time.June, 16, 0, 0, 0, 0, time.UTC), gym.NextDate)
+	assert.Equal(t, 5, gym.Occurrences)
+
+	assert.Equal(t, "monthly", rent.Interval)
+	assert.Equal(t, int64(120000), rent.Amount)
+	assert.Equal(t, "Housing", rent.CategoryName)
+	assert.Equal(t, time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC), rent.NextDate)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+const (
+	MatchContains = "contains"
+	MatchRegex    = "regex"
+)
+
+type RuleService struct {
+	Repo   repository.RuleRepository
+	TxRepo repository.TransactionRepository
+}
+
+// Apply runs the user's rules against t (in place) and reports what changed
+func (s *RuleService) Apply(ctx context.Context, t *models.Transaction) (*models.RuleChange, error) {
+	set, err := s.Load(ctx, t.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return set.Apply(t), nil
+}
+
+// Load fetches and compiles the user's rules, so they can be applied to many transactions
+func (s *RuleService) Load(ctx context.Context, userID uuid.UUID) (*RuleSet, error) {
+	rules, err := s.Repo.ListRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return CompileRules(rules), nil
+}
+
+// Rerun re-applies the rules to every existing transaction of the user.
+// With dryRun set nothing is written, only the diff is returned.
+func (s *RuleService) Rerun(ctx context.Context, userID uuid.UUID, dryRun bool) ([]*models.RuleChange, error) {
+	set, err := s.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.TxRepo.ListTransactions(ctx, userID, models.TransactionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*models.RuleChange{}
+	for _, t := range transactions {
+		change := set.Apply(t)
+		if len(change.Changes) == 0 {
+			continue
+		}
+		if !dryRun {
+			if err := s.TxRepo.UpdateTransaction(ctx, t); err != nil {
+				return nil, fmt.Errorf("update transaction %s: %w", t.ID, err)
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// ValidateRule checks a rule before it is stored
+func ValidateRule(rule *models.Rule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return errors.New("name is required")
+	}
+	switch rule.MatchType {
+	case MatchContains:
+	case MatchRegex:
+		if _, err := regexp.Compile(rule.DescriptionPattern); err != nil {
+			return fmt.Errorf("invalid description_pattern: %v", err)
+		}
+	default:
+		return fmt.Errorf("match_type must be %q or %q", MatchContains, MatchRegex)
+	}
+	for _, d := range rule.Weekdays {
+		if d < 0 || d > 6 {
+			return errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.New("min_amount must not be greater than max_amount")
+	}
+	if rule.SetCategoryId == nil && rule.SetDescription == nil && len(rule.AddTagIds) == 0 {
+		return errors.New("rule must have at least one action")
+	}
+	return nil
+}
+
+// RuleSet is a list of rules ready to be applied (regexes compiled once)
+type RuleSet struct {
+	rules   []*models.Rule
+	regexps map[uuid.UUID]*regexp.Regexp
+}
+
+// CompileRules keeps enabled rules, in the given (priority) order.
+// Rules with an invalid regex are skipped.
+func CompileRules(rules []*models.Rule) *RuleSet {
+	set := &RuleSet{regexps: make(map[uuid.UUID]*regexp.Regexp)}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.MatchType == MatchRegex && rule.DescriptionPattern != "" {
+			re, err := regexp.Compile(rule.DescriptionPattern)
+			if err != nil {
+				continue
+			}
+			set.regexps[rule.ID] = re
+		}
+		set.rules = append(set.rules, rule)
+	}
+	return set
+}
+
+// Apply modifies t in place. For the category and the description the highest
+// priority matching rule wins; tags from every matching rule are added.
+func (s *RuleSet) Apply(t *models.Transaction) *models.RuleChange {
+	change := &models.RuleChange{
+		TransactionID: t.ID,
+		Description:   t.Description,
+		MatchedRules:  []uuid.UUID{},
+		Changes:       []models.FieldChange{},
+	}
+	if s == nil {
+		return change
+	}
+
+	oldCategory := t.CategoryId
+	oldDescription := t.Description
+	oldTags := tagIDStrings(t.Tags)
+
+	categorySet, descriptionSet := false, false
+	for _, rule := range s.rules {
+		// Conditions always look at the original description
+		if !s.matches(rule, t, oldDescription) {
+			continue
+		}
+		change.MatchedRules = append(change.MatchedRules, rule.ID)
+
+		if rule.SetCategoryId != nil && !categorySet {
+			id := *rule.SetCategoryId
+			t.CategoryId = &id
+			categorySet = true
+		}
+		if rule.SetDescription != nil && !descriptionSet {
+			t.Description = *rule.SetDescription
+			descriptionSet = true
+		}
+		for _, tagID := range rule.AddTagIds {
+			if !hasTag(t.Tags, tagID) {
+				t.Tags = append(t.Tags, &models.Tag{ID: tagID, UserId: t.UserId})
+			}
+		}
+	}
+
+	if !sameUUID(oldCategory, t.CategoryId) {
+		change.Changes = append(change.Changes, models.FieldChange{Field: "category_id", From: oldCategory, To: t.CategoryId})
+	}
+	if oldDescription != t.Description {
+		change.Changes = append(change.Changes, models.FieldChange{Field: "description", From: oldDescription, To: t.Description})
+	}
+	if newTags := tagIDStrings(t.Tags); len(newTags) != len(oldTags) {
+		change.Changes = append(change.Changes, models.FieldChange{Field: "tags", From: oldTags, To: newTags})
+	}
+
+	return change
+}
+
+func (s *RuleSet) matches(rule *models.Rule, t *models.Transaction, description string) bool {
+	if rule.DescriptionPattern != "" {
+		if rule.MatchType == MatchRegex {
+			re, ok := s.regexps[rule.ID]
+			if !ok || !re.MatchString(description) {
+				return false
+			}
+		} else if !strings.Contains(strings.ToLower(description), strings.ToLower(rule.DescriptionPattern)) {
+			return false
+		}
+	}
+	if rule.MinAmount != nil && t.Amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && t.Amount > *rule.MaxAmount {
+		return false
+	}
+	if len(rule.Weekdays) > 0 {
+		weekday := int(t.Date.Weekday())
+		found := false
+		for _, d := range rule.Weekdays {
+			if d == weekday {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []*models.Tag, id uuid.UUID) bool {
+	for _, tag := range tags {
+		if tag.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func tagIDStrings(tags []*models.Tag) []string {
+	ids := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID.String())
+	}
+	return ids
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRuleRepo struct {
+	mock.Mock
+}
+
+func (m *MockRuleRepo) CreateRule(ctx context.Context, rule *models.Rule) error {
+	return nil // Not used in this test
+}
+func (m *MockRuleRepo) ListRules(ctx context.Context, userID uuid.UUID) ([]*models.Rule, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Rule), args.Error(1)
+}
+func (m *MockRuleRepo) GetRule(ctx context.Context, userID, ruleID uuid.UUID) (*models.Rule, error) {
+	return nil, nil // Not used in this test
+}
+func (m *MockRuleRepo) UpdateRule(ctx context.Context, rule *models.Rule) error {
+	return nil // Not used in this test
+}
+func (m *MockRuleRepo) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	return nil // Not used in this test
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestRuleSetApply(t *testing.T) {
+	groceries := uuid.New()
+	dining := uuid.New()
+	reimbursable := uuid.New()
+	// 2026-03-06 is a Friday
+	friday := time.Date(2026, time.March, 6, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Highest Priority Category Wins And Tags Accumulate", func(t *testing.T) {
+		rules := []*models.Rule{
+			{ID: uuid.New(), Enabled: true, Priority: 10, MatchType: MatchContains, DescriptionPattern: "lidl", SetCategoryId: &groceries},
+			{ID: uuid.New(), Enabled: true, Priority: 5, MatchType: MatchContains, DescriptionPattern: "LIDL", SetCategoryId: &dining, AddTagIds: []uuid.UUID{reimbursable}},
+		}
+		tx := &models.Transaction{ID: uuid.New(), Description: "LIDL Berlin 123", Amount: 4599, Date: friday}
+
+		change := CompileRules(rules).Apply(tx)
+
+		assert.Equal(t, groceries, *tx.CategoryId)
+		assert.Len(t, tx.Tags, 1)
+		assert.Len(t, change.MatchedRules, 2)
+		assert.Len(t, change.Changes, 2) // category_id and tags
+	})
+
+	t.Run("Regex, Amount Range And Weekday Conditions", func(t *testing.T) {
+		newName := "Coffee"
+		rule := &models.Rule{
+			ID: uuid.New(), Enabled: true, MatchType: MatchRegex, DescriptionPattern: `(?i)^starbucks\s+#\d+`,
+			MinAmount: int64Ptr(100), MaxAmount: int64Ptr(1000), Weekdays: []int{5},
+			SetDescription: &newName,
+		}
+		set := CompileRules([]*models.Rule{rule})
+
+		match := &models.Transaction{Description: "STARBUCKS #42", Amount: 450, Date: friday}
+		set.Apply(match)
+		assert.Equal(t, "Coffee", match.Description)
+
+		tooExpensive := &models.Transaction{Description: "STARBUCKS #42", Amount: 4500, Date: friday}
+		set.Apply(tooExpensive)
+		assert.Equal(t, "STARBUCKS #42", tooExpensive.Description)
+
+		wrongDay := &models.Transaction{Description: "STARBUCKS #42", Amount: 450, Date: friday.AddDate(0, 0, 1)}
+		set.Apply(wrongDay)
+		assert.Equal(t, "STARBUCKS #42", wrongDay.Description)
+	})
+
+	t.Run("Disabled Rules Are Ignored", func(t *testing.T) {
+		rules := []*models.Rule{{ID: uuid.New(), Enabled: false, MatchType: MatchContains, SetCategoryId: &groceries}}
+		tx := &models.Transaction{Description: "Anything"}
+
+		change := CompileRules(rules).Apply(tx)
+
+		assert.Nil(t, tx.CategoryId)
+		assert.Empty(t, change.Changes)
+	})
+}
+
+func TestValidateRule(t *testing.T) {
+	category := uuid.New()
+
+	assert.NoError(t, ValidateRule(&models.Rule{Name: "ok", MatchType: MatchContains, SetCategoryId: &category}))
+	assert.Error(t, ValidateRule(&models.Rule{Name: "no action", MatchType: MatchContains}))
+	assert.Error(t, ValidateRule(&models.Rule{Name: "bad regex", MatchType: MatchRegex, DescriptionPattern: "(", SetCategoryId: &category}))
+	assert.Error(t, ValidateRule(&models.Rule{Name: "bad weekday", MatchType: MatchContains, Weekdays: []int{7}, SetCategoryId: &category}))
+	assert.Error(t, ValidateRule(&models.Rule{Name: "bad range", MatchType: MatchContains, MinAmount: int64Ptr(10), MaxAmount: int64Ptr(5), SetCategoryId: &category}))
+}
+
+func TestRerunRules(t *testing.T) {
+	userID := uuid.New()
+	oldCategory := uuid.New()
+	newCategory := uuid.New()
+
+	rules := []*models.Rule{{ID: uuid.New(), Enabled: true, MatchType: MatchContains, DescriptionPattern: "netflix", SetCategoryId: &newCategory}}
+	txs := func() []*models.Transaction {
+		return []*models.Transaction{
+			{ID: uuid.New(), UserId: userID, Description: "NETFLIX.COM", CategoryId: &oldCategory},
+			{ID: uuid.New(), UserId: userID, Description: "Rent", CategoryId: &oldCategory},
+		}
+	}
+
+	t.Run("Dry Run Does Not Write", func(t *testing.T) {
+		ruleRepo := new(MockRuleRepo)
+		txRepo := new(MockRepo)
+		ruleRepo.On("ListRules", mock.Anything, userID).Return(rules, nil)
+		txRepo.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{}).Return(txs(), nil)
+
+		s := &RuleService{Repo: ruleRepo, TxRepo: txRepo}
+		changes, err := s.Rerun(context.Background(), userID, true)
+
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+		assert.Equal(t, "category_id", changes[0].Changes[0].Field)
+		txRepo.AssertNotCalled(t, "UpdateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Applies Changes", func(t *testing.T) {
+		ruleRepo := new(MockRuleRepo)
+		txRepo := new(MockRepo)
+		ruleRepo.On("ListRules", mock.Anything, userID).Return(rules, nil)
+		txRepo.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{}).Return(txs(), nil)
+		txRepo.On("UpdateTransaction", mock.Anything, mock.MatchedBy(func(t *models.Transaction) bool {
+			return *t.CategoryId == newCategory
+		})).Return(nil).Once()
+
+		s := &RuleService{Repo: ruleRepo, TxRepo: txRepo}
+		changes, err := s.Rerun(context.Background(), userID, false)
+
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+		txRepo.AssertExpectations(t)
+	})
+}
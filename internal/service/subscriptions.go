@@ -0,0 +1,68 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// SubscriptionService finds the recurring charges in the user's history
+type SubscriptionService struct {
+	TxRepo repository.TransactionRepository
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+// ListSubscriptions analyzes the last 400 days of transactions
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]*models.Subscription, error) {
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	today := dayOf(now)
+
+	history, err := s.TxRepo.ListTransactions(ctx, userID, models.TransactionFilter{
+		From: today.AddDate(0, 0, -forecastHistoryDays),
+		To:   today.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DetectSubscriptions(history, now), nil
+}
+
+// DetectSubscriptions keeps the recurring expenses, most expensive per year first
+func DetectSubscriptions(history []*models.Transaction, now time.Time) []*models.Subscription {
+	subscriptions := []*models.Subscription{}
+
+	for _, m := range detectRecurring(history, now) {
+		if m.item.Type != "expense" {
+			continue
+		}
+
+		sub := &models.Subscription{RecurringItem: *m.item, PriceChanges: []*models.PriceChange{}}
+		for i, t := range m.transactions {
+			if i > 0 && t.Amount != m.transactions[i-1].Amount {
+				sub.PriceChanges = append(sub.PriceChanges, &models.PriceChange{
+					Date: dayOf(t.Date),
+					From: m.transactions[i-1].Amount,
+					To:   t.Amount,
+				})
+			}
+		}
+		sub.CurrentAmount = m.transactions[len(m.transactions)-1].Amount
+		sub.AnnualCost = sub.CurrentAmount * int64(m.recurrence.perYear)
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	slices.SortStableFunc(subscriptions, func(a, b *models.Subscription) int {
+		return cmp.Compare(b.AnnualCost, a.AnnualCost)
+	})
+	return subscriptions
+}
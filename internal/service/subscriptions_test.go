@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSubscriptions(t *testing.T) {
+	now := time.Date(2026, time.June, 10, 9, 0, 0, 0, time.UTC)
+	salary := &models.Category{ID: uuid.New(), Name: "Salary", Type: "income"}
+	media := &models.Category{ID: uuid.New(), Name: "Media", Type: "expense"}
+	internet := &models.Category{ID: uuid.New(), Name: "Internet", Type: "expense"}
+
+	var history []*models.Transaction
+	for m := time.January; m <= time.June; m++ {
+		amount := int64(999)
+		if m >= time.April {
+			amount = 1199
+		}
+		// Billing drifts by a day now and then
+		history = append(history, historyTx(media, "NETFLIX.COM 8443", amount, time.Date(2026, m, 3+int(m)%2, 0, 0, 0, 0, time.UTC)))
+	}
+	for m := time.February; m <= time.May; m++ {
+		history = append(history, historyTx(salary, "ACME Payroll", 300000, time.Date(2026, m, 25, 0, 0, 0, 0, time.UTC)))
+	}
+	history = append(history,
+		historyTx(internet, "Domain renewal", 1500, time.Date(2025, time.August, 20, 0, 0, 0, 0, time.UTC)),
+		historyTx(internet, "Domain renewal", 1800, time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC)), // Half a year: no pattern
+	)
+
+	subs := DetectSubscriptions(history, now)
+	require.Len(t, subs, 1)
+
+	netflix := subs[0]
+	assert.Equal(t, "monthly", netflix.Interval)
+	assert.Equal(t, int64(1199), netflix.CurrentAmount)
+	assert.Equal(t, int64(1199*12), netflix.AnnualCost)
+	assert.Equal(t, time.Date(2026, time.July, 3, 0, 0, 0, 0, time.UTC), netflix.NextDate)
+	require.Len(t, netflix.PriceChanges, 1)
+	assert.Equal(t, &models.PriceChange{
+		Date: time.Date(2026, time.April, 3, 0, 0, 0, 0, time.UTC),
+		From: 999,
+		To:   1199,
+	}, netflix.PriceChanges[0])
+
+	// Yearly charges only need to be seen twice
+	history = append(history, historyTx(internet, "Hosting plan", 9900, time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)),
+		historyTx(internet, "Hosting plan", 9900, time.Date(2026, time.June, 29, 0, 0, 0, 0, time.UTC)))
+	subs = DetectSubscriptions(history, now.AddDate(0, 0, 20))
+	require.Len(t, subs, 2)
+	assert.Equal(t, "Hosting plan", subs[1].Description)
+	assert.Equal(t, "yearly", subs[1].Interval)
+	assert.Equal(t, int64(9900), subs[1].AnnualCost)
+	assert.Empty(t, subs[1].PriceChanges)
+}
+
+func TestDetectSubscriptions_PriceSteps(t *testing.T) {
+	now := time.Date(2026, time.August, 10, 9, 0, 0, 0, time.UTC)
+	media := &models.Category{ID: uuid.New(), Name: "Media", Type: "expense"}
+
+	// Each step is more than the tolerance of a varying bill
+	var history []*models.Transaction
+	for i, amount := range []int64{999, 999, 999, 1299, 1299, 1299, 1549} {
+		history = append(history, historyTx(media, "Streaming Plus", amount, time.Date(2026, time.February+time.Month(i), 5, 0, 0, 0, 0, time.UTC)))
+	}
+
+	subs := DetectSubscriptions(history, now)
+	require.Len(t, subs, 1)
+	assert.Equal(t, int64(1549), subs[0].CurrentAmount)
+	require.Len(t, subs[0].PriceChanges, 2)
+	assert.Equal(t, int64(999), subs[0].PriceChanges[0].From)
+	assert.Equal(t, int64(1549), subs[0].PriceChanges[1].To)
+}
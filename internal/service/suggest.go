@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+)
+
+// CategorySuggester predicts a category from a transaction description using
+// a per-user multinomial naive Bayes model trained on the user's own history.
+// Models are built lazily on first use and updated incrementally with Observe.
+type CategorySuggester struct {
+	Repo repository.TransactionRepository
+
+	mu     sync.Mutex
+	models map[uuid.UUID]*bayesModel
+}
+
+// bayesModel holds token frequencies per category
+type bayesModel struct {
+	docs        map[uuid.UUID]int            // Transactions per category
+	tokens      map[uuid.UUID]map[string]int // Token counts per category
+	tokenTotals map[uuid.UUID]int            // Sum of token counts per category
+	vocabulary  map[string]struct{}
+	names       map[uuid.UUID]string
+	totalDocs   int
+}
+
+func newBayesModel() *bayesModel {
+	return &bayesModel{
+		docs:        make(map[uuid.UUID]int),
+		tokens:      make(map[uuid.UUID]map[string]int),
+		tokenTotals: make(map[uuid.UUID]int),
+		vocabulary:  make(map[string]struct{}),
+		names:       make(map[uuid.UUID]string),
+	}
+}
+
+func (m *bayesModel) add(categoryID uuid.UUID, categoryName, description string) {
+	words := tokenize(description)
+	if len(words) == 0 {
+		return
+	}
+
+	m.docs[categoryID]++
+	m.totalDocs++
+	if categoryName != "" {
+		m.names[categoryID] = categoryName
+	}
+
+	counts, ok := m.tokens[categoryID]
+	if !ok {
+		counts = make(map[string]int)
+		m.tokens[categoryID] = counts
+	}
+	for _, w := range words {
+		counts[w]++
+		m.tokenTotals[categoryID]++
+		m.vocabulary[w] = struct{}{}
+	}
+}
+
+// Suggest returns up to limit categories ordered by confidence (0..1)
+func (s *CategorySuggester) Suggest(ctx context.Context, userID uuid.UUID, description string, limit int) ([]*models.CategorySuggestion, error) {
+	m, err := s.model(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return m.predict(description, limit), nil
+}
+
+// Observe feeds a freshly created transaction into the user's model.
+// If the model hasn't been built yet it will include the row when it is.
+func (s *CategorySuggester) Observe(t *models.Transaction) {
+	if t.CategoryId == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.models[t.UserId]; ok {
+		m.add(*t.CategoryId, t.CategoryName, t.Description)
+	}
+}
+
+// Forget drops the user's model so it gets rebuilt from the database,
+// useful after bulk changes such as re-running rules.
+func (s *CategorySuggester) Forget(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.models, userID)
+}
+
+func (s *CategorySuggester) model(ctx context.Context, userID uuid.UUID) (*bayesModel, error) {
+	s.mu.Lock()
+	m, ok := s.models[userID]
+	s.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+
+	// Build outside the lock, the DB query may be slow
+	transactions, err := s.Repo.ListTransactions(ctx, userID, models.TransactionFilter{})
+	if err != nil {
+		return nil, err
+	}
+	built := newBayesModel()
+	for _, t := range transactions {
+		if t.CategoryId != nil {
+			built.add(*t.CategoryId, t.CategoryName, t.Description)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.models == nil {
+		s.models = make(map[uuid.UUID]*bayesModel)
+	}
+	// Another request may have won the race, keep the first one
+	if existing, ok := s.models[userID]; ok {
+		return existing, nil
+	}
+	s.models[userID] = built
+	return built, nil
+}
+
+func (m *bayesModel) predict(description string, limit int) []*models.CategorySuggestion {
+	suggestions := []*models.CategorySuggestion{}
+
+	// Ignore words the model has never seen, they carry no signal
+	var words []string
+	for _, w := range tokenize(description) {
+		if _, ok := m.vocabulary[w]; ok {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 || m.totalDocs == 0 {
+		return suggestions
+	}
+
+	// log P(category) + sum(log P(word | category)) with Laplace smoothing
+	vocabSize := float64(len(m.vocabulary))
+	scores := make(map[uuid.UUID]float64, len(m.docs))
+	maxScore := math.Inf(-1)
+	for categoryID, docs := range m.docs {
+		score := math.Log(float64(docs) / float64(m.totalDocs))
+		denominator := float64(m.tokenTotals[categoryID]) + vocabSize
+		for _, w := range words {
+			score += math.Log((float64(m.tokens[categoryID][w]) + 1) / denominator)
+		}
+		scores[categoryID] = score
+		maxScore = math.Max(maxScore, score)
+	}
+
+	// Softmax turns log scores into confidences that sum up to 1
+	var sum float64
+	for categoryID, score := range scores {
+		scores[categoryID] = math.Exp(score - maxScore)
+		sum += scores[categoryID]
+	}
+	for categoryID, score := range scores {
+		suggestions = append(suggestions, &models.CategorySuggestion{
+			CategoryID:   categoryID,
+			CategoryName: m.names[categoryID],
+			Confidence:   math.Round(score/sum*1000) / 1000,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CategoryID.String() < suggestions[j].CategoryID.String()
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// tokenize lowercases the text and keeps words of 2+ characters that are not pure numbers
+// (card numbers, dates and store IDs are noise for categorization)
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) < 2 || strings.IndexFunc(f, unicode.IsLetter) < 0 {
+			continue
+		}
+		words = append(words, f)
+	}
+	return words
+}
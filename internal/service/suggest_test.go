@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"rewe", "markt", "berlin"}, tokenize("REWE Markt 4711 Berlin, 2026-03-01 *"))
+	assert.Empty(t, tokenize("12345 / 6"))
+}
+
+func TestCategorySuggester(t *testing.T) {
+	userID := uuid.New()
+	groceries := uuid.New()
+	transport := uuid.New()
+
+	history := []*models.Transaction{
+		{CategoryId: &groceries, CategoryName: "Groceries", Description: "REWE Markt"},
+		{CategoryId: &groceries, CategoryName: "Groceries", Description: "Lidl Filiale"},
+		{CategoryId: &groceries, CategoryName: "Groceries", Description: "REWE City"},
+		{CategoryId: &transport, CategoryName: "Transport", Description: "BVG Ticket"},
+		{CategoryId: &transport, CategoryName: "Transport", Description: "Deutsche Bahn Ticket"},
+	}
+
+	t.Run("Ranks Categories By Confidence", func(t *testing.T) {
+		repo := new(MockRepo)
+		repo.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{}).Return(history, nil).Once()
+		s := &CategorySuggester{Repo: repo}
+
+		suggestions, err := s.Suggest(context.Background(), userID, "REWE Markt Mitte", 3)
+
+		assert.NoError(t, err)
+		assert.Len(t, suggestions, 2)
+		assert.Equal(t, "Groceries", suggestions[0].CategoryName)
+		assert.Greater(t, suggestions[0].Confidence, 0.8)
+		assert.InDelta(t, 1.0, suggestions[0].Confidence+suggestions[1].Confidence, 0.01)
+
+		// Second call hits the cached model (ListTransactions expected only once)
+		_, err = s.Suggest(context.Background(), userID, "Ticket", 1)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Words Give No Suggestion", func(t *testing.T) {
+		repo := new(MockRepo)
+		repo.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{}).Return(history, nil)
+		s := &CategorySuggester{Repo: repo}
+
+		suggestions, err := s.Suggest(context.Background(), userID, "Completely new merchant", 3)
+
+		assert.NoError(t, err)
+		assert.Empty(t, suggestions)
+	})
+
+	t.Run("Learns Incrementally", func(t *testing.T) {
+		repo := new(MockRepo)
+		repo.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{}).Return(history, nil).Once()
+		s := &CategorySuggester{Repo: repo}
+
+		_, err := s.Suggest(context.Background(), userID, "warm up", 1)
+		assert.NoError(t, err)
+
+		for range 3 {
+			s.Observe(&models.Transaction{UserId: userID, CategoryId: &transport, Description: "Uber trip"})
+		}
+
+		suggestions, err := s.Suggest(context.Background(), userID, "Uber", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, transport, suggestions[0].CategoryID)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/storage"
+)
+
+// DefaultTrashRetention is how long deleted items stay restorable by default
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService purges deleted transactions and categories once they spent
+// the retention period in the trash
+type TrashService struct {
+	Repo      repository.TrashRepository
+	Storage   storage.BlobStorage // Optional, removes the receipts of purged transactions
+	Retention time.Duration       // Defaults to DefaultTrashRetention
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (s *TrashService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// RetentionPeriod is how long a deleted item can be restored
+func (s *TrashService) RetentionPeriod() time.Duration {
+	if s.Retention <= 0 {
+		return DefaultTrashRetention
+	}
+	return s.Retention
+}
+
+// Purge permanently removes what was deleted more than the retention period
+// ago and returns how many rows it removed. A receipt blob that cannot be
+// removed is only logged: its row is gone already.
+func (s *TrashService) Purge(ctx context.Context) (int64, error) {
+	purged, storageKeys, err := s.Repo.PurgeDeleted(ctx, s.now().Add(-s.RetentionPeriod()))
+	if err != nil {
+		return 0, err
+	}
+	if s.Storage != nil {
+		for _, key := range storageKeys {
+			if err := s.Storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				log.Printf("trash: delete receipt %s: %v", key, err)
+			}
+		}
+	}
+	return purged, nil
+}
+
+// Run purges the trash every interval until ctx is done
+func (s *TrashService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.Purge(ctx); err != nil {
+			log.Printf("trash: %v", err)
+		} else if n > 0 {
+			log.Printf("trash: purged %d rows", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memTrashRepo struct {
+	repository.TrashRepository
+	cutoff      time.Time
+	storageKeys []string
+}
+
+func (r *memTrashRepo) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, []string, error) {
+	r.cutoff = cutoff
+	return 3, r.storageKeys, nil
+}
+
+func TestTrashService_Purge(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, blobs.Put(ctx, "receipts/a.pdf", bytes.NewReader([]byte("%PDF")), 4, "application/pdf"))
+
+	now := time.Date(2026, time.March, 31, 12, 0, 0, 0, time.UTC)
+	repo := &memTrashRepo{storageKeys: []string{"receipts/a.pdf", "receipts/gone.pdf"}}
+	s := &TrashService{Repo: repo, Storage: blobs, Now: func() time.Time { return now }}
+
+	n, err := s.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, now.Add(-DefaultTrashRetention), repo.cutoff)
+
+	// Receipts of purged transactions are removed, missing ones are not an error
+	_, err = blobs.Get(ctx, "receipts/a.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	s.Retention = 7 * 24 * time.Hour
+	_, err = s.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), repo.cutoff)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+)
+
+// WebhookEvents are the event types endpoints can subscribe to
+var WebhookEvents = []string{
+	models.EventTransactionCreated,
+	models.EventTransactionUpdated,
+	models.EventTransactionDeleted,
+	models.EventTransactionRestored,
+	models.EventCategoryCreated,
+	models.EventCategoryDeleted,
+	models.EventCategoryRestored,
+}
+
+const (
+	webhookClaimBatch = 50
+	// Claimed deliveries are hidden from other workers this long, well over the HTTP timeout
+	webhookLease = 5 * time.Minute
+)
+
+// defaultWebhookClient refuses internal addresses and redirects, see webhook.NewClient
+var defaultWebhookClient = webhook.NewClient(30 * time.Second)
+
+// WebhookService queues events for the endpoints subscribed to them and
+// delivers the queue with exponential backoff. Every attempt is recorded on
+// the delivery row, which doubles as the delivery log.
+type WebhookService struct {
+	Repo     repository.WebhookRepository
+	Client   *http.Client     // Optional, defaults to webhook.NewClient. Tests inject their own.
+	Resolver webhook.Resolver // Optional, checks endpoint hosts. Defaults to net.DefaultResolver
+
+	MaxAttempts int           // Defaults to 8
+	BaseDelay   time.Duration // Before the 2nd attempt, doubled after each failure. Defaults to 30s
+	MaxDelay    time.Duration // Defaults to 6h
+
+	Now func() time.Time // Defaults to time.Now
+}
+
+func (s *WebhookService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// Publish queues the event for every enabled endpoint of the user subscribed
+// to it. An endpoint gets one delivery per event ID, however often it is published.
+func (s *WebhookService) Publish(ctx context.Context, userID uuid.UUID, event *models.Event) error {
+	endpoints, err := s.Repo.ListSubscribers(ctx, userID, event.Type)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, e := range endpoints {
+		if _, err := s.enqueue(ctx, e, event, payload); err != nil && !errors.Is(err, repository.ErrAlreadyQueued) {
+			errs = append(errs, fmt.Errorf("endpoint %s: %w", e.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendTest queues a sample event for the endpoint and attempts it right away.
+// Failed attempts are retried like any other delivery.
+func (s *WebhookService) SendTest(ctx context.Context, userID, endpointID uuid.UUID) (*models.WebhookDelivery, error) {
+	e, err := s.Repo.GetEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.Event{
+		ID:        uuid.New(),
+		Type:      models.EventWebhookTest,
+		CreatedAt: s.now().UTC(),
+		Data: map[string]any{
+			"message":     "This is a test event",
+			"endpoint_id": e.ID,
+		},
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.enqueue(ctx, e, event, payload)
+	if err != nil {
+		return nil, err
+	}
+	return d, s.attempt(ctx, e, d)
+}
+
+// ProcessDue sends the deliveries that are due and returns how many were attempted
+func (s *WebhookService) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := s.Repo.ClaimDueDeliveries(ctx, s.now(), webhookLease, webhookClaimBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range deliveries {
+		e, err := s.Repo.GetEndpoint(ctx, d.UserId, d.EndpointId)
+		if err != nil {
+			return 0, err // Deleting an endpoint deletes its deliveries, so this is a DB error
+		}
+		if err := s.attempt(ctx, e, d); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// Run processes the queue every interval until ctx is done
+func (s *WebhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Drain full batches right away, wait when the queue is empty
+		for {
+			n, err := s.ProcessDue(ctx)
+			if err != nil {
+				log.Printf("webhook deliveries: %v", err)
+			}
+			if err != nil || n < webhookClaimBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ValidateEndpoint checks the URL, which must not point at an internal
+// address, and the subscribed event types
+func (s *WebhookService) ValidateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	if err := webhook.CheckURL(ctx, s.Resolver, e.URL); err != nil {
+		return err
+	}
+	for _, event := range e.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) enqueue(ctx context.Context, e *models.WebhookEndpoint, event *models.Event, payload []byte) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{
+		EndpointId:    e.ID,
+		UserId:        e.UserId,
+		EventId:       event.ID,
+		Event:         event.Type,
+		Payload:       payload,
+		NextAttemptAt: s.now().UTC(),
+	}
+	if err := s.Repo.EnqueueDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// attempt sends d once and records the outcome. Only a failure to record is returned.
+func (s *WebhookService) attempt(ctx context.Context, e *models.WebhookEndpoint, d *models.WebhookDelivery) error {
+	statusCode, err := s.post(ctx, e, d)
+
+	d.Attempts++
+	d.LastStatusCode = nil
+	if statusCode != 0 {
+		d.LastStatusCode = &statusCode
+	}
+	d.LastError = nil
+	now := s.now()
+
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+	switch {
+	case err == nil:
+		d.Status = models.DeliveryStatusSucceeded
+		d.DeliveredAt = &now
+	case !e.Enabled || d.Attempts >= maxAttempts:
+		msg := err.Error()
+		d.LastError = &msg
+		d.Status = models.DeliveryStatusFailed
+	default:
+		msg := err.Error()
+		d.LastError = &msg
+		d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+	}
+
+	return s.Repo.SaveAttempt(ctx, d)
+}
+
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	base, maxDelay := s.BaseDelay, s.MaxDelay
+	if base <= 0 {
+		base = 30 * time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = 6 * time.Hour
+	}
+	return backoff(base, maxDelay, attempts)
+}
+
+// backoff is the delay after the given number of failed attempts:
+// base doubled each time, capped at maxDelay, with +-20% jitter
+func backoff(base, maxDelay time.Duration, attempts int) time.Duration {
+	delay := maxDelay
+	if attempts < 30 {
+		delay = min(base<<(attempts-1), maxDelay)
+	}
+	jitter := time.Duration(rand.Int64N(int64(delay)/5*2+1)) - delay/5
+	return delay + jitter
+}
+
+// post sends the signed payload, returning the HTTP status when a response arrived
+func (s *WebhookService) post(ctx context.Context, e *models.WebhookEndpoint, d *models.WebhookDelivery) (int, error) {
+	if !e.Enabled {
+		return 0, errors.New("endpoint is disabled")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderEvent, d.Event)
+	req.Header.Set(webhook.HeaderDelivery, d.ID.String())
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(e.Secret, s.now(), d.Payload))
+
+	client := s.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	// The body is not kept: the delivery log is shown to the user who picked the URL
+	return resp.StatusCode, fmt.Errorf("receiver returned %s", resp.Status)
+}
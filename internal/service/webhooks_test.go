@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olmits/budget-tracker-backend/internal/models"
+	"github.com/olmits/budget-tracker-backend/internal/repository"
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memWebhookRepo keeps endpoints and deliveries in memory
+type memWebhookRepo struct {
+	mu         sync.Mutex
+	endpoints  []*models.WebhookEndpoint
+	deliveries []*models.WebhookDelivery
+}
+
+func (r *memWebhookRepo) CreateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.ID = uuid.New()
+	r.endpoints = append(r.endpoints, e)
+	return nil
+}
+
+func (r *memWebhookRepo) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*models.WebhookEndpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*models.WebhookEndpoint
+	for _, e := range r.endpoints {
+		if e.UserId == userID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *memWebhookRepo) GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*models.WebhookEndpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.endpoints {
+		if e.ID == endpointID && e.UserId == userID {
+			return e, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memWebhookRepo) UpdateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	return nil // Not used in these tests
+}
+
+func (r *memWebhookRepo) DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error {
+	return nil // Not used in these tests
+}
+
+func (r *memWebhookRepo) ListSubscribers(ctx context.Context, userID uuid.UUID, event string) ([]*models.WebhookEndpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*models.WebhookEndpoint
+	for _, e := range r.endpoints {
+		if e.UserId == userID && e.Enabled && (len(e.Events) == 0 || slices.Contains(e.Events, event)) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *memWebhookRepo) EnqueueDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, queued := range r.deliveries {
+		if queued.EndpointId == d.EndpointId && queued.EventId == d.EventId {
+			return repository.ErrAlreadyQueued
+		}
+	}
+	d.ID = uuid.New()
+	d.Status = models.DeliveryStatusPending
+	r.deliveries = append(r.deliveries, d)
+	return nil
+}
+
+func (r *memWebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if len(out) < limit && d.Status == models.DeliveryStatusPending && !d.NextAttemptAt.After(now) {
+			d.NextAttemptAt = now.Add(lease)
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memWebhookRepo) SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	return nil // Deliveries are stored by pointer
+}
+
+func (r *memWebhookRepo) ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	return nil, nil // Not used in these tests
+}
+
+// receiver records the requests it gets and answers with the given status
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func newEvent(eventType string, data any) *models.Event {
+	return &models.Event{ID: uuid.New(), Type: eventType, CreatedAt: time.Now().UTC(), Data: data}
+}
+
+func newWebhookFixture(t *testing.T, status int) (*WebhookService, *memWebhookRepo, *receiver, *httptest.Server) {
+	rc := &receiver{status: status}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	repo := &memWebhookRepo{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := &WebhookService{
+		Repo:      repo,
+		Client:    srv.Client(),
+		BaseDelay: time.Minute,
+		Now:       func() time.Time { return now },
+	}
+	return s, repo, rc, srv
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	ctx := context.Background()
+	s, repo, rc, srv := newWebhookFixture(t, http.StatusOK)
+	userID := uuid.New()
+
+	subscribed := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_a", Events: []string{models.EventTransactionCreated}, Enabled: true}
+	all := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_b", Events: []string{}, Enabled: true}
+	other := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_c", Events: []string{models.EventCategoryCreated}, Enabled: true}
+	disabled := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_d", Events: []string{}, Enabled: false}
+	for _, e := range []*models.WebhookEndpoint{subscribed, all, other, disabled} {
+		require.NoError(t, repo.CreateEndpoint(ctx, e))
+	}
+
+	tx := &models.Transaction{ID: uuid.New(), Amount: 1250, Description: "Coffee"}
+	require.NoError(t, s.Publish(ctx, userID, newEvent(models.EventTransactionCreated, tx)))
+
+	require.Len(t, repo.deliveries, 2)
+	var endpointIDs []uuid.UUID
+	for _, d := range repo.deliveries {
+		endpointIDs = append(endpointIDs, d.EndpointId)
+		assert.Equal(t, repo.deliveries[0].EventId, d.EventId, "one event, one ID for every endpoint")
+	}
+	assert.ElementsMatch(t, []uuid.UUID{subscribed.ID, all.ID}, endpointIDs)
+
+	n, err := s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, rc.requests, 2)
+
+	// Each request is signed with the secret of its endpoint
+	secrets := map[string]string{}
+	for _, d := range repo.deliveries {
+		e, err := repo.GetEndpoint(ctx, userID, d.EndpointId)
+		require.NoError(t, err)
+		secrets[d.ID.String()] = e.Secret
+	}
+	for i, req := range rc.requests {
+		secret := secrets[req.Header.Get(webhook.HeaderDelivery)]
+		require.NotEmpty(t, secret)
+		err := webhook.Verify(secret, req.Header.Get(webhook.HeaderSignature), rc.bodies[i], s.Now(), 5*time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, models.EventTransactionCreated, req.Header.Get(webhook.HeaderEvent))
+	}
+
+	var event struct {
+		Type string             `json:"type"`
+		Data models.Transaction `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rc.bodies[0], &event))
+	assert.Equal(t, models.EventTransactionCreated, event.Type)
+	assert.Equal(t, tx.ID, event.Data.ID)
+
+	for _, d := range repo.deliveries {
+		assert.Equal(t, models.DeliveryStatusSucceeded, d.Status)
+		assert.Equal(t, 1, d.Attempts)
+		assert.NotNil(t, d.DeliveredAt)
+	}
+
+	// Nothing left to send
+	n, err = s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestWebhookService_PublishReplay(t *testing.T) {
+	ctx := context.Background()
+	s, repo, _, srv := newWebhookFixture(t, http.StatusOK)
+	userID := uuid.New()
+
+	e := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_a", Events: []string{}, Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+
+	// The outbox relay publishes an event again when a later sink failed
+	event := newEvent(models.EventCategoryCreated, map[string]string{"name": "Food"})
+	require.NoError(t, s.Publish(ctx, userID, event))
+	require.NoError(t, s.Publish(ctx, userID, event))
+	require.Len(t, repo.deliveries, 1)
+	assert.Equal(t, event.ID, repo.deliveries[0].EventId)
+}
+
+func TestWebhookService_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	s, repo, rc, srv := newWebhookFixture(t, http.StatusInternalServerError)
+	s.MaxAttempts = 3
+	now := s.Now()
+	s.Now = func() time.Time { return now }
+	userID := uuid.New()
+
+	e := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_a", Events: []string{}, Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+	require.NoError(t, s.Publish(ctx, userID, newEvent(models.EventCategoryCreated, map[string]string{"name": "Food"})))
+	require.Len(t, repo.deliveries, 1)
+	d := repo.deliveries[0]
+
+	// 1st failure: retried after BaseDelay, +-20%
+	_, err := s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryStatusPending, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	require.NotNil(t, d.LastStatusCode)
+	assert.Equal(t, http.StatusInternalServerError, *d.LastStatusCode)
+	require.NotNil(t, d.LastError)
+	delay := d.NextAttemptAt.Sub(now)
+	assert.GreaterOrEqual(t, delay, 48*time.Second)
+	assert.LessOrEqual(t, delay, 72*time.Second)
+
+	// Not due yet
+	n, err := s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// 2nd failure: the delay doubles
+	now = d.NextAttemptAt
+	_, err = s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, d.Attempts)
+	delay = d.NextAttemptAt.Sub(now)
+	assert.GreaterOrEqual(t, delay, 96*time.Second)
+	assert.LessOrEqual(t, delay, 144*time.Second)
+
+	// 3rd failure: out of attempts
+	now = d.NextAttemptAt
+	_, err = s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, models.DeliveryStatusFailed, d.Status)
+	assert.Nil(t, d.DeliveredAt)
+	assert.Len(t, rc.requests, 3)
+
+	now = now.Add(24 * time.Hour)
+	n, err = s.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestWebhookService_SendTest(t *testing.T) {
+	ctx := context.Background()
+	s, repo, rc, srv := newWebhookFixture(t, http.StatusNoContent)
+	userID := uuid.New()
+
+	e := &models.WebhookEndpoint{UserId: userID, URL: srv.URL, Secret: "whsec_a", Events: []string{models.EventTransactionCreated}, Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+
+	d, err := s.SendTest(ctx, userID, e.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventWebhookTest, d.Event)
+	assert.Equal(t, models.DeliveryStatusSucceeded, d.Status)
+	require.NotNil(t, d.LastStatusCode)
+	assert.Equal(t, http.StatusNoContent, *d.LastStatusCode)
+	require.Len(t, rc.requests, 1)
+	assert.NoError(t, webhook.Verify(e.Secret, rc.requests[0].Header.Get(webhook.HeaderSignature), rc.bodies[0], s.Now(), time.Minute))
+
+	_, err = s.SendTest(ctx, uuid.New(), e.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+// fakeResolver answers DNS lookups from a map
+type fakeResolver map[string][]netip.Addr
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{ip}, nil
+	}
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	ctx := context.Background()
+	s := &WebhookService{Resolver: fakeResolver{
+		"example.com":       {netip.MustParseAddr("93.184.215.14")},
+		"internal.example":  {netip.MustParseAddr("10.0.0.5")},
+		"rebind.example":    {netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("127.0.0.1")},
+		"metadata.internal": {netip.MustParseAddr("169.254.169.254")},
+	}}
+	valid := &models.WebhookEndpoint{URL: "https://example.com/hooks", Events: []string{models.EventTransactionCreated}}
+	assert.NoError(t, s.ValidateEndpoint(ctx, valid))
+
+	for _, u := range []string{"", "example.com/hooks", "ftp://example.com", "https://", "https://unknown.example/hooks"} {
+		assert.Error(t, s.ValidateEndpoint(ctx, &models.WebhookEndpoint{URL: u}), u)
+	}
+	// Internal services are off limits, however they are spelled
+	for _, u := range []string{
+		"http://127.0.0.1:5432", "http://[::1]/", "http://internal.example/", "http://rebind.example/",
+		"http://metadata.internal/latest/meta-data", "http://169.254.169.254/", "http://[::ffff:10.0.0.1]/",
+	} {
+		err := s.ValidateEndpoint(ctx, &models.WebhookEndpoint{URL: u})
+		assert.ErrorIs(t, err, webhook.ErrForbiddenAddress, u)
+	}
+	assert.Error(t, s.ValidateEndpoint(ctx, &models.WebhookEndpoint{URL: valid.URL, Events: []string{"transaction.exploded"}}))
+	assert.Error(t, s.ValidateEndpoint(ctx, &models.WebhookEndpoint{URL: valid.URL, Events: []string{models.EventWebhookTest}}))
+}
@@ -0,0 +1,20 @@
+-- 1. Tags Table
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- A user cannot have two tags with the same name (case-insensitive)
+CREATE UNIQUE INDEX unique_user_tag_name_idx
+ON tags (user_id, LOWER(name));
+
+-- 2. Many-to-many link between transactions and tags
+CREATE TABLE transaction_tags (
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX idx_transaction_tags_tag ON transaction_tags(tag_id);
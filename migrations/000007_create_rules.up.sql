@@ -0,0 +1,25 @@
+-- User-defined categorization rules.
+-- All non-empty conditions must match; actions are applied in priority order (highest first).
+CREATE TABLE rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Conditions
+    description_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    match_type VARCHAR(20) NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'regex')),
+    min_amount BIGINT,
+    max_amount BIGINT,
+    weekdays INTEGER[] NOT NULL DEFAULT '{}', -- 0 = Sunday ... 6 = Saturday, empty = any day
+
+    -- Actions
+    set_category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    set_description VARCHAR(255),
+    add_tag_ids UUID[] NOT NULL DEFAULT '{}',
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_rules_user ON rules(user_id);
@@ -0,0 +1,15 @@
+-- Pairs of transactions that look like the same real-world payment.
+-- transaction_id is the newer row (the suspected duplicate), duplicate_of_id the one it matches.
+CREATE TABLE duplicate_candidates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    duplicate_of_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    score REAL NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dismissed')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT unique_duplicate_pair UNIQUE (transaction_id, duplicate_of_id)
+);
+
+CREATE INDEX idx_duplicate_candidates_user_status ON duplicate_candidates(user_id, status);
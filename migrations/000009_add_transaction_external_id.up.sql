@@ -0,0 +1,7 @@
+-- Stable ID from the source system (bank FITID, statement reference...).
+-- Lets re-imports of overlapping statements skip rows we already have.
+ALTER TABLE transactions ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX unique_user_transaction_external_id_idx
+ON transactions (user_id, external_id)
+WHERE external_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Accounts linked through a bank sync provider.
+-- sync_cursor is the provider's opaque position: the next sync only fetches what came after it.
+CREATE TABLE bank_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    external_id VARCHAR(255) NOT NULL, -- Account ID at the provider
+    name VARCHAR(100) NOT NULL,
+    mask VARCHAR(10) NOT NULL DEFAULT '',
+    currency VARCHAR(10) NOT NULL DEFAULT '',
+    balance BIGINT NOT NULL DEFAULT 0, -- Cents, as of balance_updated_at
+    balance_updated_at TIMESTAMP WITH TIME ZONE,
+    sync_cursor TEXT NOT NULL DEFAULT '',
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_user_bank_account UNIQUE (user_id, provider, external_id)
+);
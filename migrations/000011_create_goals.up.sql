@@ -0,0 +1,16 @@
+-- Savings goals. Progress comes either from the transactions of a category
+-- (contributions since start_date) or from the balance of a linked bank account.
+CREATE TABLE goals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    target_amount BIGINT NOT NULL CHECK (target_amount > 0), -- Cents
+    start_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    target_date DATE,
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    bank_account_id UUID REFERENCES bank_accounts(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT goal_dates CHECK (target_date IS NULL OR target_date > start_date)
+);
+
+CREATE INDEX idx_goals_user ON goals(user_id);
@@ -0,0 +1,13 @@
+-- Envelope budgeting: the amount assigned to an expense category for a month.
+-- Spending, rollover and "ready to assign" are computed from transactions.
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    month DATE NOT NULL CHECK (EXTRACT(DAY FROM month) = 1), -- First day of the month
+    assigned BIGINT NOT NULL DEFAULT 0, -- Cents, negative once money was moved out
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_budget_month UNIQUE (user_id, category_id, month)
+);
+
+CREATE INDEX idx_budgets_user_month ON budgets(user_id, month);
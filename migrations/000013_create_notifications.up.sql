@@ -0,0 +1,24 @@
+-- In-app inbox. Every notification lands here, whatever else it is sent to.
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL, -- e.g. "budget_overrun", "bill_reminder", "anomaly"
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Delivery channels besides the inbox, one row per channel and user
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL, -- "email", "webhook" or "log"
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    target TEXT NOT NULL DEFAULT '', -- Email address or URL
+    kinds TEXT[] NOT NULL DEFAULT '{}', -- Empty means every kind
+    PRIMARY KEY (user_id, channel)
+);
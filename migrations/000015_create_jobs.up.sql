@@ -0,0 +1,20 @@
+-- Background jobs. Workers claim due rows with FOR UPDATE SKIP LOCKED, so any
+-- number of them (API instances or cmd/worker) can share the table. A job that
+-- uses up its attempts stays here as "dead" until it is retried.
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE, -- NULL for system jobs
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- "queued", "running", "succeeded" or "dead"
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE, -- Lease of the worker running it
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_jobs_due ON jobs(run_at) WHERE status IN ('queued', 'running');
+CREATE INDEX idx_jobs_user ON jobs(user_id, created_at DESC);
@@ -0,0 +1,17 @@
+-- Transactional outbox. Repository writes insert their domain events here in
+-- the same transaction as the change, so an event exists if and only if the
+-- change committed. The relay publishes the rows and deletes them.
+CREATE TABLE outbox_events (
+    seq BIGSERIAL PRIMARY KEY, -- Publishing order
+    event_id UUID NOT NULL DEFAULT uuid_generate_v4(), -- Stable across retries, receivers deduplicate on it
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE, -- Lease of the relay publishing it, or retry time after a failure
+    last_error TEXT
+);
+
+-- A replayed event must not queue a second delivery for the same endpoint
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(endpoint_id, event_id);
@@ -0,0 +1,20 @@
+-- Who changed what: one row per create, update and delete of transactions,
+-- categories and budgets, written in the same database transaction as the
+-- change itself. Rows are never updated.
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Owner of the data
+    actor_id UUID, -- User who made the change, NULL for background work
+    action VARCHAR(10) NOT NULL, -- "create", "update" or "delete"
+    entity_type VARCHAR(30) NOT NULL, -- "transaction", "category" or "budget"
+    entity_id UUID NOT NULL,
+    before JSONB, -- NULL on create
+    after JSONB, -- NULL on delete
+    ip VARCHAR(45),
+    user_agent TEXT,
+    request_id VARCHAR(128),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX idx_audit_log_user ON audit_log(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_log_entity ON audit_log(user_id, entity_id);
@@ -0,0 +1,13 @@
+-- Deleted transactions and categories go to the trash first: they are hidden
+-- from every query but can be restored until the retention job purges them.
+ALTER TABLE transactions ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE categories ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_transactions_deleted ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_categories_deleted ON categories(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A category in the trash does not block its name
+DROP INDEX unique_user_category_name_idx;
+CREATE UNIQUE INDEX unique_user_category_name_idx
+ON categories (user_id, LOWER(name))
+WHERE deleted_at IS NULL;
@@ -0,0 +1,16 @@
+-- Responses of POST requests sent with an Idempotency-Key header, replayed
+-- when the client retries the same request. A row without a status code is
+-- a request still being processed.
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL, -- SHA-256 of the method, path and body
+    status_code INT,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys(expires_at);
@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every write bumps the version, served as the ETag.
+-- A write sent with an outdated If-Match version is refused.
+ALTER TABLE transactions ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- A request holds its key for a short time only: when its process dies the
+-- key is taken over by a retry instead of staying "in progress" until it expires
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
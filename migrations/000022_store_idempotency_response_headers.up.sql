@@ -0,0 +1,6 @@
+-- Replayed responses carry the headers of the original one (ETag, Location...),
+-- not only its content type
+ALTER TABLE idempotency_keys ADD COLUMN response_headers JSONB;
+UPDATE idempotency_keys SET response_headers = jsonb_build_object('Content-Type', content_type)
+WHERE content_type IS NOT NULL;
+ALTER TABLE idempotency_keys DROP COLUMN content_type;
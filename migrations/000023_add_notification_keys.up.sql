@@ -0,0 +1,4 @@
+-- Alerts raised from at-least-once sources (outbox events, periodic scans)
+-- carry a key, so the same alert only lands in the inbox once
+ALTER TABLE notifications ADD COLUMN dedupe_key TEXT;
+CREATE UNIQUE INDEX idx_notifications_dedupe_key ON notifications(user_id, dedupe_key) WHERE dedupe_key IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Trashed transactions give up their external ID, so re-importing a
+-- statement brings them back as new rows instead of skipping them
+DROP INDEX unique_user_transaction_external_id_idx;
+
+CREATE UNIQUE INDEX unique_user_transaction_external_id_idx
+ON transactions (user_id, external_id)
+WHERE external_id IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,11 @@
+-- Every balance seen for a linked account, so goals can measure what was
+-- saved since their start date rather than the whole balance
+CREATE TABLE bank_account_balances (
+    account_id UUID NOT NULL REFERENCES bank_accounts(id) ON DELETE CASCADE,
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL,
+    balance BIGINT NOT NULL, -- Cents
+    PRIMARY KEY (account_id, as_of)
+);
+
+INSERT INTO bank_account_balances (account_id, as_of, balance)
+SELECT id, balance_updated_at, balance FROM bank_accounts WHERE balance_updated_at IS NOT NULL;
@@ -0,0 +1,214 @@
+package banking
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeMerchants feed the generated transaction descriptions
+var fakeMerchants = []struct {
+	name     string
+	category string
+	min, max int64 // Cents
+}{
+	{"REWE Markt", "Groceries", 1500, 9000},
+	{"Coffee Corner", "Dining out", 300, 900},
+	{"City Transit", "Transport", 250, 300},
+	{"Netflix", "Subscriptions", 1599, 1599},
+	{"Pizzeria Roma", "Dining out", 1800, 6000},
+	{"Shell Station", "Transport", 3000, 8000},
+	{"Pharmacy", "Health", 500, 4000},
+}
+
+// FakeProvider is a deterministic, offline BankProvider for development and
+// tests. Every account gets one transaction per day from Start until Now,
+// with a salary on the 1st of each month. The same token, account and clock
+// always produce the same data.
+type FakeProvider struct {
+	Start    time.Time        // First day of history
+	Now      func() time.Time // Defaults to time.Now
+	PageSize int              // Defaults to 50
+
+	mu         sync.Mutex
+	amendments map[string]int64 // Key: transaction ID
+}
+
+// NewFakeProvider returns a fake with 90 days of history before now
+func NewFakeProvider() *FakeProvider {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return &FakeProvider{Start: today.AddDate(0, 0, -90)}
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+// LinkAccount accepts any token starting with "fake-" and links a checking
+// and a savings account derived from it
+func (p *FakeProvider) LinkAccount(ctx context.Context, token string) ([]Account, error) {
+	if !strings.HasPrefix(token, "fake-") || len(token) == len("fake-") || strings.Contains(token, ":") {
+		return nil, ErrInvalidToken
+	}
+
+	accounts := []Account{
+		{ID: token + ":checking", Name: "Fake Checking", Mask: p.mask(token + "checking"), Currency: "EUR"},
+		{ID: token + ":savings", Name: "Fake Savings", Mask: p.mask(token + "savings"), Currency: "EUR"},
+	}
+	for i := range accounts {
+		accounts[i].Balance = p.balance(accounts[i].ID)
+	}
+	return accounts, nil
+}
+
+// FetchTransactions pages through the generated history. The cursor is the
+// first day not returned yet, so new days appear as the clock moves on.
+func (p *FakeProvider) FetchTransactions(ctx context.Context, accountID, cursor string) (*TransactionPage, error) {
+	if err := p.checkAccount(accountID); err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		offset = n
+	}
+
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	days := p.days()
+
+	page := &TransactionPage{}
+	day := offset
+	for ; day < days && day < offset+pageSize; day++ {
+		page.Transactions = append(page.Transactions, p.transactions(accountID, day, days)...)
+	}
+	page.HasMore = day < days
+
+	// Today is still pending: the next sync starts there again to get it booked
+	next := day
+	if !page.HasMore && days > 0 && next >= days {
+		next = days - 1
+	}
+	page.NextCursor = strconv.Itoa(next)
+	return page, nil
+}
+
+func (p *FakeProvider) RefreshBalances(ctx context.Context, accountIDs []string) ([]Balance, error) {
+	now := p.now()
+	balances := make([]Balance, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		if err := p.checkAccount(id); err != nil {
+			return nil, err
+		}
+		balances = append(balances, Balance{AccountID: id, Current: p.balance(id), AsOf: now})
+	}
+	return balances, nil
+}
+
+// Amend changes the amount of an already generated transaction, like a bank
+// correcting a booking. Later fetches return the new amount.
+func (p *FakeProvider) Amend(transactionID string, amount int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.amendments == nil {
+		p.amendments = make(map[string]int64)
+	}
+	p.amendments[transactionID] = amount
+}
+
+func (p *FakeProvider) now() time.Time {
+	if p.Now != nil {
+		return p.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// days is the number of days of history available so far (today included)
+func (p *FakeProvider) days() int {
+	start := p.Start.UTC().Truncate(24 * time.Hour)
+	n := int(p.now().Sub(start)/(24*time.Hour)) + 1
+	return max(n, 0)
+}
+
+// transactions generates the movements of one day; the newest day is still pending
+func (p *FakeProvider) transactions(accountID string, day, days int) []Transaction {
+	date := p.Start.UTC().Truncate(24*time.Hour).AddDate(0, 0, day)
+	pending := day == days-1
+
+	var result []Transaction
+	if strings.HasSuffix(accountID, ":savings") {
+		// Savings only get the monthly interest
+		if date.Day() == 1 {
+			result = append(result, Transaction{
+				ID: fmt.Sprintf("%s:%d:interest", accountID, day), Date: date, Amount: 125,
+				Description: "Interest", Category: "Interest", Pending: pending,
+			})
+		}
+	} else {
+		if date.Day() == 1 {
+			result = append(result, Transaction{
+				ID: fmt.Sprintf("%s:%d:salary", accountID, day), Date: date, Amount: 320000,
+				Description: "ACME GmbH Salary", Category: "Salary", Pending: pending,
+			})
+		}
+		h := p.hash(fmt.Sprintf("%s:%d", accountID, day))
+		m := fakeMerchants[h%uint64(len(fakeMerchants))]
+		amount := m.min
+		if m.max > m.min {
+			amount += int64(h>>8) % (m.max - m.min + 1)
+		}
+		result = append(result, Transaction{
+			ID: fmt.Sprintf("%s:%d:card", accountID, day), Date: date, Amount: -amount,
+			Description: m.name, Category: m.category, Pending: pending,
+		})
+	}
+
+	p.mu.Lock()
+	for i := range result {
+		if amount, ok := p.amendments[result[i].ID]; ok {
+			result[i].Amount = amount
+		}
+	}
+	p.mu.Unlock()
+	return result
+}
+
+// balance is an opening balance plus every booked transaction
+func (p *FakeProvider) balance(accountID string) int64 {
+	balance := int64(100000)
+	days := p.days()
+	for day := 0; day < days; day++ {
+		for _, t := range p.transactions(accountID, day, days) {
+			if !t.Pending {
+				balance += t.Amount
+			}
+		}
+	}
+	return balance
+}
+
+func (p *FakeProvider) checkAccount(accountID string) error {
+	token, kind, ok := strings.Cut(accountID, ":")
+	if !ok || !strings.HasPrefix(token, "fake-") || (kind != "checking" && kind != "savings") {
+		return ErrUnknownAccount
+	}
+	return nil
+}
+
+func (p *FakeProvider) mask(s string) string {
+	return fmt.Sprintf("%04d", p.hash(s)%10000)
+}
+
+func (p *FakeProvider) hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
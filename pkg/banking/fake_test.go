@@ -0,0 +1,96 @@
+package banking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFake(now time.Time) *FakeProvider {
+	return &FakeProvider{
+		Start:    time.Date(2026, time.February, 25, 0, 0, 0, 0, time.UTC),
+		Now:      func() time.Time { return now },
+		PageSize: 3,
+	}
+}
+
+func TestFakeProviderLinkAccount(t *testing.T) {
+	p := newTestFake(time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC))
+
+	accounts, err := p.LinkAccount(context.Background(), "fake-alice")
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "fake-alice:checking", accounts[0].ID)
+	assert.Len(t, accounts[0].Mask, 4)
+
+	again, err := p.LinkAccount(context.Background(), "fake-alice")
+	require.NoError(t, err)
+	assert.Equal(t, accounts, again)
+
+	_, err = p.LinkAccount(context.Background(), "real-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestFakeProviderFetchTransactions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC) // 9 days of history
+	p := newTestFake(now)
+
+	// Page through everything
+	var all []Transaction
+	cursor := ""
+	for {
+		page, err := p.FetchTransactions(ctx, "fake-alice:checking", cursor)
+		require.NoError(t, err)
+		all = append(all, page.Transactions...)
+		cursor = page.NextCursor
+		if !page.HasMore {
+			break
+		}
+	}
+
+	// One card payment a day plus the salary on March 1st, only today is pending
+	require.Len(t, all, 10)
+	pending := 0
+	for _, tx := range all {
+		if tx.Pending {
+			pending++
+			assert.Equal(t, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), tx.Date)
+		}
+	}
+	assert.Equal(t, 1, pending)
+
+	// The next sync starts at today again, a day later it is booked
+	assert.Equal(t, "8", cursor)
+	p.Now = func() time.Time { return now.AddDate(0, 0, 1) }
+	page, err := p.FetchTransactions(ctx, "fake-alice:checking", cursor)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	assert.Equal(t, all[len(all)-1].ID, page.Transactions[0].ID)
+	assert.False(t, page.Transactions[0].Pending)
+	assert.True(t, page.Transactions[1].Pending)
+
+	// Deterministic and amendable
+	first, err := p.FetchTransactions(ctx, "fake-alice:checking", "")
+	require.NoError(t, err)
+	assert.Equal(t, all[0], first.Transactions[0])
+	p.Amend(all[0].ID, -1)
+	first, err = p.FetchTransactions(ctx, "fake-alice:checking", "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), first.Transactions[0].Amount)
+
+	_, err = p.FetchTransactions(ctx, "other:checking", "")
+	assert.ErrorIs(t, err, ErrUnknownAccount)
+}
+
+func TestFakeProviderRefreshBalances(t *testing.T) {
+	p := newTestFake(time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC))
+
+	balances, err := p.RefreshBalances(context.Background(), []string{"fake-alice:savings"})
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, int64(100125), balances[0].Current) // Opening balance plus the March interest
+}
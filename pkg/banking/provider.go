@@ -0,0 +1,69 @@
+// Package banking connects to bank data aggregators. Each aggregator is a
+// BankProvider; the sync service only ever talks to this interface.
+package banking
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when a link token is unknown or expired
+	ErrInvalidToken = errors.New("invalid link token")
+	// ErrUnknownAccount is returned for accounts the provider does not know (any more)
+	ErrUnknownAccount = errors.New("unknown account")
+)
+
+// Account is a bank account as seen by the provider
+type Account struct {
+	ID       string // Provider account ID, stable across syncs
+	Name     string
+	Mask     string // Last digits of the account number
+	Currency string
+	Balance  int64 // Cents
+}
+
+// Transaction is a booked or pending account movement
+type Transaction struct {
+	ID          string // Provider transaction ID, stable across syncs
+	Date        time.Time
+	Amount      int64 // Signed cents: positive is money in, negative money out
+	Description string
+	Category    string // Optional category hint from the provider
+	Pending     bool
+}
+
+// TransactionPage is one batch of transactions after a cursor.
+// NextCursor must be passed to the next call, also when HasMore is false,
+// so the following sync only returns what changed since.
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   string
+	HasMore      bool
+}
+
+// Balance is the current balance of an account
+type Balance struct {
+	AccountID string
+	Current   int64 // Cents
+	AsOf      time.Time
+}
+
+// BankProvider is a source of bank accounts and their transactions
+type BankProvider interface {
+	// Name identifies the provider, it prefixes the external IDs of synced transactions
+	Name() string
+
+	// LinkAccount exchanges the token obtained by the client from the
+	// provider's link flow for the accounts the user gave access to.
+	LinkAccount(ctx context.Context, token string) ([]Account, error)
+
+	// FetchTransactions returns the transactions of an account after cursor.
+	// An empty cursor starts from the beginning of the available history.
+	// Transactions already returned may come again with updated values.
+	FetchTransactions(ctx context.Context, accountID, cursor string) (*TransactionPage, error)
+
+	// RefreshBalances returns the current balance of every given account
+	RefreshBalances(ctx context.Context, accountIDs []string) ([]Balance, error)
+}
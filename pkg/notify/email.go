@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// EmailDispatcher sends plain text emails through an SMTP server
+type EmailDispatcher struct {
+	Host     string // e.g. "smtp.example.com"
+	Port     string // Defaults to "587"
+	Username string // Optional, enables PLAIN auth
+	Password string
+	From     string // e.g. "Budget Tracker <noreply@example.com>"
+
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error // Replaced in tests
+}
+
+func (d *EmailDispatcher) Channel() string { return ChannelEmail }
+
+func (d *EmailDispatcher) Send(ctx context.Context, target string, msg Message) error {
+	to, err := mail.ParseAddress(target)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid email address %q: %w", target, err))
+	}
+	from, err := mail.ParseAddress(d.From)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid sender address %q: %w", d.From, err))
+	}
+
+	port := d.Port
+	if port == "" {
+		port = "587"
+	}
+	var auth smtp.Auth
+	if d.Username != "" {
+		auth = smtp.PlainAuth("", d.Username, d.Password, d.Host)
+	}
+	send := d.send
+	if send == nil {
+		send = smtp.SendMail
+	}
+
+	// net/smtp has no context support, at least do not start once cancelled
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err = send(net.JoinHostPort(d.Host, port), auth, from.Address, []string{to.Address}, d.compose(from, to, msg))
+
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return Permanent(err) // The server refused the message, retrying will not help
+	}
+	return err
+}
+
+func (d *EmailDispatcher) compose(from, to *mail.Address, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Title) + "\r\n")
+	b.WriteString("Date: " + msg.CreatedAt.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"os"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+)
+
+// DispatchersFromEnv sets up the available channels.
+// Webhook and log are always available, email needs SMTP_HOST and SMTP_FROM.
+func DispatchersFromEnv() map[string]Dispatcher {
+	dispatchers := []Dispatcher{
+		&WebhookDispatcher{Client: webhook.NewClient(10 * time.Second)},
+		&LogDispatcher{},
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		dispatchers = append(dispatchers, &EmailDispatcher{
+			Host:     host,
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		})
+	}
+
+	result := make(map[string]Dispatcher, len(dispatchers))
+	for _, d := range dispatchers {
+		result[d.Channel()] = d
+	}
+	return result
+}
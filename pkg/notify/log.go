@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// LogDispatcher writes notifications to a logger, handy in development
+type LogDispatcher struct {
+	Logger *log.Logger // Optional, defaults to the standard logger
+}
+
+func (d *LogDispatcher) Channel() string { return ChannelLog }
+
+func (d *LogDispatcher) Send(ctx context.Context, target string, msg Message) error {
+	logger := d.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("notification %s [%s] to %q: %s - %s", msg.ID, msg.Kind, target, msg.Title, msg.Body)
+	return nil
+}
@@ -0,0 +1,49 @@
+// Package notify delivers notifications outside the app. Each delivery
+// channel (email, webhook, log) is a Dispatcher. Dispatchers try once, the
+// caller retries the failures that are not Permanent.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+// Message is the channel independent content of a notification
+type Message struct {
+	ID        string         `json:"id"`
+	Kind      string         `json:"kind"` // e.g. "budget_overrun", "bill_reminder", "anomaly"
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Dispatcher sends a message to a target: an email address, a URL...
+type Dispatcher interface {
+	Channel() string
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// permanentError marks failures that retrying cannot fix (bad address, rejected request)
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the caller gives up at once
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	status := http.StatusInternalServerError
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	d := &WebhookDispatcher{Client: server.Client()}
+	msg := Message{ID: "n1", Kind: "anomaly", Title: "Unusual spending", Data: map[string]any{"amount": 18000.0}}
+
+	err := d.Send(context.Background(), server.URL, msg)
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+
+	status = http.StatusBadRequest
+	assert.True(t, IsPermanent(d.Send(context.Background(), server.URL, msg)))
+
+	status = http.StatusNoContent
+	require.NoError(t, d.Send(context.Background(), server.URL, msg))
+	assert.Equal(t, msg.Title, received.Title)
+	assert.Equal(t, msg.Data, received.Data)
+
+	assert.True(t, IsPermanent(d.Send(context.Background(), "ftp://example.com", msg)))
+
+	// Without a test client, the server on loopback is off limits
+	err = (&WebhookDispatcher{}).Send(context.Background(), server.URL, msg)
+	assert.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestEmailDispatcher(t *testing.T) {
+	var sentTo []string
+	var sent string
+	var reply error
+	d := &EmailDispatcher{
+		Host: "smtp.example.com",
+		From: "Budget Tracker <noreply@example.com>",
+		send: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			assert.Equal(t, "smtp.example.com:587", addr)
+			assert.Equal(t, "noreply@example.com", from)
+			sentTo, sent = to, string(msg)
+			return reply
+		},
+	}
+	msg := Message{Title: "Groceries over budget", Body: "You spent 120%\nof the envelope.", CreatedAt: time.Now()}
+
+	require.NoError(t, d.Send(context.Background(), "alice@example.com", msg))
+	assert.Equal(t, []string{"alice@example.com"}, sentTo)
+	assert.Contains(t, sent, "Subject: Groceries over budget\r\n")
+	assert.True(t, strings.HasSuffix(sent, "\r\n\r\nYou spent 120%\r\nof the envelope.\r\n"))
+
+	reply = &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	assert.True(t, IsPermanent(d.Send(context.Background(), "alice@example.com", msg)))
+
+	reply = &textproto.Error{Code: 421, Msg: "try again later"}
+	assert.False(t, IsPermanent(d.Send(context.Background(), "alice@example.com", msg)))
+
+	assert.True(t, IsPermanent(d.Send(context.Background(), "not an address", msg)))
+}
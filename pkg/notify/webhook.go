@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/olmits/budget-tracker-backend/pkg/webhook"
+)
+
+// defaultWebhookClient refuses internal addresses and redirects, see webhook.NewClient
+var defaultWebhookClient = webhook.NewClient(10 * time.Second)
+
+// WebhookDispatcher posts the message as JSON to the target URL. Targets are
+// picked by users, so they must not reach internal addresses.
+type WebhookDispatcher struct {
+	Client *http.Client // Optional, defaults to webhook.NewClient. Tests inject their own.
+}
+
+func (d *WebhookDispatcher) Channel() string { return ChannelWebhook }
+
+func (d *WebhookDispatcher) Send(ctx context.Context, target string, msg Message) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Permanent(fmt.Errorf("invalid webhook URL %q", target))
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := d.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	resp, err := client.Do(req)
+	if errors.Is(err, webhook.ErrForbiddenAddress) {
+		return Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return Permanent(fmt.Errorf("webhook returned %s", resp.Status))
+	}
+}
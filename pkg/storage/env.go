@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// FromEnv sets up the driver chosen by STORAGE_DRIVER, "local" (default) or "s3"
+func FromEnv() (BlobStorage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./data/attachments"
+		}
+		return NewLocalStorage(dir)
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &S3Storage{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    region,
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when the requested object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// BlobStorage stores opaque binary objects (e.g. receipt scans) under a key.
+// Keys use "/" as a separator regardless of the backend.
+type BlobStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a tiny MinIO-style stand-in: it keeps objects in memory
+// and only checks that requests carry a SigV4 Authorization header.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func exerciseStorage(t *testing.T, s BlobStorage) {
+	ctx := context.Background()
+	key := "user/tx/receipt"
+
+	require.NoError(t, s.Put(ctx, key, strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+
+	r, err := s.Get(ctx, key)
+	require.NoError(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "%PDF-1.4", string(content))
+
+	require.NoError(t, s.Delete(ctx, key))
+
+	_, err = s.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	t.Run("Round Trip", func(t *testing.T) {
+		exerciseStorage(t, s)
+	})
+
+	t.Run("Rejects Path Traversal", func(t *testing.T) {
+		err := s.Put(context.Background(), "../escape", strings.NewReader("x"), 1, "text/plain")
+		assert.Error(t, err)
+	})
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := &S3Storage{
+		Endpoint:  server.URL,
+		Bucket:    "receipts",
+		Region:    "us-east-1",
+		AccessKey: "test-key",
+		SecretKey: "test-secret",
+	}
+
+	t.Run("Round Trip", func(t *testing.T) {
+		exerciseStorage(t, s)
+	})
+
+	t.Run("Uses Path-Style URLs", func(t *testing.T) {
+		require.NoError(t, s.Put(context.Background(), "a/b c", strings.NewReader("x"), 1, "image/png"))
+		_, ok := fake.objects["/receipts/a/b c"]
+		assert.True(t, ok)
+		assert.Equal(t, "image/png", fake.types["/receipts/a/b c"])
+	})
+
+	t.Run("Surfaces Auth Errors", func(t *testing.T) {
+		bad := *s
+		bad.AccessKey = "wrong"
+		err := bad.Put(context.Background(), "k", strings.NewReader("x"), 1, "")
+		assert.ErrorContains(t, err, "status 403")
+	})
+}
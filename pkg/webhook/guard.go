@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for targets on loopback, private,
+// link-local (cloud metadata) and other non-public addresses. Users pick
+// webhook URLs, they must not reach services inside our network.
+var ErrForbiddenAddress = errors.New("webhook target address is not allowed")
+
+// Ranges that IsPrivate, IsLoopback and friends do not cover
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT, some cloud metadata services
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved, includes broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, embeds any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+}
+
+// AllowedAddr reports whether webhooks may connect to ip
+func AllowedAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range forbiddenPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolver looks up the addresses of a host, *net.Resolver implements it
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// CheckURL validates a webhook target: an http or https URL whose host only
+// resolves to allowed addresses. A nil resolver uses net.DefaultResolver.
+// The check at registration is for early feedback, NewClient enforces it
+// on every connection since DNS answers can change.
+func CheckURL(ctx context.Context, resolver Resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook URL %q", rawURL)
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("cannot resolve webhook host %q", u.Hostname())
+	}
+	for _, ip := range addrs {
+		if !AllowedAddr(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, u.Hostname())
+		}
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for user supplied webhook URLs. It refuses
+// to connect to addresses AllowedAddr rejects, checked after DNS resolution,
+// ignores proxy settings and does not follow redirects: a 3xx is a failed delivery.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !AllowedAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
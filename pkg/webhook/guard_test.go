@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.215.14":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false, // Cloud metadata
+		"100.100.100.200":  false, // Alibaba Cloud metadata
+		"0.0.0.0":          false,
+		"fd00:ec2::254":    false, // AWS IPv6 metadata
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+		"64:ff9b::a00:1":   false, // NAT64 of 10.0.0.1
+		"255.255.255.255":  false,
+	} {
+		assert.Equal(t, want, AllowedAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := NewClient(5 * time.Second)
+	_, err := client.Get(srv.URL)
+	assert.ErrorIs(t, err, ErrForbiddenAddress, "the test server listens on loopback")
+	assert.ErrorIs(t, client.CheckRedirect(nil, nil), http.ErrUseLastResponse, "redirects are not followed")
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	sentAt := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"transaction.created"}`)
+	header := Sign(secret, sentAt, body)
+	assert.True(t, strings.HasPrefix(header, "t=1772712000,v1="))
+
+	assert.NoError(t, Verify(secret, header, body, sentAt.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, Verify(secret, header, []byte(`{"type":"tampered"}`), sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_other", header, body, sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, header, body, sentAt.Add(time.Hour), 5*time.Minute), ErrExpiredSignature)
+	assert.ErrorIs(t, Verify(secret, "v1=abc", body, sentAt, 5*time.Minute), ErrInvalidSignature)
+}